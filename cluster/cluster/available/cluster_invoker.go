@@ -44,7 +44,7 @@ func NewClusterInvoker(directory directory.Directory) protocol.Invoker {
 }
 
 func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 	err := invoker.CheckInvokers(invokers, invocation)
 	if err != nil {
 		return &protocol.RPCResult{Err: err}
@@ -55,6 +55,10 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 		return &protocol.RPCResult{Err: err}
 	}
 
+	if target, pinned, _ := invoker.TargetInvoker(invocation, invokers); pinned {
+		return target.Invoke(ctx, invocation)
+	}
+
 	for _, ivk := range invokers {
 		if ivk.IsAvailable() {
 			return ivk.Invoke(ctx, invocation)
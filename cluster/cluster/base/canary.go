@@ -0,0 +1,71 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package base
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/logger"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+const (
+	defaultCanaryTag = "canary"
+	// canarySampledKey is the attribute of the call sampled already, which the nested cluster invokers of the
+	// multiple registries or groups don't sample again
+	canarySampledKey = "canary.sampled"
+)
+
+// List samples @invocation for the canary providers, and then lists the invokers the directory routes it to
+func (invoker *ClusterInvoker) List(invocation protocol.Invocation) []protocol.Invoker {
+	url := invoker.GetURL()
+	// the url of the registry directory carries the consumer url
+	if url != nil && url.SubURL != nil {
+		url = url.SubURL
+	}
+	if inv, ok := invocation.(*invocation_impl.RPCInvocation); ok && url != nil {
+		appendCanaryTag(url, inv)
+	}
+	return invoker.Directory.List(invocation)
+}
+
+// appendCanaryTag tags the percentage of the calls given by the url param canary.percentage with the tag canary.tag,
+// which is carried in the attachment dubbo.tag for the tag router to route them to the canary providers.
+// The calls tagged already are left untouched.
+func appendCanaryTag(url *common.URL, inv *invocation_impl.RPCInvocation) {
+	percentage := url.GetParam(constant.CANARY_PERCENTAGE_KEY, "")
+	if len(percentage) == 0 || len(inv.AttachmentsByKey(constant.Tagkey, "")) > 0 ||
+		inv.AttributeByKey(canarySampledKey, nil) != nil {
+		return
+	}
+	percent, err := strconv.ParseFloat(percentage, 64)
+	if err != nil || percent < 0 || percent > 100 {
+		logger.Warnf("Invalid canary percentage %q, which must be in [0, 100]", percentage)
+		return
+	}
+	inv.SetAttribute(canarySampledKey, true)
+	if rand.Float64()*100 < percent {
+		inv.SetAttachments(constant.Tagkey, url.GetParam(constant.CANARY_TAG_KEY, defaultCanaryTag))
+	}
+}
@@ -33,6 +33,10 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/protocol"
 )
 
+// ErrNoTargetInvoker is the cause of the error returned for a call pinned by the target.address attachment
+// to an address no available provider matches.
+var ErrNoTargetInvoker = perrors.New("no available provider of the target address")
+
 type ClusterInvoker struct {
 	Directory      directory.Directory
 	AvailableCheck bool
@@ -66,7 +70,8 @@ func (invoker *ClusterInvoker) IsAvailable() bool {
 	return invoker.Directory.IsAvailable()
 }
 
-// CheckInvokers checks invokers' status if is available or not
+// CheckInvokers checks invokers' status if is available or not, and that the provider the call is pinned to
+// by the target.address attachment is among them
 func (invoker *ClusterInvoker) CheckInvokers(invokers []protocol.Invoker, invocation protocol.Invocation) error {
 	if len(invokers) == 0 {
 		ip := common.GetLocalIp()
@@ -74,7 +79,8 @@ func (invoker *ClusterInvoker) CheckInvokers(invokers []protocol.Invoker, invoca
 			"registry %v on the consumer %v using the dubbo version %v .Please check if the providers have been started and registered.",
 			invocation.MethodName(), invoker.Directory.GetURL().SubURL.Key(), invoker.Directory.GetURL().String(), ip, constant.Version)
 	}
-	return nil
+	_, _, err := invoker.TargetInvoker(invocation, invokers)
+	return err
 }
 
 // CheckWhetherDestroyed checks if cluster invoker was destroyed or not
@@ -87,12 +93,43 @@ func (invoker *ClusterInvoker) CheckWhetherDestroyed() error {
 	return nil
 }
 
+// TargetInvoker returns the invoker pinned by the target.address attachment of @invocation, pinned is false
+// if the call is not pinned. An error caused by ErrNoTargetInvoker is returned if no available invoker matches the address.
+func (invoker *ClusterInvoker) TargetInvoker(invocation protocol.Invocation, invokers []protocol.Invoker) (target protocol.Invoker, pinned bool, err error) {
+	address := invocation.AttachmentsByKey(constant.TARGET_ADDRESS_KEY, "")
+	if len(address) == 0 {
+		return nil, false, nil
+	}
+	for _, ivk := range invokers {
+		if ivk.GetURL().Location == address && (!invoker.AvailableCheck || ivk.IsAvailable()) {
+			return ivk, true, nil
+		}
+	}
+	return nil, true, perrors.WithMessagef(ErrNoTargetInvoker, "Failed to invoke the method %v, target address %s",
+		invocation.MethodName(), address)
+}
+
+// NoInvokerError returns the error of @invocation no invoker is selected for among @invokers, which is caused by
+// ErrNoTargetInvoker if the call is pinned to an address none of them matches.
+func (invoker *ClusterInvoker) NoInvokerError(invocation protocol.Invocation, invokers []protocol.Invoker) error {
+	if _, _, err := invoker.TargetInvoker(invocation, invokers); err != nil {
+		return err
+	}
+	return perrors.Errorf("Failed to invoke the method %v of the service %v. No provider is selected.",
+		invocation.MethodName(), invoker.GetURL().Service())
+}
+
 func (invoker *ClusterInvoker) DoSelect(lb loadbalance.LoadBalance, invocation protocol.Invocation, invokers []protocol.Invoker, invoked []protocol.Invoker) protocol.Invoker {
 	var selectedInvoker protocol.Invoker
 	if len(invokers) <= 0 {
 		return selectedInvoker
 	}
 
+	// the call pinned to a target gone since CheckInvokers is never sent to another provider
+	if target, pinned, _ := invoker.TargetInvoker(invocation, invokers); pinned {
+		return target
+	}
+
 	url := invokers[0].GetURL()
 	sticky := url.GetParamBool(constant.STICKY_KEY, false)
 	// Get the service method sticky config if have
@@ -18,6 +18,7 @@
 package base
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -30,6 +31,7 @@ import (
 	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
 	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance/random"
 	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/protocol"
 	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
 )
@@ -73,3 +75,37 @@ func TestStickyNormalWhenError(t *testing.T) {
 	result1 := base.DoSelect(random.NewLoadBalance(), invocation.NewRPCInvocation(baseClusterInvokerMethodName, nil, nil), invokers, invoked)
 	assert.NotEqual(t, result, result1)
 }
+
+func TestTargetAddress(t *testing.T) {
+	var invokers []protocol.Invoker
+	for i := 0; i < 10; i++ {
+		url, _ := common.NewURL(fmt.Sprintf(baseClusterInvokerFormat, i))
+		invokers = append(invokers, clusterpkg.NewMockInvoker(url, 1))
+	}
+	base := &ClusterInvoker{}
+	base.AvailableCheck = true
+
+	tmpInvocation := invocation.NewRPCInvocation(baseClusterInvokerMethodName, nil,
+		map[string]interface{}{constant.TARGET_ADDRESS_KEY: "192.168.1.3:20000"})
+	for i := 0; i < 5; i++ {
+		result := base.DoSelect(random.NewLoadBalance(), tmpInvocation, invokers, nil)
+		assert.Equal(t, invokers[3], result)
+	}
+	target, pinned, err := base.TargetInvoker(tmpInvocation, invokers)
+	assert.NoError(t, err)
+	assert.True(t, pinned)
+	assert.Equal(t, invokers[3], target)
+
+	// no matching provider
+	tmpInvocation = invocation.NewRPCInvocation(baseClusterInvokerMethodName, nil,
+		map[string]interface{}{constant.TARGET_ADDRESS_KEY: "192.168.2.1:20000"})
+	assert.Nil(t, base.DoSelect(random.NewLoadBalance(), tmpInvocation, invokers, nil))
+	_, pinned, err = base.TargetInvoker(tmpInvocation, invokers)
+	assert.True(t, pinned)
+	assert.True(t, errors.Is(err, ErrNoTargetInvoker))
+
+	// not pinned
+	_, pinned, err = base.TargetInvoker(invocation.NewRPCInvocation(baseClusterInvokerMethodName, nil, nil), invokers)
+	assert.False(t, pinned)
+	assert.NoError(t, err)
+}
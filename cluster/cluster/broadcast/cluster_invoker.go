@@ -40,7 +40,7 @@ func newClusterInvoker(directory directory.Directory) protocol.Invoker {
 
 // nolint
 func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 	err := invoker.CheckInvokers(invokers, invocation)
 	if err != nil {
 		return &protocol.RPCResult{Err: err}
@@ -49,6 +49,9 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	if err != nil {
 		return &protocol.RPCResult{Err: err}
 	}
+	if target, pinned, _ := invoker.TargetInvoker(invocation, invokers); pinned {
+		invokers = []protocol.Invoker{target}
+	}
 
 	var result protocol.Result
 	for _, ivk := range invokers {
@@ -19,6 +19,7 @@ package failback
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"sync"
 	"time"
@@ -79,6 +80,17 @@ func (invoker *clusterInvoker) tryTimerTaskProc(ctx context.Context, retryTask *
 	invoked = append(invoked, retryTask.lastInvoker)
 
 	retryInvoker := invoker.DoSelect(retryTask.loadbalance, retryTask.invocation, retryTask.invokers, invoked)
+	if retryInvoker == nil {
+		err := invoker.NoInvokerError(retryTask.invocation, retryTask.invokers)
+		// retrying never brings the provider the call is pinned to back
+		if errors.Is(err, base.ErrNoTargetInvoker) {
+			logger.Errorf("Failed retry to invoke the method %v in the service %v, abandon it: %v.\n",
+				retryTask.invocation.MethodName(), invoker.GetURL().Service(), err)
+			return
+		}
+		invoker.checkRetry(retryTask, err)
+		return
+	}
 	result := retryInvoker.Invoke(ctx, retryTask.invocation)
 	if result.Error() != nil {
 		retryTask.lastInvoker = retryInvoker
@@ -132,8 +144,12 @@ func (invoker *clusterInvoker) checkRetry(retryTask *retryTimerTask, err error)
 
 // nolint
 func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 	if err := invoker.CheckInvokers(invokers, invocation); err != nil {
+		// retrying never brings the provider the call is pinned to back
+		if errors.Is(err, base.ErrNoTargetInvoker) {
+			return &protocol.RPCResult{Err: err}
+		}
 		logger.Errorf("Failed to invoke the method %v in the service %v, wait for retry in background. Ignored exception: %v.\n",
 			invocation.MethodName(), invoker.GetURL().Service(), err)
 		return &protocol.RPCResult{}
@@ -151,6 +167,9 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	loadBalance := extension.GetLoadbalance(lb)
 	invoked := make([]protocol.Invoker, 0, len(invokers))
 	ivk := invoker.DoSelect(loadBalance, invocation, invokers, invoked)
+	if ivk == nil {
+		return &protocol.RPCResult{Err: invoker.NoInvokerError(invocation, invokers)}
+	}
 	// DO INVOKE
 	result := ivk.Invoke(ctx, invocation)
 	if result.Error() != nil {
@@ -39,7 +39,7 @@ func newClusterInvoker(directory directory.Directory) protocol.Invoker {
 
 // nolint
 func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 	err := invoker.CheckInvokers(invokers, invocation)
 	if err != nil {
 		return &protocol.RPCResult{Err: err}
@@ -53,5 +53,8 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	}
 
 	ivk := invoker.DoSelect(loadbalance, invocation, invokers, nil)
+	if ivk == nil {
+		return &protocol.RPCResult{Err: invoker.NoInvokerError(invocation, invokers)}
+	}
 	return ivk.Invoke(ctx, invocation)
 }
@@ -19,6 +19,7 @@ package failfast
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -33,6 +34,7 @@ import (
 
 import (
 	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/cluster/cluster/base"
 	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
 	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance/random"
 	"dubbo.apache.org/dubbo-go/v3/common"
@@ -102,3 +104,23 @@ func TestFailfastInvokeFail(t *testing.T) {
 	assert.Equal(t, "error", result.Error().Error())
 	assert.Nil(t, result.Result())
 }
+
+func TestFailfastInvokeTargetMissing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	invoker := mock.NewMockInvoker(ctrl)
+	clusterInvoker := registerFailfast(invoker)
+
+	invoker.EXPECT().IsAvailable().Return(true).AnyTimes()
+	invoker.EXPECT().GetUrl().Return(failfastUrl).AnyTimes()
+	invoker.EXPECT().Invoke(gomock.Any()).Times(0)
+
+	inv := invocation.NewRPCInvocation("GetUser", nil,
+		map[string]interface{}{constant.TARGET_ADDRESS_KEY: "192.168.2.1:20000"})
+	result := clusterInvoker.Invoke(context.Background(), inv)
+
+	assert.Error(t, result.Error())
+	assert.True(t, errors.Is(result.Error(), base.ErrNoTargetInvoker))
+	assert.Nil(t, result.Result())
+}
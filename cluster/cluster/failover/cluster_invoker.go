@@ -19,6 +19,7 @@ package failover
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 )
@@ -54,7 +55,7 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 		ivk       protocol.Invoker
 	)
 
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 	if err := invoker.CheckInvokers(invokers, invocation); err != nil {
 		return &protocol.RPCResult{Err: err}
 	}
@@ -71,7 +72,7 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 				return &protocol.RPCResult{Err: err}
 			}
 
-			invokers = invoker.Directory.List(invocation)
+			invokers = invoker.List(invocation)
 			if err := invoker.CheckInvokers(invokers, invocation); err != nil {
 				return &protocol.RPCResult{Err: err}
 			}
@@ -93,6 +94,9 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	invokerSvc := invoker.GetURL().Service()
 	invokerUrl := invoker.Directory.GetURL()
 	if ivk == nil {
+		if err := invoker.NoInvokerError(invocation, invokers); errors.Is(err, base.ErrNoTargetInvoker) {
+			return &protocol.RPCResult{Err: err}
+		}
 		logger.Errorf("Failed to invoke the method %s of the service %s .No provider is available.", methodName, invokerSvc)
 		return &protocol.RPCResult{
 			Err: perrors.Errorf("Failed to invoke the method %s of the service %s .No provider is available because can't connect server.",
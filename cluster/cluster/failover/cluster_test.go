@@ -32,6 +32,7 @@ import (
 	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
 	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
 	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance/random"
+	"dubbo.apache.org/dubbo-go/v3/cluster/router"
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/extension"
@@ -115,3 +116,84 @@ func TestFailoverDestroy(t *testing.T) {
 	clusterInvoker.Destroy()
 	assert.Equal(t, false, clusterInvoker.IsAvailable())
 }
+
+// tagInvoker replies the tag of its provider
+type tagInvoker struct {
+	*protocol.BaseInvoker
+}
+
+func (i *tagInvoker) Invoke(context.Context, protocol.Invocation) protocol.Result {
+	return &protocol.RPCResult{Rest: i.GetURL().GetParam(constant.Tagkey, "")}
+}
+
+// tagRouter routes the calls to the providers of the tag in the dubbo.tag attachment
+type tagRouter struct{}
+
+func (r *tagRouter) Route(invokers []protocol.Invoker, _ *common.URL, invocation protocol.Invocation) []protocol.Invoker {
+	tag := invocation.AttachmentsByKey(constant.Tagkey, "")
+	var routed []protocol.Invoker
+	for _, invoker := range invokers {
+		if invoker.GetURL().GetParam(constant.Tagkey, "") == tag {
+			routed = append(routed, invoker)
+		}
+	}
+	return routed
+}
+
+func (r *tagRouter) URL() *common.URL {
+	return nil
+}
+
+func (r *tagRouter) Priority() int64 {
+	return 0
+}
+
+func TestFailoverCanary(t *testing.T) {
+	extension.SetLoadbalance("random", random.NewLoadBalance)
+	join := func(percentage string) protocol.Invoker {
+		var invokers []protocol.Invoker
+		for i, tag := range []string{"", "", "canary", "stable"} {
+			u, _ := common.NewURL(fmt.Sprintf("dubbo://192.168.1.%v:20000/com.ikurento.user.UserProvider", i),
+				common.WithParamsValue(constant.CANARY_PERCENTAGE_KEY, percentage),
+				common.WithParamsValue(constant.Tagkey, tag))
+			invokers = append(invokers, &tagInvoker{BaseInvoker: protocol.NewBaseInvoker(u)})
+		}
+		staticDir := static.NewDirectory(invokers)
+		staticDir.RouterChain().AddRouters([]router.PriorityRouter{&tagRouter{}})
+		return newCluster().Join(staticDir)
+	}
+	routed := func(invoker protocol.Invoker, n int) map[string]int {
+		tags := make(map[string]int)
+		for i := 0; i < n; i++ {
+			ivc := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"))
+			result := invoker.Invoke(context.Background(), ivc)
+			assert.NoError(t, result.Error())
+			tags[result.Result().(string)]++
+		}
+		return tags
+	}
+
+	// off by default
+	assert.Equal(t, map[string]int{"": 100}, routed(join(""), 100))
+
+	// the sampled calls are routed to the canary provider
+	tags := routed(join("20"), 5000)
+	assert.Len(t, tags, 2)
+	assert.InDelta(t, 1000, tags["canary"], 200)
+	assert.Equal(t, map[string]int{"canary": 100}, routed(join("100"), 100))
+
+	// the nested cluster invoker doesn't sample the call again
+	nested := newCluster().Join(static.NewDirectory([]protocol.Invoker{join("20")}))
+	tags = routed(nested, 5000)
+	assert.InDelta(t, 1000, tags["canary"], 200)
+
+	// the tagged call is left untouched
+	ivc := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"),
+		invocation.WithAttachments(map[string]interface{}{constant.Tagkey: "stable"}))
+	result := join("100").Invoke(context.Background(), ivc)
+	assert.NoError(t, result.Error())
+	assert.Equal(t, "stable", result.Result())
+
+	// the invalid percentage is ignored
+	assert.Equal(t, map[string]int{"": 100}, routed(join("120"), 100))
+}
@@ -19,6 +19,7 @@ package failsafe
 
 import (
 	"context"
+	"errors"
 )
 
 import (
@@ -48,10 +49,14 @@ func newClusterInvoker(directory directory.Directory) protocol.Invoker {
 }
 
 func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 
 	err := invoker.CheckInvokers(invokers, invocation)
 	if err != nil {
+		// the call pinned to a missing provider is a misuse rather than a failure to ignore
+		if errors.Is(err, base.ErrNoTargetInvoker) {
+			return &protocol.RPCResult{Err: err}
+		}
 		return &protocol.RPCResult{}
 	}
 
@@ -69,6 +74,14 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	var result protocol.Result
 
 	ivk := invoker.DoSelect(loadbalance, invocation, invokers, invoked)
+	if ivk == nil {
+		err := invoker.NoInvokerError(invocation, invokers)
+		if errors.Is(err, base.ErrNoTargetInvoker) {
+			return &protocol.RPCResult{Err: err}
+		}
+		logger.Errorf("Failsafe ignore exception: %v.\n", err.Error())
+		return &protocol.RPCResult{}
+	}
 	// DO INVOKE
 	result = ivk.Invoke(ctx, invocation)
 	if result.Error() != nil {
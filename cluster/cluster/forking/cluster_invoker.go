@@ -50,7 +50,7 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 		return &protocol.RPCResult{Err: err}
 	}
 
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
 	if err := invoker.CheckInvokers(invokers, invocation); err != nil {
 		return &protocol.RPCResult{Err: err}
 	}
@@ -58,7 +58,12 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	var selected []protocol.Invoker
 	forks := invoker.GetURL().GetParamByIntValue(constant.FORKS_KEY, constant.DEFAULT_FORKS)
 	timeouts := invoker.GetURL().GetParamInt(constant.TIMEOUT_KEY, constant.DEFAULT_TIMEOUT)
-	if forks < 0 || forks > len(invokers) {
+	if target, pinned, err := invoker.TargetInvoker(invocation, invokers); pinned {
+		if err != nil {
+			return &protocol.RPCResult{Err: err}
+		}
+		selected = []protocol.Invoker{target}
+	} else if forks < 0 || forks > len(invokers) {
 		selected = invokers
 	} else {
 		loadBalance := base.GetLoadBalance(invokers[0], invocation)
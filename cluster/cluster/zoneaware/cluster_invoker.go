@@ -19,7 +19,9 @@ package zoneaware
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 )
 
 import (
@@ -29,11 +31,16 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/protocol"
 )
 
+// defaultAffinityOrder is the default order of the affinity tiers to pick the registries by
+const defaultAffinityOrder = constant.ZONE_KEY + "," + constant.REGION_KEY + "," + constant.AFFINITY_ANY
+
 // When there're more than one registry for subscription.
 //
 // This extension provides a strategy to decide how to distribute traffics among them:
 // 1. registry marked as 'preferred=true' has the highest priority.
-// 2. check the zone the current request belongs, pick the registry that has the same zone first.
+// 2. check the zone the current request belongs, pick the registry that has the same zone first,
+// then the one that has the same region. The order is configured by the url param registry.affinity.order,
+// "zone,region,any" by default, and the traffics never fall back to the others if "any" is left out of it.
 // 3. Evenly balance traffic between all registries based on each registry's weight.
 // 4. Pick anyone that's available.
 type clusterInvoker struct {
@@ -48,7 +55,12 @@ func newClusterInvoker(directory directory.Directory) protocol.Invoker {
 }
 
 func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
-	invokers := invoker.Directory.List(invocation)
+	invokers := invoker.List(invocation)
+
+	// the invokers are the registries here, the one holding the provider the call is pinned to serves it
+	if address := invocation.AttachmentsByKey(constant.TARGET_ADDRESS_KEY, ""); len(address) > 0 && len(invokers) > 0 {
+		return invokeTarget(ctx, invocation, invokers, address)
+	}
 
 	err := invoker.CheckInvokers(invokers, invocation)
 	if err != nil {
@@ -63,25 +75,40 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 		}
 	}
 
-	// providers in the registry with the same zone
-	key := constant.REGISTRY_KEY + "." + constant.ZONE_KEY
-	zone := invocation.AttachmentsByKey(key, "")
-	if "" != zone {
+	// providers in the registry with the same zone, then region, as the affinity order falls back
+	order := invoker.GetURL().GetParam(constant.REGISTRY_KEY+"."+constant.AFFINITY_ORDER_KEY, defaultAffinityOrder)
+	fallbackToAny := false
+	for _, tier := range strings.Split(order, ",") {
+		if tier = strings.TrimSpace(tier); tier == constant.AFFINITY_ANY {
+			fallbackToAny = true
+			break
+		}
+		key := constant.REGISTRY_KEY + "." + tier
+		value := invocation.AttachmentsByKey(key, "")
+		if "" == value {
+			continue
+		}
 		for _, invoker := range invokers {
-			if invoker.IsAvailable() && matchParam(zone, key, "", invoker) {
+			if invoker.IsAvailable() && matchParam(value, key, "", invoker) {
 				return invoker.Invoke(ctx, invocation)
 			}
 		}
 
 		force := invocation.AttachmentsByKey(constant.REGISTRY_KEY+"."+constant.ZONE_FORCE_KEY, "")
-		if "true" == force {
+		if tier == constant.ZONE_KEY && "true" == force {
 			return &protocol.RPCResult{
 				Err: fmt.Errorf("no registry instance in zone or "+
 					"no available providers in the registry, zone: %v, "+
-					" registries: %v", zone, invoker.GetURL()),
+					" registries: %v", value, invoker.GetURL()),
 			}
 		}
 	}
+	if !fallbackToAny {
+		return &protocol.RPCResult{
+			Err: fmt.Errorf("no available providers in the registries matching the affinity order %s, registries: %v",
+				order, invoker.GetURL()),
+		}
+	}
 
 	// load balance among all registries, with registry weight count in.
 	loadBalance := base.GetLoadBalance(invokers[0], invocation)
@@ -102,6 +129,26 @@ func (invoker *clusterInvoker) Invoke(ctx context.Context, invocation protocol.I
 	}
 }
 
+// invokeTarget invokes the registries in turn until one of them holds the provider of @address.
+func invokeTarget(ctx context.Context, invocation protocol.Invocation, invokers []protocol.Invoker, address string) protocol.Result {
+	var result protocol.Result
+	for _, invoker := range invokers {
+		if !invoker.IsAvailable() {
+			continue
+		}
+		result = invoker.Invoke(ctx, invocation)
+		if !errors.Is(result.Error(), base.ErrNoTargetInvoker) {
+			return result
+		}
+	}
+	if result == nil {
+		result = &protocol.RPCResult{
+			Err: fmt.Errorf("no available registries for the target address %s in %v", address, invokers),
+		}
+	}
+	return result
+}
+
 func matchParam(target, key, def string, invoker protocol.Invoker) bool {
 	return target == invoker.GetURL().GetParam(key, def)
 }
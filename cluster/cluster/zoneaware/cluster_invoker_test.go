@@ -31,6 +31,7 @@ import (
 
 import (
 	clusterpkg "dubbo.apache.org/dubbo-go/v3/cluster/cluster"
+	"dubbo.apache.org/dubbo-go/v3/cluster/cluster/base"
 	"dubbo.apache.org/dubbo-go/v3/cluster/directory/static"
 	"dubbo.apache.org/dubbo-go/v3/cluster/loadbalance/random"
 	"dubbo.apache.org/dubbo-go/v3/common"
@@ -214,3 +215,98 @@ func TestZoneWareInvokerWithZoneForceFail(t *testing.T) {
 
 	assert.NotNil(t, result.Error())
 }
+
+// affinityInvoker is the invoker of the registry in the zone and region, which replies its name
+type affinityInvoker struct {
+	protocol.BaseInvoker
+	name      string
+	available bool
+}
+
+func (i *affinityInvoker) IsAvailable() bool {
+	return i.available
+}
+
+func (i *affinityInvoker) Invoke(context.Context, protocol.Invocation) protocol.Result {
+	return &protocol.RPCResult{Rest: i.name}
+}
+
+func TestZoneWareInvokerAffinityOrder(t *testing.T) {
+	extension.SetLoadbalance(constant.LoadBalanceKeyRandom, random.NewLoadBalance)
+	newInvokers := func(order string) []*affinityInvoker {
+		var invokers []*affinityInvoker
+		for i, location := range [][]string{{"hz-a", "east"}, {"sh-a", "east"}, {"bj-a", "north"}} {
+			url, _ := common.NewURL(fmt.Sprintf("dubbo://192.168.1.%v:20000/com.ikurento.user.UserProvider", i))
+			url.SetParam(constant.REGISTRY_KEY+"."+constant.ZONE_KEY, location[0])
+			url.SetParam(constant.REGISTRY_KEY+"."+constant.REGION_KEY, location[1])
+			if len(order) > 0 {
+				url.SetParam(constant.REGISTRY_KEY+"."+constant.AFFINITY_ORDER_KEY, order)
+			}
+			invokers = append(invokers, &affinityInvoker{BaseInvoker: *protocol.NewBaseInvoker(url), name: location[0], available: true})
+		}
+		return invokers
+	}
+	invoke := func(invokers []*affinityInvoker, zone, region string) protocol.Result {
+		var ivks []protocol.Invoker
+		for _, invoker := range invokers {
+			ivks = append(ivks, invoker)
+		}
+		inv := &invocation.RPCInvocation{}
+		inv.SetAttachments(constant.REGISTRY_KEY+"."+constant.ZONE_KEY, zone)
+		inv.SetAttachments(constant.REGISTRY_KEY+"."+constant.REGION_KEY, region)
+		return newCluster().Join(static.NewDirectory(ivks)).Invoke(context.Background(), inv)
+	}
+
+	// the same zone first
+	invokers := newInvokers("")
+	assert.Equal(t, "sh-a", invoke(invokers, "sh-a", "east").Result())
+	// then the same region
+	invokers[1].available = false
+	assert.Equal(t, "hz-a", invoke(invokers, "sh-a", "east").Result())
+	invokers[0].available = false
+	// then anywhere
+	assert.Equal(t, "bj-a", invoke(invokers, "sh-a", "east").Result())
+
+	// the region first
+	invokers = newInvokers("region,zone,any")
+	assert.Equal(t, "hz-a", invoke(invokers, "sh-a", "east").Result())
+
+	// never anywhere
+	invokers = newInvokers("zone,region")
+	assert.Equal(t, "bj-a", invoke(invokers, "gz-a", "north").Result())
+	result := invoke(invokers, "gz-a", "south")
+	assert.Error(t, result.Error())
+	assert.Nil(t, result.Result())
+}
+
+func TestZoneWareInvokerWithTargetAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockResult := &protocol.RPCResult{Rest: clusterpkg.Rest{Tried: 0, Success: true}}
+
+	var invokers []protocol.Invoker
+	for i := 0; i < 2; i++ {
+		url, _ := common.NewURL(fmt.Sprintf("dubbo://192.168.1.%v:20000/com.ikurento.user.UserProvider", i))
+		invoker := mock.NewMockInvoker(ctrl)
+		invoker.EXPECT().IsAvailable().Return(true).AnyTimes()
+		invoker.EXPECT().GetUrl().Return(url).AnyTimes()
+		if 0 == i {
+			// the preferred registry does not hold the target
+			url.SetParam(constant.REGISTRY_KEY+"."+constant.PREFERRED_KEY, "true")
+			invoker.EXPECT().Invoke(gomock.Any()).Return(&protocol.RPCResult{Err: base.ErrNoTargetInvoker})
+		} else {
+			invoker.EXPECT().Invoke(gomock.Any()).Return(mockResult)
+		}
+		invokers = append(invokers, invoker)
+	}
+
+	zoneAwareCluster := newCluster()
+	staticDir := static.NewDirectory(invokers)
+	clusterInvoker := zoneAwareCluster.Join(staticDir)
+
+	inv := invocation.NewRPCInvocation("GetUser", nil,
+		map[string]interface{}{constant.TARGET_ADDRESS_KEY: "192.168.2.1:20000"})
+	result := clusterInvoker.Invoke(context.Background(), inv)
+
+	assert.Equal(t, mockResult, result)
+}
@@ -52,6 +52,9 @@ const (
 	COMMA_SEPARATOR          = ","
 	// DUBBO_KEY                = "dubbo"
 	SSL_ENABLED_KEY = "ssl-enabled"
+	// TLS_SERVER_NAME_KEY is the server name presented in the tls handshake (SNI) to the provider connected over ssl
+	// and verified against its certificate, the host of the address by default
+	TLS_SERVER_NAME_KEY = "tls.server.name"
 	// PARAMS_TYPE_Key key used in pass through invoker factory, to define param type
 	PARAMS_TYPE_Key  = "parameter-type-names"
 	DEFAULT_Key      = "default"
@@ -125,20 +128,30 @@ const (
 )
 
 const (
-	REGISTRY_KEY              = "registry"
-	REGISTRY_PROTOCOL         = "registry"
-	SERVICE_REGISTRY_PROTOCOL = "service-discovery-registry"
-	ROLE_KEY                  = "registry.role"
-	REGISTRY_DEFAULT_KEY      = "registry.default"
-	REGISTRY_TIMEOUT_KEY      = "registry.timeout"
-	REGISTRY_LABEL_KEY        = "label"
-	PREFERRED_KEY             = "preferred"
-	ZONE_KEY                  = "zone"
-	ZONE_FORCE_KEY            = "zone.force"
-	REGISTRY_TTL_KEY          = "registry.ttl"
-	SIMPLIFIED_KEY            = "simplified"
-	NAMESPACE_KEY             = "namespace"
-	REGISTRY_GROUP_KEY        = "registry.group"
+	REGISTRY_KEY                 = "registry"
+	REGISTRY_PROTOCOL            = "registry"
+	SERVICE_REGISTRY_PROTOCOL    = "service-discovery-registry"
+	ROLE_KEY                     = "registry.role"
+	REGISTRY_DEFAULT_KEY         = "registry.default"
+	REGISTRY_TIMEOUT_KEY         = "registry.timeout"
+	REGISTRY_CONNECT_TIMEOUT_KEY = "registry.connect.timeout"
+	REGISTRY_LABEL_KEY           = "label"
+	PREFERRED_KEY                = "preferred"
+	ZONE_KEY                     = "zone"
+	ZONE_FORCE_KEY               = "zone.force"
+	REGION_KEY                   = "region"
+	AFFINITY_ORDER_KEY           = "affinity.order"
+	AFFINITY_ANY                 = "any"
+	REGISTRY_TTL_KEY             = "registry.ttl"
+	SIMPLIFIED_KEY               = "simplified"
+	NAMESPACE_KEY                = "namespace"
+	REGISTRY_GROUP_KEY           = "registry.group"
+	// REGISTRY_ID_KEY is the id of the registry config the registry url is loaded from
+	REGISTRY_ID_KEY = "registry.id"
+	// REGISTRY_SOURCE_KEY is the registry the provider url of the invoker is discovered from
+	REGISTRY_SOURCE_KEY = "registry.source"
+	// REGISTRY_OPTIONAL_KEY tells whether the registry failing to be created or connected is skipped
+	REGISTRY_OPTIONAL_KEY = "registry.optional"
 )
 
 const (
@@ -177,6 +190,31 @@ const (
 	CONFIG_SECRET_KEY             = "secret"
 	CONFIG_BACKUP_CONFIG_KEY      = "isBackupConfig"
 	CONFIG_BACKUP_CONFIG_PATH_KEY = "backupConfigPath"
+	CONFIG_LISTENER_TTL_KEY       = "listenerTTL"
+	CONFIG_NAMESPACE_SEPARATOR    = "namespaceSeparator"
+	CONFIG_MAX_LISTENERS_KEY      = "maxListeners"
+	CONFIG_FALLBACK_NAMESPACES    = "fallbackNamespaces"
+	CONFIG_READ_REPLICA_KEY       = "readReplica"
+	CONFIG_MERGE_NAMESPACES_KEY   = "mergeNamespaces"
+	CONFIG_SKIP_IDENTICAL_KEY     = "skipIdenticalPublish"
+	CONFIG_LENIENT_STARTUP_KEY    = "lenientStartup"
+	// CONFIG_DISPATCH_CONCURRENCY_KEY is how many listeners of zookeeper config center are notified of the changes
+	// concurrently, 0 or 1 means serially, which is the default
+	CONFIG_DISPATCH_CONCURRENCY_KEY = "dispatchConcurrency"
+	// CONFIG_MAX_RECONNECT_DURATION_KEY is how long the config center keeps reconnecting to the lost backend before
+	// giving up and staying unavailable, 0 means forever
+	CONFIG_MAX_RECONNECT_DURATION_KEY = "maxReconnectDuration"
+	// CONFIG_EMPTY_RETRIES_KEY is how many times apollo rereads the namespace loaded but empty, which may be transient
+	// right after it is loaded, 0 by default
+	CONFIG_EMPTY_RETRIES_KEY = "emptyRetries"
+	// CONFIG_EMPTY_RETRY_INTERVAL_KEY is the interval between the rereads of the empty namespace, 100ms by default
+	CONFIG_EMPTY_RETRY_INTERVAL_KEY = "emptyRetryInterval"
+	// CONFIG_PORTAL_ADDRESS_KEY is the address of the apollo portal serving the open api, e.g. to read the releases
+	CONFIG_PORTAL_ADDRESS_KEY = "portalAddress"
+	// CONFIG_PORTAL_TOKEN_KEY is the token of the apollo open api, which is a sensitive param
+	CONFIG_PORTAL_TOKEN_KEY = "portalToken"
+	// CONFIG_ENV_KEY is the environment of the apollo open api, DEV by default
+	CONFIG_ENV_KEY = "env"
 )
 
 const (
@@ -331,4 +369,115 @@ const (
 	// disable "protobuf-json" temporarily
 	//GenericSerializationProtobuf = "protobuf-json"
 	GenericSerializationGson = "gson"
+	// GenericSerializationJson passes the arguments and the result as json strings of any type
+	GenericSerializationJson = "json"
+)
+
+// Dubbo invoker
+const (
+	// ATTACHMENT_SIZE_THRESHOLD_KEY is the total attachment size in bytes above which the invoker warns, 0 means off
+	ATTACHMENT_SIZE_THRESHOLD_KEY = "attachment.size.threshold"
+	// ATTACHMENT_SIZE_REJECT_KEY rejects the invocation instead of only warning when the threshold is exceeded
+	ATTACHMENT_SIZE_REJECT_KEY = "attachment.size.reject"
+	// ATTACHMENT_SIZE_TRUNCATE_KEY removes the largest attachments instead of only warning when the threshold is exceeded,
+	// the ones set by the invoker itself like the path and version are always kept
+	ATTACHMENT_SIZE_TRUNCATE_KEY = "attachment.size.truncate"
+	// ATTACHMENT_COUNT_MAX_KEY is the max number of attachments of an invocation, 0 means unlimited
+	ATTACHMENT_COUNT_MAX_KEY = "attachment.count.max"
+	// ATTACHMENT_COUNT_POLICY_KEY is what to do with the invocation exceeding the max count of attachments:
+	// "reject" by default fails the invocation, "trim" removes the attachments beyond the max count
+	ATTACHMENT_COUNT_POLICY_KEY = "attachment.count.policy"
+	// ATTACHMENT_KEY_CASING_KEY is how the attachment keys are normalized before sending: "reserved" by default
+	// canonicalizes the reserved keys like timeout and version, "lower" lowercases all keys and "none" keeps them
+	ATTACHMENT_KEY_CASING_KEY = "attachment.key.casing"
+	// ATTACHMENT_BIDIRECTIONAL_KEY lists the attachment keys which travel back with the values echoed by the provider,
+	// separated by ',', as url param or method param. The other attachments are request-only
+	ATTACHMENT_BIDIRECTIONAL_KEY = "attachment.bidirectional"
+	// TRACE_CODEC_KEY is the name of the codec to serialize the trace context into attachments
+	TRACE_CODEC_KEY = "trace.codec"
+	// TRACE_INJECT_FAILURE_KEY is what to do when the trace context fails to be injected into the attachments:
+	// "log" by default logs the error and continues without it, "fail" fails the invocation
+	TRACE_INJECT_FAILURE_KEY = "trace.inject.failure"
+	// ONEWAY_KEY sends the invocation without waiting for or expecting any reply, as attachment or url param
+	ONEWAY_KEY = "oneway"
+	// RESPONSE_MAX_KEY is the max size in bytes of the response body, the larger ones are rejected before decoding
+	RESPONSE_MAX_KEY = "response.max"
+	// SEND_RETRIES_KEY is the times to resend the request failed on the connection, 0 means no retry
+	SEND_RETRIES_KEY = "send.retries"
+	// SEND_RETRY_DELAY_KEY is the delay before the first resend, which doubles for each following one
+	SEND_RETRY_DELAY_KEY = "send.retry.delay"
+	// RETRY_EXCEPTIONS_KEY lists the java class names of the exceptions returned by the provider to resend the request on,
+	// separated by ',', as url param or method param. No exception is retried by default
+	RETRY_EXCEPTIONS_KEY = "retry.exceptions"
+	// AFFINITY_CONNECTIONS_KEY is the number of the pooled connections to the provider the affinity keys are pinned to,
+	// 0 means affinity is off. The pool is shared by the services of the provider, so it is sized by the first one dialing
+	AFFINITY_CONNECTIONS_KEY = "affinity.connections"
+	// AFFINITY_KEY is the attachment pinning the calls with the same value to the same connection
+	AFFINITY_KEY = "affinity.key"
+	// AFFINITY_CTX_KEY is the same as AFFINITY_KEY but given by the context
+	AFFINITY_CTX_KEY = DubboCtxKey(AFFINITY_KEY)
+	// LABEL_PREFIX prefixes the url params which are the custom labels of the invoker, e.g. label.experiment=b
+	LABEL_PREFIX = "label."
+	// ROUTING_TAGS_KEY lists the url params to be the routing tags of the provider, separated by ','
+	ROUTING_TAGS_KEY = "routing.tags"
+	// SERIALIZATION_FALLBACK_KEY lists the serializations to decode the response with in order
+	// once the primary one fails, separated by ','
+	SERIALIZATION_FALLBACK_KEY = "serialization.fallback"
+	// CORRELATION_ID_KEY is the reserved attachment carrying the correlation id of the call
+	CORRELATION_ID_KEY = "correlation.id"
+	// CORRELATION_ID_CTX_KEY is the context key the correlation id of the call is read from
+	CORRELATION_ID_CTX_KEY = DubboCtxKey(CORRELATION_ID_KEY)
+	// IDEMPOTENCY_ENABLED_KEY sends the idempotency key of each call for the provider to dedupe the retried requests,
+	// as url param or method param
+	IDEMPOTENCY_ENABLED_KEY = "idempotency.enabled"
+	// IDEMPOTENCY_KEY is the attachment carrying the idempotency key, which is the same across the retries of a call
+	IDEMPOTENCY_KEY = "idempotency.key"
+	// IDEMPOTENCY_CTX_KEY is the context key the idempotency key of the call is read from
+	IDEMPOTENCY_CTX_KEY = DubboCtxKey(IDEMPOTENCY_KEY)
+	// STATS_ENABLED_KEY tracks the stats of the calls of the dubbo invoker, see DubboInvoker.Stats, true by default
+	STATS_ENABLED_KEY = "stats.enabled"
+	// CANARY_PERCENTAGE_KEY is the percentage in [0, 100] of the calls tagged for the canary providers, 0 means off
+	CANARY_PERCENTAGE_KEY = "canary.percentage"
+	// CANARY_TAG_KEY is the tag of the canary providers the sampled calls are tagged with, "canary" by default
+	CANARY_TAG_KEY = "canary.tag"
+	// DEGRADATION_KEY returns the fallback value registered for the method instead of the error of a failed call,
+	// as url param or method param. It is off by default
+	DEGRADATION_KEY = "degradation"
+	// TARGET_ADDRESS_KEY is the attachment pinning the call to the provider of the address, bypassing the load balance
+	TARGET_ADDRESS_KEY = "target.address"
+	// LAZY_CONNECT_KEY defers connecting to the provider until the first call
+	LAZY_CONNECT_KEY = "lazy.connect"
+	// BLACKLIST_FAILURES_KEY is the number of the consecutive connection failures of an endpoint, counted across all
+	// the invokers of it in the process, to blacklist it from creating new invokers. 0 means off
+	BLACKLIST_FAILURES_KEY = "blacklist.failures"
+	// BLACKLIST_COOLDOWN_KEY is how long the blacklisted endpoint is skipped
+	BLACKLIST_COOLDOWN_KEY = "blacklist.cooldown"
+	// CONNECTION_COMPRESSION_KEY is the compression of the whole connection asked by the consumer, "zip",
+	// which is used if the provider advertises it in CONNECTION_COMPRESSIONS_KEY and falls back to none if it advertises none
+	CONNECTION_COMPRESSION_KEY = "connection.compression"
+	// CONNECTION_COMPRESSIONS_KEY lists the connection compressions the server of the provider reads, separated by ',',
+	// which is exported in the provider url from its server session config
+	CONNECTION_COMPRESSIONS_KEY = "connection.compressions"
+	// EXCHANGE_CLIENT_KEY is the name of the ExchangeClientFactory creating the connections to the provider
+	EXCHANGE_CLIENT_KEY = "exchange.client"
+	// VOID_KEY marks the method replying nothing, which is called without a reply rather than failing with ErrNoReply,
+	// as attachment or method param
+	VOID_KEY = "void"
+	// SEND_DEADLINE_KEY sends the absolute deadline of the call in the attachment DEADLINE_KEY besides the relative timeout
+	SEND_DEADLINE_KEY = "send.deadline"
+	// DEADLINE_KEY is the attachment carrying the absolute deadline of the call in unix milliseconds
+	DEADLINE_KEY = "deadline"
+	// CALL_PROTOCOL_CTX_KEY is the context key of the protocol bundle overriding the serialization and the compression
+	// of a single call
+	CALL_PROTOCOL_CTX_KEY = DubboCtxKey("call.protocol")
+	// TIMEOUT_JITTER_KEY randomizes the timeout of each call by up to the percentage of it, e.g. 10 gives [0.9, 1.1]
+	// of the timeout, so that the retries of the consumers with the same timeout do not synchronize. 0 means off
+	TIMEOUT_JITTER_KEY = "timeout.jitter"
+	// ASYNC_CALLBACK_INHERIT_TIMEOUT_KEY makes the context seen by the async callback done once the timeout of the call
+	// elapses, besides when the context of the call is done
+	ASYNC_CALLBACK_INHERIT_TIMEOUT_KEY = "async.callback.inherit.timeout"
+	// BYTES_INTERCEPTOR_KEY is the name of the BytesInterceptor seeing the request and response bytes of the invoker
+	BYTES_INTERCEPTOR_KEY = "bytes.interceptor"
+	// DUMP_INVOCATION_KEY logs the method, arguments and attachments of each invocation before sending it
+	DUMP_INVOCATION_KEY = "dump.invocation"
 )
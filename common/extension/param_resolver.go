@@ -0,0 +1,80 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package extension
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+// ParamResolver resolves the value of a sensitive url parameter before it is used,
+// e.g. replacing a "${vault:...}" reference with the secret fetched from a vault.
+type ParamResolver func(key, value string) (string, error)
+
+var (
+	paramResolver ParamResolver = identityParamResolver
+
+	// sensitiveParamKeys are the url params handed to the ParamResolver
+	sensitiveParamKeys = []string{
+		constant.CONFIG_USERNAME_KEY,
+		constant.CONFIG_PASSWORD_KEY,
+		constant.CONFIG_SECRET_KEY,
+		constant.CONFIG_PORTAL_TOKEN_KEY,
+	}
+)
+
+func identityParamResolver(_, value string) (string, error) {
+	return value, nil
+}
+
+// SetParamResolver sets the resolver applied to sensitive url params, nil restores the identity one
+func SetParamResolver(resolver ParamResolver) {
+	if resolver == nil {
+		resolver = identityParamResolver
+	}
+	paramResolver = resolver
+}
+
+// GetParamResolver returns the resolver applied to sensitive url params
+func GetParamResolver() ParamResolver {
+	return paramResolver
+}
+
+// ResolveSensitiveParams runs the ParamResolver over the credentials of @url and replaces them with the resolved values
+func ResolveSensitiveParams(url *common.URL) error {
+	resolve := GetParamResolver()
+	var err error
+	if url.Username, err = resolve(constant.CONFIG_USERNAME_KEY, url.Username); err != nil {
+		return err
+	}
+	if url.Password, err = resolve(constant.CONFIG_PASSWORD_KEY, url.Password); err != nil {
+		return err
+	}
+	for _, key := range sensitiveParamKeys {
+		value := url.GetParam(key, "")
+		if value == "" {
+			continue
+		}
+		resolved, err := resolve(key, value)
+		if err != nil {
+			return err
+		}
+		url.SetParam(key, resolved)
+	}
+	return nil
+}
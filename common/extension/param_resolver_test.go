@@ -0,0 +1,66 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package extension
+
+import (
+	"strings"
+	"testing"
+)
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+func TestResolveSensitiveParams(t *testing.T) {
+	defer SetParamResolver(nil)
+
+	url, err := common.NewURL("registry://127.0.0.1:2181",
+		common.WithUsername("admin"),
+		common.WithPassword("${vault:zk/password}"),
+		common.WithParamsValue(constant.CONFIG_SECRET_KEY, "${vault:apollo/secret}"))
+	assert.NoError(t, err)
+
+	// identity by default
+	assert.NoError(t, ResolveSensitiveParams(url))
+	assert.Equal(t, "${vault:zk/password}", url.Password)
+
+	secrets := map[string]string{"zk/password": "zk-pwd", "apollo/secret": "apollo-secret"}
+	SetParamResolver(func(key, value string) (string, error) {
+		if !strings.HasPrefix(value, "${vault:") || !strings.HasSuffix(value, "}") {
+			return value, nil
+		}
+		secret, ok := secrets[value[len("${vault:"):len(value)-1]]
+		if !ok {
+			return "", errors.Errorf("no secret for %s", key)
+		}
+		return secret, nil
+	})
+	assert.NoError(t, ResolveSensitiveParams(url))
+	assert.Equal(t, "admin", url.Username)
+	assert.Equal(t, "zk-pwd", url.Password)
+	assert.Equal(t, "apollo-secret", url.GetParam(constant.CONFIG_SECRET_KEY, ""))
+
+	url.Password = "${vault:missing}"
+	assert.Error(t, ResolveSensitiveParams(url))
+}
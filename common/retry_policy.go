@@ -0,0 +1,121 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy decides whether a failed operation is retried and how long to wait before each retry.
+// The zero value never retries.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts including the first one, 0 and 1 mean no retry
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, which doubles for each following retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay, 0 means no cap
+	MaxDelay time.Duration
+	// Jitter randomizes each delay by up to the fraction of it, e.g. 0.2 waits [0.8, 1.2] of the delay
+	Jitter float64
+	// Retryable reports whether the error is retried, all errors are retried if it is nil
+	Retryable func(err error) bool
+	// Budget must allow each retry, which is skipped once it is exhausted, nil means unlimited
+	Budget RetryBudget
+}
+
+// RetryBudget caps the retries shared by many policies, so that the retries are throttled under sustained failures
+// rather than multiplying the load
+type RetryBudget interface {
+	// Acquire takes the budget of a retry, false if it is exhausted
+	Acquire() bool
+}
+
+// TokenBucketRetryBudget is a RetryBudget refilled by rate tokens per second up to burst tokens, each retry takes one
+type TokenBucketRetryBudget struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketRetryBudget returns a full budget refilled by @rate retries per second up to @burst retries
+func NewTokenBucketRetryBudget(rate float64, burst int) *TokenBucketRetryBudget {
+	return &TokenBucketRetryBudget{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Acquire takes a token if there is any after refilling the bucket by the time elapsed
+func (b *TokenBucketRetryBudget) Acquire() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	if b.tokens += now.Sub(b.last).Seconds() * b.rate; b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// ShouldRetry reports whether to retry after @attempts attempts failed with @err
+func (p *RetryPolicy) ShouldRetry(attempts int, err error) bool {
+	if p == nil || err == nil || attempts >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil && !p.Retryable(err) {
+		return false
+	}
+	return p.Budget == nil || p.Budget.Acquire()
+}
+
+// Delay returns how long to wait before the @retry-th retry, which starts from 1
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	if p == nil || p.BaseDelay <= 0 || retry < 1 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay))
+	}
+	return delay
+}
+
+// Do calls @fn, and calls it again after the delay as long as the policy retries the error it returns.
+// @onRetry, if not nil, is called with the retry number and the error before each retry.
+func (p *RetryPolicy) Do(fn func() error, onRetry func(retry int, err error)) error {
+	err := fn()
+	for attempts := 1; p.ShouldRetry(attempts, err); attempts++ {
+		if onRetry != nil {
+			onRetry(attempts, err)
+		}
+		time.Sleep(p.Delay(attempts))
+		err = fn()
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 6, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	var delays []time.Duration
+	for retry := 1; retry <= 5; retry++ {
+		delays = append(delays, policy.Delay(retry))
+	}
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second}, delays)
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.Delay(2)
+		assert.True(t, delay >= 100*time.Millisecond && delay <= 300*time.Millisecond, delay)
+	}
+
+	// no delay
+	assert.Equal(t, time.Duration(0), (&RetryPolicy{MaxAttempts: 3}).Delay(1))
+	assert.Equal(t, time.Duration(0), (*RetryPolicy)(nil).Delay(1))
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	errTemporary := errors.New("temporary")
+	errFatal := errors.New("fatal")
+
+	// no retry by default
+	calls := 0
+	err := (&RetryPolicy{}).Do(func() error {
+		calls++
+		return errTemporary
+	}, nil)
+	assert.Equal(t, errTemporary, err)
+	assert.Equal(t, 1, calls)
+
+	// capped by the attempts
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Retryable: func(err error) bool {
+		return err == errTemporary
+	}}
+	calls = 0
+	var retries []int
+	err = policy.Do(func() error {
+		calls++
+		return errTemporary
+	}, func(retry int, err error) {
+		retries = append(retries, retry)
+	})
+	assert.Equal(t, errTemporary, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []int{1, 2}, retries)
+
+	// not retryable
+	calls = 0
+	err = policy.Do(func() error {
+		calls++
+		return errFatal
+	}, nil)
+	assert.Equal(t, errFatal, err)
+	assert.Equal(t, 1, calls)
+
+	// succeeds after a retry
+	calls = 0
+	err = policy.Do(func() error {
+		if calls++; calls < 2 {
+			return errTemporary
+		}
+		return nil
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestTokenBucketRetryBudget(t *testing.T) {
+	budget := NewTokenBucketRetryBudget(100, 2)
+	assert.True(t, budget.Acquire())
+	assert.True(t, budget.Acquire())
+	assert.False(t, budget.Acquire())
+	// refilled by the time elapsed
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, budget.Acquire())
+
+	// the retries stop once the budget is exhausted
+	policy := &RetryPolicy{MaxAttempts: 5, Budget: NewTokenBucketRetryBudget(0, 2)}
+	calls := 0
+	err := policy.Do(func() error {
+		calls++
+		return errors.New("temporary")
+	}, nil)
+	assert.EqualError(t, err, "temporary")
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,41 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"crypto/tls"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+// TLSClientConfig returns the tls config of the connections to the server of @url, copied from @base if any. The server name presented in the handshake (SNI) and verified against the certificate is
+// the param tls.server.name, or the one of @base, or the host of the address, independent of the address dialed.
+func TLSClientConfig(url *URL, base *tls.Config) *tls.Config {
+	config := &tls.Config{}
+	if base != nil {
+		config = base.Clone()
+	}
+	if serverName := url.GetParam(constant.TLS_SERVER_NAME_KEY, ""); len(serverName) > 0 {
+		config.ServerName = serverName
+	} else if len(config.ServerName) == 0 {
+		config.ServerName = url.Ip
+	}
+	return config
+}
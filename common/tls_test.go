@@ -0,0 +1,47 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+func TestTLSClientConfig(t *testing.T) {
+	u, err := NewURL("registry://10.0.0.1:2379")
+	assert.NoError(t, err)
+	// derived from the host of the address by default
+	assert.Equal(t, "10.0.0.1", TLSClientConfig(u, nil).ServerName)
+	base := &tls.Config{ServerName: "base.example.com", MinVersion: tls.VersionTLS12}
+	assert.Equal(t, "base.example.com", TLSClientConfig(u, base).ServerName)
+
+	u.SetParam(constant.TLS_SERVER_NAME_KEY, "etcd.example.com")
+	config := TLSClientConfig(u, base)
+	assert.Equal(t, "etcd.example.com", config.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	// the base is not changed
+	assert.Equal(t, "base.example.com", base.ServerName)
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
 	"github.com/knadh/koanf/providers/rawbytes"
 
 	"github.com/pkg/errors"
@@ -40,6 +41,7 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/common/extension"
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
 )
 
 // CenterConfig is configuration for config center
@@ -149,6 +151,47 @@ func startConfigCenter(rc *RootConfig) error {
 	return nil
 }
 
+// LoadStartupConfig reads the startup config, e.g. dubbo.properties, of @key in @group from @cc,
+// parses it by the parser registered for @group in @cc, otherwise by the parser of its content type,
+// or by the parser of @cc if the content type is ambiguous,
+// and unmarshals it into a RootConfig.
+func LoadStartupConfig(cc config_center.DynamicConfiguration, key, group string) (*RootConfig, error) {
+	content, err := cc.GetProperties(key, config_center.WithGroup(group))
+	if err != nil {
+		return nil, errors.WithMessagef(err, "get startup config %s of group %s", key, group)
+	}
+	// the parser registered for the group is always used, whatever the content type is
+	var p parser.ConfigurationParser
+	if groupParsers, ok := cc.(config_center.GroupParsers); ok {
+		p = groupParsers.GroupParser(group)
+	}
+	if p == nil {
+		p = parser.SelectParser(key, content, config_center.ParserOf(cc, group))
+	}
+	properties, err := p.Parse(content)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "parse startup config %s of group %s", key, group)
+	}
+
+	koan := koanf.New(".")
+	if err = koan.Load(confmap.Provider(toConfMap(properties), "."), nil); err != nil {
+		return nil, err
+	}
+	rc := newEmptyRootConfig()
+	if err = koan.UnmarshalWithConf(rc.Prefix(), rc, koanf.UnmarshalConf{Tag: "yaml"}); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+func toConfMap(properties map[string]string) map[string]interface{} {
+	m := make(map[string]interface{}, len(properties))
+	for k, v := range properties {
+		m[k] = v
+	}
+	return m
+}
+
 func (c *CenterConfig) CreateDynamicConfiguration() (config_center.DynamicConfiguration, error) {
 	configCenterUrl, err := c.toURL()
 	if err != nil {
@@ -18,6 +18,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -26,7 +27,9 @@ import (
 )
 
 import (
+	"dubbo.apache.org/dubbo-go/v3/config_center"
 	_ "dubbo.apache.org/dubbo-go/v3/config_center/apollo"
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
 )
 
 func TestApolloConfigCenterConfig(t *testing.T) {
@@ -37,3 +40,44 @@ func TestApolloConfigCenterConfig(t *testing.T) {
 	registries := rootConfig.Registries
 	assert.NotNil(t, registries)
 }
+
+// startupDynamicConfiguration serves the startup config of dubbo.properties in the dubbo group
+type startupDynamicConfiguration struct {
+	config_center.MockDynamicConfiguration
+	content string
+}
+
+func (c *startupDynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if key != "dubbo.properties" || tmpOpts.Group != "dubbo" {
+		return "", errors.New("node does not exist")
+	}
+	return c.content, nil
+}
+
+func TestLoadStartupConfig(t *testing.T) {
+	cc := &startupDynamicConfiguration{content: `
+dubbo.application.name=BDTService
+dubbo.application.version=0.0.1
+dubbo.registries.hangzhouzk.protocol=zookeeper
+dubbo.registries.hangzhouzk.timeout=3s
+dubbo.registries.hangzhouzk.address=127.0.0.1:2181
+dubbo.protocols.dubbo.name=dubbo
+dubbo.protocols.dubbo.port=20000
+`}
+	cc.SetParser(&parser.DefaultConfigurationParser{})
+
+	rc, err := LoadStartupConfig(cc, "dubbo.properties", "dubbo")
+	assert.NoError(t, err)
+	assert.Equal(t, "BDTService", rc.Application.Name)
+	assert.Equal(t, "0.0.1", rc.Application.Version)
+	assert.Equal(t, "zookeeper", rc.Registries["hangzhouzk"].Protocol)
+	assert.Equal(t, "127.0.0.1:2181", rc.Registries["hangzhouzk"].Address)
+	assert.Equal(t, "20000", rc.Protocols["dubbo"].Port)
+
+	_, err = LoadStartupConfig(cc, "dubbo.properties", "other")
+	assert.Error(t, err)
+}
@@ -50,6 +50,10 @@ type ConsumerConfig struct {
 
 	FilterConf                     interface{} `yaml:"filter-conf" json:"filter-conf,omitempty" property:"filter-conf"`
 	MaxWaitTimeForServiceDiscovery string      `default:"3s" yaml:"max-wait-time-for-service-discovery" json:"max-wait-time-for-service-discovery,omitempty" property:"max-wait-time-for-service-discovery"`
+	// the budget shared by the retries of all the dubbo invokers, refilled by RetryBudgetRate retries per second
+	// up to RetryBudgetBurst retries
+	RetryBudgetRate  float64 `default:"100" yaml:"retry-budget-rate" json:"retry-budget-rate,omitempty" property:"retry-budget-rate"`
+	RetryBudgetBurst int     `default:"1000" yaml:"retry-budget-burst" json:"retry-budget-burst,omitempty" property:"retry-budget-burst"`
 
 	rootConfig *RootConfig
 }
@@ -180,6 +184,12 @@ func (ccb *ConsumerConfigBuilder) SetMaxWaitTimeForServiceDiscovery(maxWaitTimeF
 	return ccb
 }
 
+func (ccb *ConsumerConfigBuilder) SetRetryBudget(rate float64, burst int) *ConsumerConfigBuilder {
+	ccb.consumerConfig.RetryBudgetRate = rate
+	ccb.consumerConfig.RetryBudgetBurst = burst
+	return ccb
+}
+
 func (ccb *ConsumerConfigBuilder) SetProxyFactory(proxyFactory string) *ConsumerConfigBuilder {
 	ccb.consumerConfig.ProxyFactory = proxyFactory
 	return ccb
@@ -0,0 +1,93 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/yaml"
+)
+
+// maskedPassword replaces the passwords in the dumped config
+const maskedPassword = "******"
+
+// effectiveConfig is the root config dumped together with the registry urls resolved from it
+type effectiveConfig struct {
+	RootConfig `yaml:",inline"`
+	// ResolvedRegistries are the urls of the registries loaded from the root config by their ids
+	ResolvedRegistries map[string]string `yaml:"resolved-registries"`
+}
+
+// DumpEffectiveConfig serializes the root config, merged from the defaults, the config file and the config center,
+// to yaml together with the registry urls resolved from it, for debugging. The passwords are masked.
+func DumpEffectiveConfig() (string, error) {
+	rc := GetRootConfig()
+	if rc == nil {
+		return "", perrors.New("the root config is not loaded")
+	}
+	roleType := common.RoleType(common.CONSUMER)
+	if rc.Consumer == nil && rc.Provider != nil {
+		roleType = common.PROVIDER
+	}
+	registryURLs, err := loadRegistries(nil, rc.Registries, roleType)
+	if err != nil {
+		return "", err
+	}
+
+	dump := &effectiveConfig{RootConfig: *rc, ResolvedRegistries: make(map[string]string, len(registryURLs))}
+	if rc.Registries != nil {
+		dump.Registries = make(map[string]*RegistryConfig, len(rc.Registries))
+		for id, registry := range rc.Registries {
+			masked := *registry
+			masked.Password = maskPassword(masked.Password)
+			dump.Registries[id] = &masked
+		}
+	}
+	if rc.ConfigCenter != nil {
+		masked := *rc.ConfigCenter
+		masked.Password = maskPassword(masked.Password)
+		dump.ConfigCenter = &masked
+	}
+	if rc.MetadataReport != nil {
+		masked := *rc.MetadataReport
+		masked.Password = maskPassword(masked.Password)
+		dump.MetadataReport = &masked
+	}
+	for _, registryURL := range registryURLs {
+		registryURL.Password = maskPassword(registryURL.Password)
+		dump.ResolvedRegistries[registryURL.GetParam(constant.REGISTRY_ID_KEY, "")] = registryURL.String()
+	}
+
+	bytes, err := yaml.MarshalYML(dump)
+	if err != nil {
+		return "", perrors.WithMessage(err, "dump the effective config")
+	}
+	return string(bytes), nil
+}
+
+// maskPassword masks the non empty @password
+func maskPassword(password string) string {
+	if len(password) == 0 {
+		return password
+	}
+	return maskedPassword
+}
@@ -142,7 +142,10 @@ func (rc *ReferenceConfig) Refer(srv interface{}) {
 			}
 		}
 	} else { // use registry configs
-		rc.urls = loadRegistries(rc.RegistryIDs, rc.rootConfig.Registries, common.CONSUMER)
+		var err error
+		if rc.urls, err = loadRegistries(rc.RegistryIDs, rc.rootConfig.Registries, common.CONSUMER); err != nil {
+			panic(err)
+		}
 		// set url to regURLs
 		for _, regURL := range rc.urls {
 			regURL.SubURL = cfgURL
@@ -154,22 +157,26 @@ func (rc *ReferenceConfig) Refer(srv interface{}) {
 		invoker protocol.Invoker
 		regURL  *common.URL
 	)
-	invokers := make([]protocol.Invoker, len(rc.urls))
-	for i, u := range rc.urls {
+	invokers := make([]protocol.Invoker, 0, len(rc.urls))
+	for _, u := range rc.urls {
 		if u.Protocol == constant.SERVICE_REGISTRY_PROTOCOL {
 			invoker = extension.GetProtocol("registry").Refer(u)
 		} else {
 			invoker = extension.GetProtocol(u.Protocol).Refer(u)
 		}
+		if u.Protocol == constant.REGISTRY_PROTOCOL {
+			regURL = u
+		}
+		// the optional registry failing to be created or connected refers nothing
+		if invoker == nil && u.GetParamBool(constant.REGISTRY_OPTIONAL_KEY, false) {
+			continue
+		}
 
 		if rc.URL != "" {
 			invoker = protocolwrapper.BuildInvokerChain(invoker, constant.REFERENCE_FILTER_KEY)
 		}
 
-		invokers[i] = invoker
-		if u.Protocol == constant.REGISTRY_PROTOCOL {
-			regURL = u
-		}
+		invokers = append(invokers, invoker)
 	}
 
 	// TODO(hxmhlt): decouple from directory, config should not depend on directory module
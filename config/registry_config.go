@@ -21,10 +21,13 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 import (
 	"github.com/creasty/defaults"
+
+	perrors "github.com/pkg/errors"
 )
 
 import (
@@ -37,11 +40,13 @@ import (
 
 // RegistryConfig is the configuration of the registry center
 type RegistryConfig struct {
-	Protocol  string `validate:"required" yaml:"protocol"  json:"protocol,omitempty" property:"protocol"`
-	Timeout   string `default:"5s" validate:"required" yaml:"timeout" json:"timeout,omitempty" property:"timeout"` // unit: second
-	Group     string `yaml:"group" json:"group,omitempty" property:"group"`
-	Namespace string `yaml:"namespace" json:"namespace,omitempty" property:"namespace"`
-	TTL       string `default:"10s" yaml:"ttl" json:"ttl,omitempty" property:"ttl"` // unit: minute
+	Protocol string `validate:"required" yaml:"protocol"  json:"protocol,omitempty" property:"protocol"`
+	Timeout  string `default:"5s" validate:"required" yaml:"timeout" json:"timeout,omitempty" property:"timeout"` // unit: second
+	// ConnectTimeout is the timeout to establish the connection with the registry, it is Timeout if not set
+	ConnectTimeout string `yaml:"connect-timeout" json:"connect-timeout,omitempty" property:"connect-timeout"`
+	Group          string `yaml:"group" json:"group,omitempty" property:"group"`
+	Namespace      string `yaml:"namespace" json:"namespace,omitempty" property:"namespace"`
+	TTL            string `default:"10s" yaml:"ttl" json:"ttl,omitempty" property:"ttl"` // unit: minute
 	// for registry
 	Address    string `validate:"required" yaml:"address" json:"address,omitempty" property:"address"`
 	Username   string `yaml:"username" json:"username,omitempty" property:"username"`
@@ -51,11 +56,15 @@ type RegistryConfig struct {
 	Preferred bool `yaml:"preferred" json:"preferred,omitempty" property:"preferred"`
 	// The region where the registry belongs, usually used to isolate traffics
 	Zone string `yaml:"zone" json:"zone,omitempty" property:"zone"`
+	// The larger region containing the zone, the traffics fall back to when no registry is in the same zone
+	Region string `yaml:"region" json:"region,omitempty" property:"region"`
 	// Affects traffic distribution among registriesConfig,
 	// useful when subscribe to multiple registriesConfig Take effect only when no preferred registry is specified.
 	Weight       int64             `yaml:"weight" json:"weight,omitempty" property:"weight"`
 	Params       map[string]string `yaml:"params" json:"params,omitempty" property:"params"`
 	RegistryType string            `yaml:"registry-type"`
+	// Optional registry failing to load is skipped rather than aborting the startup
+	Optional bool `yaml:"optional" json:"optional,omitempty" property:"optional"`
 }
 
 // Prefix dubbo.registries
@@ -68,29 +77,46 @@ func (c *RegistryConfig) Init() error {
 		return err
 	}
 	c.translateRegistryAddress()
+	if c.ConnectTimeout == "" {
+		c.ConnectTimeout = c.Timeout
+	}
+	if _, err := time.ParseDuration(c.ConnectTimeout); err != nil {
+		return perrors.WithMessagef(err, "invalid registry connect timeout: %s", c.ConnectTimeout)
+	}
 	return verify(c)
 }
 
+func (c *RegistryConfig) getConnectTimeout() string {
+	if c.ConnectTimeout == "" {
+		return c.Timeout
+	}
+	return c.ConnectTimeout
+}
+
 func (c *RegistryConfig) getUrlMap(roleType common.RoleType) url.Values {
 	urlMap := url.Values{}
 	urlMap.Set(constant.GROUP_KEY, c.Group)
 	urlMap.Set(constant.ROLE_KEY, strconv.Itoa(int(roleType)))
 	urlMap.Set(constant.REGISTRY_KEY, c.Protocol)
 	urlMap.Set(constant.REGISTRY_TIMEOUT_KEY, c.Timeout)
+	urlMap.Set(constant.REGISTRY_CONNECT_TIMEOUT_KEY, c.getConnectTimeout())
 	// multi registry invoker weight label for load balance
 	urlMap.Set(constant.REGISTRY_KEY+"."+constant.REGISTRY_LABEL_KEY, strconv.FormatBool(true))
 	urlMap.Set(constant.REGISTRY_KEY+"."+constant.PREFERRED_KEY, strconv.FormatBool(c.Preferred))
 	urlMap.Set(constant.REGISTRY_KEY+"."+constant.ZONE_KEY, c.Zone)
+	urlMap.Set(constant.REGISTRY_KEY+"."+constant.REGION_KEY, c.Region)
 	urlMap.Set(constant.REGISTRY_KEY+"."+constant.WEIGHT_KEY, strconv.FormatInt(c.Weight, 10))
 	urlMap.Set(constant.REGISTRY_TTL_KEY, c.TTL)
+	urlMap.Set(constant.REGISTRY_OPTIONAL_KEY, strconv.FormatBool(c.Optional))
 	for k, v := range c.Params {
 		urlMap.Set(k, v)
 	}
 	return urlMap
 }
 
-//translateRegistryAddress translate registry address
-//  eg:address=nacos://127.0.0.1:8848 will return 127.0.0.1:8848 and protocol will set nacos
+// translateRegistryAddress translate registry address
+//
+//	eg:address=nacos://127.0.0.1:8848 will return 127.0.0.1:8848 and protocol will set nacos
 func (c *RegistryConfig) translateRegistryAddress() string {
 	if strings.Contains(c.Address, "://") {
 		u, err := url.Parse(c.Address)
@@ -137,7 +163,7 @@ func (c *RegistryConfig) toURL(roleType common.RoleType) (*common.URL, error) {
 	)
 }
 
-///////////////////////////////////// registry config api
+// /////////////////////////////////// registry config api
 const (
 	// defaultZKAddr is the default registry address of zookeeper
 	defaultZKAddr = "127.0.0.1:2181"
@@ -209,6 +235,14 @@ func WithRegistryTimeOut(timeout string) RegistryConfigOpt {
 	}
 }
 
+// WithRegistryConnectTimeOut returns RegistryConfigOpt with given @connectTimeout registry config
+func WithRegistryConnectTimeOut(connectTimeout string) RegistryConfigOpt {
+	return func(config *RegistryConfig) *RegistryConfig {
+		config.ConnectTimeout = connectTimeout
+		return config
+	}
+}
+
 // WithRegistryGroup returns RegistryConfigOpt with given @group registry group
 func WithRegistryGroup(group string) RegistryConfigOpt {
 	return func(config *RegistryConfig) *RegistryConfig {
@@ -293,6 +327,11 @@ func (rcb *RegistryConfigBuilder) SetTimeout(timeout string) *RegistryConfigBuil
 	return rcb
 }
 
+func (rcb *RegistryConfigBuilder) SetConnectTimeout(connectTimeout string) *RegistryConfigBuilder {
+	rcb.registryConfig.ConnectTimeout = connectTimeout
+	return rcb
+}
+
 func (rcb *RegistryConfigBuilder) SetGroup(group string) *RegistryConfigBuilder {
 	rcb.registryConfig.Group = group
 	return rcb
@@ -338,6 +377,11 @@ func (rcb *RegistryConfigBuilder) SetZone(zone string) *RegistryConfigBuilder {
 	return rcb
 }
 
+func (rcb *RegistryConfigBuilder) SetRegion(region string) *RegistryConfigBuilder {
+	rcb.registryConfig.Region = region
+	return rcb
+}
+
 func (rcb *RegistryConfigBuilder) SetWeight(weight int64) *RegistryConfigBuilder {
 	rcb.registryConfig.Weight = weight
 	return rcb
@@ -26,6 +26,7 @@ import (
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
 )
 
 func TestLoadRegistries(t *testing.T) {
@@ -41,7 +42,8 @@ func TestLoadRegistries(t *testing.T) {
 			Password: "pwd1",
 		},
 	}
-	urls := loadRegistries(target, regs, common.CONSUMER)
+	urls, err := loadRegistries(target, regs, common.CONSUMER)
+	assert.NoError(t, err)
 	t.Logf("loadRegistries() = urls:%v", urls)
 	assert.Equal(t, "127.0.0.2:2181,128.0.0.1:2181", urls[0].Location)
 }
@@ -59,11 +61,46 @@ func TestLoadRegistries1(t *testing.T) {
 			Password: "pwd1",
 		},
 	}
-	urls := loadRegistries(target, regs, common.CONSUMER)
+	urls, err := loadRegistries(target, regs, common.CONSUMER)
+	assert.NoError(t, err)
 	t.Logf("loadRegistries() = urls:%v", urls)
 	assert.Equal(t, "127.0.0.2:2181", urls[0].Location)
 }
 
+func TestLoadOptionalRegistries(t *testing.T) {
+	regs := map[string]*RegistryConfig{
+		"shanghai1": {
+			Protocol: "mock",
+			Timeout:  "2s",
+			Address:  "127.0.0.2:2181",
+		},
+		"shanghai2": {
+			Protocol: "mock",
+			Timeout:  "2s",
+			Address:  "127.0.0.3:%zz",
+			Optional: true,
+		},
+	}
+	urls, err := loadRegistries(nil, regs, common.CONSUMER)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(urls))
+	assert.Equal(t, "127.0.0.2:2181", urls[0].Location)
+	assert.False(t, urls[0].GetParamBool(constant.REGISTRY_OPTIONAL_KEY, true))
+
+	// the optional one loaded is marked so in its url
+	regs["shanghai2"].Address = "127.0.0.3:2181"
+	urls, err = loadRegistries([]string{"shanghai2"}, regs, common.CONSUMER)
+	assert.NoError(t, err)
+	assert.True(t, urls[0].GetParamBool(constant.REGISTRY_OPTIONAL_KEY, false))
+	regs["shanghai2"].Address = "127.0.0.3:%zz"
+
+	// the mandatory one fails
+	regs["shanghai2"].Optional = false
+	_, err = loadRegistries(nil, regs, common.CONSUMER)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "registry id shanghai2")
+}
+
 func TestTranslateRegistryAddress(t *testing.T) {
 	reg := new(RegistryConfig)
 	reg.Address = "nacos://127.0.0.1:8848"
@@ -73,3 +110,25 @@ func TestTranslateRegistryAddress(t *testing.T) {
 	assert.Equal(t, "nacos", reg.Protocol)
 	assert.Equal(t, "127.0.0.1:8848", reg.Address)
 }
+
+func TestRegistryConnectTimeout(t *testing.T) {
+	reg := &RegistryConfig{
+		Protocol:       "mock",
+		Timeout:        "10s",
+		ConnectTimeout: "1s",
+		Address:        "127.0.0.1:2181",
+	}
+	assert.NoError(t, reg.Init())
+	u, err := reg.toURL(common.CONSUMER)
+	assert.NoError(t, err)
+	assert.Equal(t, "10s", u.GetParam(constant.REGISTRY_TIMEOUT_KEY, ""))
+	assert.Equal(t, "1s", u.GetParam(constant.REGISTRY_CONNECT_TIMEOUT_KEY, ""))
+
+	// defaults to the timeout
+	reg = &RegistryConfig{Protocol: "mock", Timeout: "10s", Address: "127.0.0.1:2181"}
+	assert.NoError(t, reg.Init())
+	assert.Equal(t, "10s", reg.ConnectTimeout)
+
+	reg = &RegistryConfig{Protocol: "mock", ConnectTimeout: "abc", Address: "127.0.0.1:2181"}
+	assert.Error(t, reg.Init())
+}
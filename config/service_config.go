@@ -21,6 +21,7 @@ import (
 	"container/list"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -160,7 +161,10 @@ func (svc *ServiceConfig) Export() error {
 		return nil
 	}
 
-	regUrls := loadRegistries(svc.RegistryIDs, svc.RCRegistriesMap, common.PROVIDER)
+	regUrls, err := loadRegistries(svc.RegistryIDs, svc.RCRegistriesMap, common.PROVIDER)
+	if err != nil {
+		return err
+	}
 	urlMap := svc.getUrlMap()
 	protocolConfigs := loadProtocol(svc.ProtocolIDs, svc.RCProtocolsMap)
 	if len(protocolConfigs) == 0 {
@@ -261,8 +265,14 @@ func loadProtocol(protocolIds []string, protocols map[string]*ProtocolConfig) []
 	return returnProtocols
 }
 
-func loadRegistries(registryIds []string, registries map[string]*RegistryConfig, roleType common.RoleType) []*common.URL {
-	var registryURLs []*common.URL
+// loadRegistries builds the urls of the target registries. The optional registries failing are logged and skipped,
+// while the mandatory ones failing are returned together in the error. The urls of the optional registries are marked
+// so, to skip them as well if they fail to be created or connected.
+func loadRegistries(registryIds []string, registries map[string]*RegistryConfig, roleType common.RoleType) ([]*common.URL, error) {
+	var (
+		registryURLs []*common.URL
+		errs         []string
+	)
 	//trSlice := strings.Split(targetRegistries, ",")
 
 	for k, registryConf := range registries {
@@ -286,16 +296,29 @@ func loadRegistries(registryIds []string, registries map[string]*RegistryConfig,
 		}
 
 		if target {
-			if registryURL, err := registryConf.toURL(roleType); err != nil {
+			registryURL, err := registryConf.toURL(roleType)
+			if err == nil {
+				err = extension.ResolveSensitiveParams(registryURL)
+			}
+			if err != nil {
+				if registryConf.Optional {
+					logger.Warnf("The optional registry id: %s is skipped, error: %v", k, err)
+					continue
+				}
 				logger.Errorf("The registry id: %s url is invalid, error: %#v", k, err)
-				panic(err)
-			} else {
-				registryURLs = append(registryURLs, registryURL)
+				errs = append(errs, fmt.Sprintf("registry id %s: %v", k, err))
+				continue
 			}
+			registryURL.SetParam(constant.REGISTRY_ID_KEY, k)
+			registryURLs = append(registryURLs, registryURL)
 		}
 	}
 
-	return registryURLs
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return registryURLs, perrors.Errorf("load registries error: %s", strings.Join(errs, "; "))
+	}
+	return registryURLs, nil
 }
 
 // Unexport will call unexport of all exporters service config exported
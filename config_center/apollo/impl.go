@@ -22,6 +22,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 )
 
 import (
@@ -30,20 +31,43 @@ import (
 	perrors "github.com/pkg/errors"
 
 	"github.com/zouyx/agollo/v3"
+	"github.com/zouyx/agollo/v3/env"
 	"github.com/zouyx/agollo/v3/env/config"
+	"github.com/zouyx/agollo/v3/storage"
 )
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	cc "dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
 	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
 )
 
 const (
+	apolloProtocol       = "apollo"
 	apolloProtocolPrefix = "http://"
+	// the default separator replacing the path separator of keys in namespaces
+	defaultNamespaceSeparator = "."
+	// the interval to check whether the apollo servers are down
+	serverCheckInterval = time.Second
 )
 
+// listenerKey identifies the listeners, the group and key are kept apart so that they never collide
+type listenerKey struct {
+	group string
+	key   string
+}
+
+func (k listenerKey) String() string {
+	if len(k.group) == 0 {
+		return k.key
+	}
+	return k.group + ":" + k.key
+}
+
 type apolloConfiguration struct {
 	cc.BaseDynamicConfiguration
 	url *common.URL
@@ -51,11 +75,40 @@ type apolloConfiguration struct {
 	listeners sync.Map
 	appConf   *config.AppConfig
 	parser    parser.ConfigurationParser
+	// replaces the path separator '/' when a key is mapped to a namespace
+	namespaceSeparator string
+	// the key each namespace is mapped from, so that a key mapped to the namespace of another key is rejected
+	namespaceKeys sync.Map
+	// consulted in order once a lookup misses in the primary namespace appConf.NamespaceName
+	fallbackNamespaces []string
+	// enumerates the keys across the primary and fallback namespaces in GetConfigKeysByGroup
+	mergeNamespaces bool
+
+	// the namespace loaded but empty is reread up to emptyRetries times every emptyRetryInterval, as apollo may
+	// transiently return nothing right after the namespace is loaded
+	emptyRetries       int
+	emptyRetryInterval time.Duration
+
+	// the listeners not refreshed by AddListener within listenerTTL are removed, 0 means never
+	listenerTTL time.Duration
+	done        chan struct{}
+	destroyOnce sync.Once
 }
 
 func newApolloConfiguration(url *common.URL) (*apolloConfiguration, error) {
+	if err := extension.ResolveSensitiveParams(url); err != nil {
+		return nil, perrors.WithMessage(err, "resolve apollo config center params")
+	}
+	if err := cc.CheckAddress(url); err != nil {
+		return nil, err
+	}
 	c := &apolloConfiguration{
-		url: url,
+		url:                url,
+		done:               make(chan struct{}),
+		namespaceSeparator: url.GetParam(constant.CONFIG_NAMESPACE_SEPARATOR, defaultNamespaceSeparator),
+		mergeNamespaces:    url.GetParamBool(constant.CONFIG_MERGE_NAMESPACES_KEY, false),
+		emptyRetries:       int(url.GetParamInt(constant.CONFIG_EMPTY_RETRIES_KEY, 0)),
+		emptyRetryInterval: url.GetParamDuration(constant.CONFIG_EMPTY_RETRY_INTERVAL_KEY, "100ms"),
 	}
 	c.appConf = &config.AppConfig{
 		AppID:            url.GetParam(constant.CONFIG_APP_ID_KEY, ""),
@@ -66,9 +119,19 @@ func newApolloConfiguration(url *common.URL) (*apolloConfiguration, error) {
 		IsBackupConfig:   url.GetParamBool(constant.CONFIG_BACKUP_CONFIG_KEY, true),
 		BackupConfigPath: url.GetParam(constant.CONFIG_BACKUP_CONFIG_PATH_KEY, ""),
 	}
+	for _, namespace := range strings.Split(url.GetParam(constant.CONFIG_FALLBACK_NAMESPACES, ""), ",") {
+		if namespace = strings.TrimSpace(namespace); len(namespace) > 0 {
+			c.fallbackNamespaces = append(c.fallbackNamespaces, namespace)
+		}
+	}
 	agollo.InitCustomConfig(func() (*config.AppConfig, error) {
 		return c.appConf, nil
 	})
+	if ttl := url.GetParamDuration(constant.CONFIG_LISTENER_TTL_KEY, "0s"); ttl > 0 {
+		c.listenerTTL = ttl
+		go c.sweepListeners()
+	}
+	go c.watchServers()
 	return c, agollo.Start()
 }
 
@@ -78,9 +141,13 @@ func (c *apolloConfiguration) AddListener(key string, listener cc.ConfigurationL
 		opt(k)
 	}
 
-	key = k.Group + key
-	l, _ := c.listeners.LoadOrStore(key, newApolloListener())
-	l.(*apolloListener).AddListener(listener)
+	namespace, err := c.toNamespace(key)
+	if err != nil {
+		logger.Errorf("add apollo listener of %s error: %v", key, err)
+		return
+	}
+	l, _ := c.listeners.LoadOrStore(listenerKey{group: k.Group, key: namespace}, newApolloListener(namespace))
+	l.(*apolloListener).AddListener(listener, opts...)
 }
 
 func (c *apolloConfiguration) RemoveListener(key string, listener cc.ConfigurationListener, opts ...cc.Option) {
@@ -89,19 +156,144 @@ func (c *apolloConfiguration) RemoveListener(key string, listener cc.Configurati
 		opt(k)
 	}
 
-	key = k.Group + key
-	l, ok := c.listeners.Load(key)
+	namespace, err := c.toNamespace(key)
+	if err != nil {
+		return
+	}
+	l, ok := c.listeners.Load(listenerKey{group: k.Group, key: namespace})
 	if ok {
 		l.(*apolloListener).RemoveListener(listener)
 	}
 }
 
-func (c *apolloConfiguration) GetInternalProperty(key string, opts ...cc.Option) (string, error) {
-	newConfig := agollo.GetConfig(c.appConf.NamespaceName)
-	if newConfig == nil {
-		return "", perrors.New(fmt.Sprintf("nothing in namespace:%s ", key))
+// sweepListeners removes the listeners expired periodically until the configuration is destroyed
+func (c *apolloConfiguration) sweepListeners() {
+	ticker := time.NewTicker(c.listenerTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.listeners.Range(func(key, value interface{}) bool {
+				l := value.(*apolloListener)
+				if l.expired(now, c.listenerTTL) {
+					c.listeners.Delete(key)
+					// agollo.RemoveChangeListener adds the listener instead in this version of agollo
+					storage.RemoveChangeListener(l)
+					logger.Infof("Remove the apollo listeners of %s, not refreshed within %s", key, c.listenerTTL)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// watchServers notifies the availability listeners by the state of the apollo servers periodically
+// until the configuration is destroyed
+func (c *apolloConfiguration) watchServers() {
+	ticker := time.NewTicker(serverCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			c.NotifyAvailability(false)
+			return
+		case <-ticker.C:
+			c.checkServers(env.GetServers())
+		}
+	}
+}
+
+// checkServers notifies the availability by @servers kept by agollo, which are marked down once the requests fail
+// and refreshed by the server list sync. The config center is unavailable once all the known servers are down.
+func (c *apolloConfiguration) checkServers(servers *sync.Map) {
+	known, available := false, false
+	servers.Range(func(_, value interface{}) bool {
+		known = true
+		if server, ok := value.(*config.ServerInfo); ok && !server.IsDown {
+			available = true
+			return false
+		}
+		return true
+	})
+	c.NotifyAvailability(available || !known)
+}
+
+// Destroy stops the listener sweeper and the server watcher
+func (c *apolloConfiguration) Destroy() {
+	c.destroyOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *apolloConfiguration) GetInternalProperty(key string, opts ...cc.Option) (_ string, err error) {
+	defer func(start time.Time) {
+		metrics.RecordOperation(apolloProtocol, metrics.OperationRead, start, err)
+	}(time.Now())
+	// apollo has no group, the items are always looked up in the configured namespace,
+	// and then in the fallback namespaces in order
+	// the items are looked up in the local cache, any character is allowed in their keys
+	key = strings.TrimSpace(key)
+	// an item is never read from a namespace not loaded, which blocks until the namespace is synced
+	newConfig := c.getConfig(c.appConf.NamespaceName)
+	loaded := newConfig != nil && newConfig.GetIsInit()
+	if loaded {
+		// an item present but empty is found as well, rather than looked up in the fallback namespaces
+		if value, ok := getItem(newConfig, key); ok {
+			return value, nil
+		}
+	}
+	for _, namespace := range c.fallbackNamespaces {
+		if fallbackConfig := c.getConfig(namespace); fallbackConfig != nil && fallbackConfig.GetIsInit() {
+			if value, ok := getItem(fallbackConfig, key); ok {
+				return value, nil
+			}
+		}
+	}
+	if !loaded {
+		return "", perrors.Wrapf(cc.ErrNamespaceNotFound, "get key %s in namespace %s", key, c.appConf.NamespaceName)
+	}
+	return "", perrors.Wrapf(cc.ErrKeyNotFound, "get key %s in namespace %s", key, c.appConf.NamespaceName)
+}
+
+// Exists checks whether the item @key is present in the cache of the configured namespace or the fallback
+// namespaces. The item is missing without error if the configured namespace is loaded, otherwise its presence
+// is unknown and ErrNamespaceNotFound is returned.
+func (c *apolloConfiguration) Exists(key string, _ ...cc.Option) (bool, error) {
+	key = strings.TrimSpace(key)
+	loaded := false
+	for i, namespace := range append([]string{c.appConf.NamespaceName}, c.fallbackNamespaces...) {
+		config := c.getConfig(namespace)
+		if config == nil || !config.GetIsInit() || config.GetCache() == nil {
+			continue
+		}
+		if i == 0 {
+			loaded = true
+		}
+		if _, ok := getItem(config, key); ok {
+			return true, nil
+		}
+	}
+	if !loaded {
+		return false, perrors.Wrapf(cc.ErrNamespaceNotFound, "check key %s in namespace %s", key, c.appConf.NamespaceName)
+	}
+	return false, nil
+}
+
+// getItem returns the value of the item @key in the cache of @config, and whether the item is present
+func getItem(config *storage.Config, key string) (string, bool) {
+	if config.GetCache() == nil {
+		return "", false
+	}
+	value, err := config.GetCache().Get(key)
+	if err != nil {
+		return "", false
+	}
+	if s, ok := value.(string); ok {
+		return s, true
 	}
-	return newConfig.GetStringValue(key, ""), nil
+	return fmt.Sprint(value), true
 }
 
 func (c *apolloConfiguration) GetRule(key string, opts ...cc.Option) (string, error) {
@@ -109,36 +301,192 @@ func (c *apolloConfiguration) GetRule(key string, opts ...cc.Option) (string, er
 }
 
 // PublishConfig will publish the config with the (key, group, value) pair
-func (c *apolloConfiguration) PublishConfig(string, string, string) error {
+func (c *apolloConfiguration) PublishConfig(string, string, string, ...cc.Option) error {
 	return perrors.New("unsupport operation")
 }
 
-// GetConfigKeysByGroup will return all keys with the group
+// GetConfigKeysByGroup will return all keys with the group, it is only supported in the merged mode
+// which unions the keys across the primary and fallback namespaces, apollo has no group
 func (c *apolloConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
-	return nil, perrors.New("unsupport operation")
+	if !c.mergeNamespaces {
+		return nil, perrors.New("unsupport operation")
+	}
+	keys := gxset.NewSet()
+	for key := range c.GetMergedConfigs() {
+		keys.Add(key)
+	}
+	return keys, nil
+}
+
+// GetMergedConfigs unions the items across the primary namespace and the fallback namespaces. An item present
+// in several namespaces takes the value of the one with the highest priority, i.e. the first in the chain.
+func (c *apolloConfiguration) GetMergedConfigs() map[string]string {
+	merged := make(map[string]string)
+	for _, namespace := range append([]string{c.appConf.NamespaceName}, c.fallbackNamespaces...) {
+		config := c.getConfig(namespace)
+		if config == nil || !config.GetIsInit() || config.GetCache() == nil {
+			continue
+		}
+		config.GetCache().Range(func(key, value interface{}) bool {
+			if k, ok := key.(string); ok {
+				if _, exists := merged[k]; !exists {
+					merged[k] = fmt.Sprint(value)
+				}
+			}
+			return true
+		})
+	}
+	return merged
 }
 
-func (c *apolloConfiguration) GetProperties(key string, opts ...cc.Option) (string, error) {
+func (c *apolloConfiguration) GetProperties(key string, opts ...cc.Option) (_ string, err error) {
+	defer func(start time.Time) {
+		metrics.RecordOperation(apolloProtocol, metrics.OperationRead, start, err)
+	}(time.Now())
 	/**
 	 * when group is not null, we are getting startup configs(config file) from ShutdownConfig Center, for example:
 	 * key=dubbo.propertie
 	 */
+	tmpOpts := &cc.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if key, err = c.toNamespace(key); err != nil {
+		return "", err
+	}
 	if key == "" {
 		key = c.appConf.NamespaceName
 	}
-	tmpConfig := agollo.GetConfig(key)
-	if tmpConfig == nil {
+	if len(tmpOpts.Release) > 0 {
+		content, err := c.getRelease(key, tmpOpts.Release)
+		if err != nil {
+			return "", err
+		}
+		return cc.ApplyReadOptions(content, opts...)
+	}
+	tmpConfig, content := c.getContent(key)
+	if len(content) == 0 && key == c.appConf.NamespaceName {
+		for _, namespace := range c.fallbackNamespaces {
+			if fallbackConfig := c.getConfig(namespace); fallbackConfig != nil && len(fallbackConfig.GetContent()) > 0 {
+				tmpConfig, content = fallbackConfig, fallbackConfig.GetContent()
+				break
+			}
+		}
+	}
+	if len(content) == 0 {
+		if !isLoaded(key, tmpConfig) {
+			if tmpOpts.EmptyAsBlank {
+				logger.Debugf("namespace %s does not exist, read as blank", key)
+				return "", nil
+			}
+			return "", cc.NotFound(perrors.New(fmt.Sprintf("namespace %s does not exist", key)))
+		}
+		if tmpOpts.EmptyAsBlank {
+			return "", nil
+		}
 		return "", perrors.New(fmt.Sprintf("nothing in namespace:%s ", key))
 	}
 
-	content := tmpConfig.GetContent()
-	b := []byte(content)
-	if len(b) == 0 {
-		return "", perrors.New(fmt.Sprintf("nothing in namespace:%s ", key))
+	return cc.ApplyReadOptions(trimContentPrefix(content), opts...)
+}
+
+// GetPropertiesMulti reads the contents of @namespaces at once, e.g. to snapshot them, in the same way as GetProperties.
+// The namespaces empty or failed to read are omitted, and an error is only returned if none of them is read.
+func (c *apolloConfiguration) GetPropertiesMulti(namespaces []string) (map[string]string, error) {
+	contents := make(map[string]string, len(namespaces))
+	var failures []string
+	for _, namespace := range namespaces {
+		content, err := c.GetProperties(namespace, cc.WithEmptyAsBlank(true))
+		if err != nil {
+			logger.Warnf("read apollo namespace %s error %v, omitted", namespace, err)
+			failures = append(failures, fmt.Sprintf("%s: %v", namespace, err))
+			continue
+		}
+		if len(content) > 0 {
+			contents[namespace] = content
+		}
 	}
+	if len(contents) == 0 && len(namespaces) > 0 {
+		if len(failures) > 0 {
+			return nil, perrors.Errorf("none of the namespaces %v is read: %s", namespaces, strings.Join(failures, "; "))
+		}
+		return nil, perrors.Errorf("all the namespaces %v are empty", namespaces)
+	}
+	return contents, nil
+}
+
+// contentPrefix prefixes the content of the namespace in the properties format of agollo, whose only item is content
+const contentPrefix = "content="
+
+// trimContentPrefix removes the prefix "content=" of @content, which is returned as it is without the prefix
+func trimContentPrefix(content string) string {
+	return strings.TrimPrefix(content, contentPrefix)
+}
+
+// getContent reads the content of @namespace, which is reread if it is loaded but empty, until it is not empty or
+// emptyRetries rereads are made
+func (c *apolloConfiguration) getContent(namespace string) (*storage.Config, string) {
+	tmpConfig := c.getConfig(namespace)
+	var content string
+	if tmpConfig != nil {
+		content = tmpConfig.GetContent()
+	}
+	for i := 0; i < c.emptyRetries && len(content) == 0 && isLoaded(namespace, tmpConfig); i++ {
+		logger.Debugf("namespace %s is empty, reread it in %v", namespace, c.emptyRetryInterval)
+		select {
+		case <-c.done:
+			return tmpConfig, content
+		case <-time.After(c.emptyRetryInterval):
+		}
+		if tmpConfig = c.getConfig(namespace); tmpConfig != nil {
+			content = tmpConfig.GetContent()
+		}
+	}
+	return tmpConfig, content
+}
 
-	content = string(b[8:]) //remove defalut content= prefix
-	return content, nil
+// isLoaded tells whether @namespace is synced from the backend, which always has a release key, even if it is empty
+func isLoaded(namespace string, tmpConfig *storage.Config) bool {
+	return tmpConfig != nil && len(env.GetCurrentApolloConfigReleaseKey(namespace)) > 0
+}
+
+// getConfig returns the config of @namespace, which is served by the local cache of agollo
+// or synced from the backend if it is not cached yet.
+func (c *apolloConfiguration) getConfig(namespace string) *storage.Config {
+	if _, ok := storage.GetApolloConfigCache().Load(namespace); ok {
+		metrics.CacheHit(apolloProtocol, namespace)
+	} else {
+		metrics.CacheMiss(apolloProtocol, namespace)
+	}
+	return agollo.GetConfig(namespace)
+}
+
+// toNamespace maps @key to the name of a namespace, which only consists of [0-9a-zA-Z_.-] in apollo.
+// The path separator '/' is replaced by the namespace separator and any other illegal character by '_',
+// while the characters are escaped by agollo when they are put into urls. As the mapping is lossy, a key mapped to
+// the namespace of another key, e.g. "a/b" and "a.b", is rejected rather than sharing the namespace.
+func (c *apolloConfiguration) toNamespace(key string) (string, error) {
+	separator := c.namespaceSeparator
+	if len(separator) == 0 {
+		separator = defaultNamespaceSeparator
+	}
+	key = strings.Trim(strings.TrimSpace(key), "/")
+	var b strings.Builder
+	for _, r := range key {
+		switch {
+		case r == '/':
+			b.WriteString(separator)
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	namespace := b.String()
+	if mapped, loaded := c.namespaceKeys.LoadOrStore(namespace, key); loaded && mapped.(string) != key {
+		return "", perrors.Errorf("key %s is mapped to the namespace %s of key %s", key, namespace, mapped)
+	}
+	return namespace, nil
 }
 
 func (c *apolloConfiguration) getAddressWithProtocolPrefix(url *common.URL) string {
@@ -160,6 +508,17 @@ func (c *apolloConfiguration) getAddressWithProtocolPrefix(url *common.URL) stri
 	return converted
 }
 
+// BackendInfo describes apollo, which is read-only and lists the keys only in the merged mode
+func (c *apolloConfiguration) BackendInfo() cc.BackendInfo {
+	return cc.BackendInfo{
+		Protocol:            c.url.Protocol,
+		Endpoints:           strings.Split(c.url.Location, ","),
+		Namespace:           c.appConf.NamespaceName,
+		SupportsKeysByGroup: c.mergeNamespaces,
+		ReadOnly:            true,
+	}
+}
+
 func (c *apolloConfiguration) Parser() parser.ConfigurationParser {
 	return c.parser
 }
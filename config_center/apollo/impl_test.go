@@ -18,12 +18,14 @@ package apollo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 import (
@@ -31,13 +33,21 @@ import (
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/rawbytes"
 
+	perrors "github.com/pkg/errors"
+
 	"github.com/stretchr/testify/assert"
+
+	"github.com/zouyx/agollo/v3/env"
+	agolloconfig "github.com/zouyx/agollo/v3/env/config"
+	"github.com/zouyx/agollo/v3/storage"
 )
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/config"
 	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
 	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
@@ -168,7 +178,7 @@ func initMockApollo(t *testing.T) *apolloConfiguration {
 
 func TestListener(t *testing.T) {
 	listener := &apolloDataListener{}
-	listener.wg.Add(2)
+	listener.wg.Add(1)
 	apollo := initMockApollo(t)
 	mockConfigRes = `{
 	"appId": "testApplication_yang",
@@ -213,3 +223,600 @@ func (l *apolloDataListener) Process(configType *config_center.ConfigChangeEvent
 	l.count++
 	l.event = configType.Key
 }
+
+type orderedListener struct {
+	name  string
+	order *[]string
+}
+
+func (l *orderedListener) Process(*config_center.ConfigChangeEvent) {
+	*l.order = append(*l.order, l.name)
+}
+
+func TestListenerPriority(t *testing.T) {
+	order := &[]string{}
+	reinit := &orderedListener{name: "reinit", order: order}
+	invalidate := &orderedListener{name: "invalidate", order: order}
+	l := newApolloListener("dubbo")
+	l.AddListener(reinit)
+	l.AddListener(invalidate, config_center.WithPriority(10))
+
+	l.OnChange(newChangeEvent("dubbo", map[string]*storage.ConfigChange{"k": {NewValue: "v", ChangeType: storage.ADDED}}))
+	assert.Equal(t, []string{"invalidate", "reinit"}, *order)
+}
+
+type noopListener struct{}
+
+func (l *noopListener) Process(*config_center.ConfigChangeEvent) {}
+
+func TestListenerTTL(t *testing.T) {
+	configuration := &apolloConfiguration{listenerTTL: 50 * time.Millisecond, done: make(chan struct{})}
+	go configuration.sweepListeners()
+	defer configuration.Destroy()
+
+	configuration.AddListener("expired", &noopListener{})
+	configuration.AddListener("refreshed", &noopListener{})
+	for i := 0; i < 6; i++ {
+		time.Sleep(25 * time.Millisecond)
+		configuration.AddListener("refreshed", &noopListener{})
+	}
+	_, ok := configuration.listeners.Load(listenerKey{key: "expired"})
+	assert.False(t, ok)
+	_, ok = configuration.listeners.Load(listenerKey{key: "refreshed"})
+	assert.True(t, ok)
+}
+
+func TestListenerTTLUnregister(t *testing.T) {
+	registered := storage.GetChangeListeners().Len()
+	configuration := &apolloConfiguration{listenerTTL: 50 * time.Millisecond, done: make(chan struct{})}
+	defer configuration.Destroy()
+
+	// the listeners of a key share a single registration with agollo
+	configuration.AddListener("expired", &changesListener{})
+	configuration.AddListener("expired", &changesListener{})
+	assert.Equal(t, registered+1, storage.GetChangeListeners().Len())
+
+	go configuration.sweepListeners()
+	assert.Eventually(t, func() bool {
+		_, ok := configuration.listeners.Load(listenerKey{key: "expired"})
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, registered, storage.GetChangeListeners().Len())
+}
+
+type changesListener struct {
+	changes map[string]*config_center.ConfigItemChange
+}
+
+func (l *changesListener) Process(event *config_center.ConfigChangeEvent) {
+	l.changes = event.Changes
+}
+
+func newChangeEvent(namespace string, changes map[string]*storage.ConfigChange) *storage.ChangeEvent {
+	event := &storage.ChangeEvent{Changes: changes}
+	event.Namespace = namespace
+	return event
+}
+
+func TestListenerChanges(t *testing.T) {
+	listener := &changesListener{}
+	l := newApolloListener("dubbo")
+	l.AddListener(listener)
+
+	// the first change seen is not taken as adding every item
+	l.OnChange(newChangeEvent("dubbo", map[string]*storage.ConfigChange{
+		"timeout": {OldValue: "3s", NewValue: "5s", ChangeType: storage.MODIFIED},
+	}))
+	assert.Equal(t, map[string]*config_center.ConfigItemChange{
+		"timeout": {OldValue: "3s", NewValue: "5s", ChangeType: remoting.EventTypeUpdate},
+	}, listener.changes)
+
+	l.OnChange(newChangeEvent("dubbo", map[string]*storage.ConfigChange{
+		"retries":     {OldValue: "2", ChangeType: storage.DELETED},
+		"loadbalance": {NewValue: "random", ChangeType: storage.ADDED},
+	}))
+	assert.Equal(t, map[string]*config_center.ConfigItemChange{
+		"retries":     {OldValue: "2", ChangeType: remoting.EventTypeDel},
+		"loadbalance": {NewValue: "random", ChangeType: remoting.EventTypeAdd},
+	}, listener.changes)
+}
+
+func TestListenerChangesOfNamespaces(t *testing.T) {
+	dubbo, app := &changesListener{}, &changesListener{}
+	dubboListener, appListener := newApolloListener("dubbo"), newApolloListener("app")
+	dubboListener.AddListener(dubbo)
+	appListener.AddListener(app)
+
+	// agollo notifies every listener of the change of a namespace
+	event := newChangeEvent("app", map[string]*storage.ConfigChange{"timeout": {NewValue: "3s", ChangeType: storage.ADDED}})
+	dubboListener.OnChange(event)
+	appListener.OnChange(event)
+	assert.Nil(t, dubbo.changes)
+	assert.Equal(t, map[string]*config_center.ConfigItemChange{
+		"timeout": {NewValue: "3s", ChangeType: remoting.EventTypeAdd},
+	}, app.changes)
+}
+
+func TestToNamespace(t *testing.T) {
+	tests := []struct {
+		key       string
+		separator string
+		want      string
+	}{
+		{key: "mockDubbogo.yaml", want: "mockDubbogo.yaml"},
+		{key: " dubbo.properties\n", want: "dubbo.properties"},
+		{key: "dubbo/org.apache.dubbo.DemoService.configurators", want: "dubbo.org.apache.dubbo.DemoService.configurators"},
+		{key: "/dubbo/demo/", separator: "-", want: "dubbo-demo"},
+		{key: "demo server.yaml", want: "demo_server.yaml"},
+	}
+	for _, tt := range tests {
+		c := &apolloConfiguration{namespaceSeparator: tt.separator}
+		namespace, err := c.toNamespace(tt.key)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, namespace)
+	}
+}
+
+func TestToNamespaceCollision(t *testing.T) {
+	c := &apolloConfiguration{}
+	for _, keys := range [][]string{{"a/b", "a.b"}, {"a b", "a_b"}} {
+		namespace, err := c.toNamespace(keys[0])
+		assert.NoError(t, err)
+		// the same key maps to its namespace again
+		again, err := c.toNamespace(" " + keys[0])
+		assert.NoError(t, err)
+		assert.Equal(t, namespace, again)
+
+		_, err = c.toNamespace(keys[1])
+		assert.Error(t, err)
+		_, err = c.GetProperties(keys[1])
+		assert.Error(t, err)
+	}
+}
+
+func TestListenerKey(t *testing.T) {
+	configuration := &apolloConfiguration{}
+	listener := &noopListener{}
+	configuration.AddListener("boapp.configurators", listener, config_center.WithGroup("dub"))
+	configuration.AddListener("app.configurators", listener, config_center.WithGroup("dubbo"))
+	configuration.AddListener("dubbo/app configurators", listener)
+
+	count := 0
+	configuration.listeners.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 3, count)
+	_, ok := configuration.listeners.Load(listenerKey{key: "dubbo.app_configurators"})
+	assert.True(t, ok)
+
+	configuration.RemoveListener("app.configurators", listener, config_center.WithGroup("dubbo"))
+	l, _ := configuration.listeners.Load(listenerKey{group: "dubbo", key: "app.configurators"})
+	assert.Empty(t, l.(*apolloListener).listeners)
+	l, _ = configuration.listeners.Load(listenerKey{group: "dub", key: "boapp.configurators"})
+	assert.Len(t, l.(*apolloListener).listeners, 1)
+}
+
+func TestGetConfigCacheStats(t *testing.T) {
+	configuration := initMockApollo(t)
+	// the namespace is loaded on start
+	stats := metrics.GetCacheStats(apolloProtocol)
+	_, err := configuration.GetProperties(mockNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, metrics.CacheStats{Hits: stats.Hits + 1, Misses: stats.Misses}, metrics.GetCacheStats(apolloProtocol))
+
+	// the namespace is not cached and read from the backend
+	stats = metrics.GetCacheStats(apolloProtocol)
+	_, _ = configuration.GetProperties("mockUncached.yaml")
+	assert.Equal(t, metrics.CacheStats{Hits: stats.Hits, Misses: stats.Misses + 1}, metrics.GetCacheStats(apolloProtocol))
+}
+
+func TestTrimContentPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "empty", content: "", want: ""},
+		{name: "shorter than the prefix", content: "a=b", want: "a=b"},
+		{name: "the prefix only", content: "content=", want: ""},
+		{name: "as long as the prefix", content: "abc=def\n", want: "abc=def\n"},
+		{name: "prefixed", content: "content=dubbo:\n  application:\n    name: demo", want: "dubbo:\n  application:\n    name: demo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, trimContentPrefix(tt.content))
+		})
+	}
+}
+
+func TestGetPropertiesShortContent(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		mockNamespace: configResponse,
+		"mockShort": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockShort", "configurations": {"a": ""}, "releaseKey": "20191104105242-0f13805d89f834a6"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:        {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:       {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:     {mockNamespace},
+		constant.CONFIG_BACKUP_CONFIG_KEY: {"false"},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+
+	// the content "a=\n" is returned as it is rather than panicking
+	content, err := configuration.GetProperties("mockShort")
+	assert.NoError(t, err)
+	assert.Equal(t, "a=\n", content)
+}
+
+func TestGetPropertiesEmptyAsBlank(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		mockNamespace: configResponse,
+		"mockEmpty.yaml": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockEmpty.yaml", "configurations": {}, "releaseKey": "20191104105242-0f13805d89f834a5"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:    {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:   {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY: {mockNamespace},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+
+	// exists but empty
+	_, err = configuration.GetProperties("mockEmpty.yaml")
+	assert.EqualError(t, err, "nothing in namespace:mockEmpty.yaml ")
+	content, err := configuration.GetProperties("mockEmpty.yaml", config_center.WithEmptyAsBlank(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "", content)
+
+	// does not exist
+	_, err = configuration.GetProperties("mockMissing.yaml")
+	assert.EqualError(t, err, "namespace mockMissing.yaml does not exist")
+	content, err = configuration.GetProperties("mockMissing.yaml", config_center.WithEmptyAsBlank(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "", content)
+}
+
+func TestGetPropertiesRetryOnEmpty(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		"mockRetryPrimary.yaml": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockRetryPrimary.yaml", "configurations": {"content": "dubbo:\n  registries: {}\n"}, "releaseKey": "20191104105242-0f13805d89f834d0"}`, mockAppId)
+		},
+		"mockRetryLate": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockRetryLate", "configurations": {}, "releaseKey": "20191104105242-0f13805d89f834d1"}`, mockAppId)
+		},
+		"mockRetryEmpty": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockRetryEmpty", "configurations": {}, "releaseKey": "20191104105242-0f13805d89f834d2"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:               {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:              {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:            {"mockRetryPrimary.yaml"},
+		constant.CONFIG_BACKUP_CONFIG_KEY:        {"false"},
+		constant.CONFIG_EMPTY_RETRIES_KEY:        {"10"},
+		constant.CONFIG_EMPTY_RETRY_INTERVAL_KEY: {"20ms"},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+	defer configuration.Destroy()
+
+	// loaded empty at first, then the content arrives
+	configuration.getConfig("mockRetryLate")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		apolloConfig := &env.ApolloConfig{Configurations: map[string]interface{}{"timeout": "5s"}}
+		apolloConfig.Init(mockAppId, mockCluster, "mockRetryLate")
+		apolloConfig.ReleaseKey = "20191104105242-0f13805d89f834d3"
+		storage.UpdateApolloConfig(apolloConfig, false)
+	}()
+	start := time.Now()
+	content, err := configuration.GetProperties("mockRetryLate")
+	assert.NoError(t, err)
+	assert.Equal(t, "timeout=5s\n", content)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// still empty after the retries
+	start = time.Now()
+	_, err = configuration.GetProperties("mockRetryEmpty")
+	assert.EqualError(t, err, "nothing in namespace:mockRetryEmpty ")
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	// the missing namespace is not retried
+	start = time.Now()
+	_, err = configuration.GetProperties("mockRetryMissing")
+	assert.EqualError(t, err, "namespace mockRetryMissing does not exist")
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestFallbackNamespaces(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		"mockPrimary": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockPrimary", "configurations": {"weight": "100", "warmup": ""}, "releaseKey": "20191104105242-0f13805d89f834a8"}`, mockAppId)
+		},
+		"mockCommon": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockCommon", "configurations": {"timeout": "5s", "warmup": "60"}, "releaseKey": "20191104105242-0f13805d89f834a6"}`, mockAppId)
+		},
+		"mockDefaults": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockDefaults", "configurations": {"timeout": "3s", "retries": "2"}, "releaseKey": "20191104105242-0f13805d89f834a7"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:          {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:         {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:       {"mockPrimary"},
+		constant.CONFIG_FALLBACK_NAMESPACES: {"mockCommon, mockDefaults"},
+		constant.CONFIG_BACKUP_CONFIG_KEY:   {"false"},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mockCommon", "mockDefaults"}, configuration.fallbackNamespaces)
+
+	// found in the primary namespace
+	value, err := configuration.GetInternalProperty("weight")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", value)
+	// the first hit in the chain
+	value, err = configuration.GetInternalProperty("timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, "5s", value)
+	value, err = configuration.GetInternalProperty("retries")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value)
+	// present but empty in the primary namespace
+	value, err = configuration.GetInternalProperty("warmup")
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+	// missing everywhere
+	value, err = configuration.GetInternalProperty("delay")
+	assert.Equal(t, config_center.ErrKeyNotFound, perrors.Cause(err))
+	assert.Equal(t, "", value)
+}
+
+func TestMergeNamespaces(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		"mockMergePrimary": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockMergePrimary", "configurations": {"weight": "100", "timeout": "1s"}, "releaseKey": "20191104105242-0f13805d89f834b1"}`, mockAppId)
+		},
+		"mockMergeCommon": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockMergeCommon", "configurations": {"timeout": "5s", "retries": "3"}, "releaseKey": "20191104105242-0f13805d89f834b2"}`, mockAppId)
+		},
+		"mockMergeDefaults": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockMergeDefaults", "configurations": {"retries": "2", "warmup": "60"}, "releaseKey": "20191104105242-0f13805d89f834b3"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	params := map[string][]string{
+		constant.CONFIG_APP_ID_KEY:          {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:         {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:       {"mockMergePrimary"},
+		constant.CONFIG_FALLBACK_NAMESPACES: {"mockMergeCommon,mockMergeDefaults"},
+		constant.CONFIG_BACKUP_CONFIG_KEY:   {"false"},
+	}
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(params))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+	_, err = configuration.GetConfigKeysByGroup("")
+	assert.EqualError(t, err, "unsupport operation")
+
+	params[constant.CONFIG_MERGE_NAMESPACES_KEY] = []string{"true"}
+	url, err = common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(params))
+	assert.NoError(t, err)
+	configuration, err = newApolloConfiguration(url)
+	assert.NoError(t, err)
+	keys, err := configuration.GetConfigKeysByGroup("")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, keys.Size())
+	for _, key := range []string{"weight", "timeout", "retries", "warmup"} {
+		assert.True(t, keys.Contains(key), key)
+	}
+	// the namespace of higher priority wins
+	assert.Equal(t, map[string]string{"weight": "100", "timeout": "1s", "retries": "3", "warmup": "60"},
+		configuration.GetMergedConfigs())
+}
+
+func TestGetInternalPropertyNotFound(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		"mockPresent": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockPresent", "configurations": {"weight": "100"}, "releaseKey": "20191104105242-0f13805d89f834a9"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:        {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:       {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:     {"mockPresent"},
+		constant.CONFIG_BACKUP_CONFIG_KEY: {"false"},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+
+	// the namespace is present, but the key is not
+	_, err = configuration.GetInternalProperty("timeout")
+	assert.Equal(t, config_center.ErrKeyNotFound, perrors.Cause(err))
+	assert.EqualError(t, err, "get key timeout in namespace mockPresent: key not found")
+
+	// the namespace is not loaded
+	configuration.appConf.NamespaceName = "mockAbsent"
+	_, err = configuration.GetInternalProperty("timeout")
+	assert.Equal(t, config_center.ErrNamespaceNotFound, perrors.Cause(err))
+	assert.EqualError(t, err, "get key timeout in namespace mockAbsent: namespace not found")
+}
+
+func TestExists(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		"mockPresent": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockPresent", "configurations": {"feature.enabled": "true"}, "releaseKey": "20191104105242-0f13805d89f834a9"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:        {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:       {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:     {"mockPresent"},
+		constant.CONFIG_BACKUP_CONFIG_KEY: {"false"},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+
+	exists, err := config_center.Exists(configuration, "feature.enabled")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = configuration.Exists("feature.disabled")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	// the namespace is not loaded
+	configuration.appConf.NamespaceName = "mockAbsent"
+	_, err = configuration.Exists("feature.enabled")
+	assert.Equal(t, config_center.ErrNamespaceNotFound, perrors.Cause(err))
+}
+
+func TestBackendInfo(t *testing.T) {
+	configuration := initMockApollo(t)
+	info := configuration.BackendInfo()
+	assert.Equal(t, "apollo", info.Protocol)
+	assert.Equal(t, "mockDubbogo.yaml", info.Namespace)
+	assert.Len(t, info.Endpoints, 1)
+	assert.False(t, info.SupportsPublish)
+	assert.False(t, info.SupportsKeysByGroup)
+	assert.True(t, info.ReadOnly)
+
+	// the keys are listed in the merged mode
+	configuration.mergeNamespaces = true
+	assert.True(t, configuration.BackendInfo().SupportsKeysByGroup)
+}
+
+func TestOnAvailabilityChange(t *testing.T) {
+	c := &apolloConfiguration{}
+	var changes []bool
+	c.OnAvailabilityChange(func(available bool) {
+		changes = append(changes, available)
+	})
+
+	var servers sync.Map
+	server := &agolloconfig.ServerInfo{HomepageURL: "http://localhost:8080/"}
+	servers.Store(server.HomepageURL, server)
+	c.checkServers(&servers)
+	assert.Empty(t, changes)
+
+	// all the servers are down
+	server.IsDown = true
+	c.checkServers(&servers)
+	c.checkServers(&servers)
+	assert.Equal(t, []bool{false}, changes)
+
+	// the servers are back
+	servers.Store(server.HomepageURL, &agolloconfig.ServerInfo{HomepageURL: server.HomepageURL})
+	c.checkServers(&servers)
+	assert.Equal(t, []bool{false, true}, changes)
+}
+
+func TestGetPropertiesRelease(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "mockToken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/openapi/v1/envs/PRO/apps/testApplication_yang/clusters/dev/namespaces/mockDubbogo.yaml/releases/20":
+			fmt.Fprint(w, `{"name": "rollback", "configurations": {"content": "dubbo:\n  application:\n    name: old"}}`)
+		case "/openapi/v1/envs/PRO/apps/testApplication_yang/clusters/dev/namespaces/application/releases/21":
+			fmt.Fprint(w, `{"name": "rollback", "configurations": {"timeout": "3s", "retries": "2"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	url, err := common.NewURL("apollo://127.0.0.1:8080?" + constant.CONFIG_PORTAL_ADDRESS_KEY + "=" + ts.URL + "&" +
+		constant.CONFIG_PORTAL_TOKEN_KEY + "=mockToken&" + constant.CONFIG_ENV_KEY + "=PRO")
+	assert.NoError(t, err)
+	configuration := &apolloConfiguration{url: url, appConf: &agolloconfig.AppConfig{
+		AppID: mockAppId, Cluster: mockCluster, NamespaceName: mockNamespace}}
+
+	content, err := configuration.GetProperties(mockNamespace, config_center.WithRelease("20"))
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo:\n  application:\n    name: old", content)
+	content, err = configuration.GetProperties("application", config_center.WithRelease("21"))
+	assert.NoError(t, err)
+	assert.Equal(t, "retries=2\ntimeout=3s\n", content)
+	_, err = configuration.GetProperties(mockNamespace, config_center.WithRelease("22"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "404 Not Found")
+
+	// the portal credentials are required
+	url.SetParam(constant.CONFIG_PORTAL_TOKEN_KEY, "")
+	_, err = configuration.GetProperties(mockNamespace, config_center.WithRelease("20"))
+	assert.EqualError(t, err, "reading the release 20 of namespace mockDubbogo.yaml requires the apollo portal address "+
+		"and token, set by portalAddress and portalToken")
+}
+
+func TestGetPropertiesMulti(t *testing.T) {
+	handlerMap := map[string]func(http.ResponseWriter, *http.Request){
+		"mockMultiPrimary.yaml": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockMultiPrimary.yaml", "configurations": {"content": "dubbo:\n  registries: {}\n"}, "releaseKey": "20191104105242-0f13805d89f834c0"}`, mockAppId)
+		},
+		"mockMultiCommon": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockMultiCommon", "configurations": {"timeout": "5s"}, "releaseKey": "20191104105242-0f13805d89f834c1"}`, mockAppId)
+		},
+		"mockMultiEmpty": func(rw http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(rw, `{"appId": "%s", "cluster": "default", "namespaceName": "mockMultiEmpty", "configurations": {}, "releaseKey": "20191104105242-0f13805d89f834c2"}`, mockAppId)
+		},
+	}
+	server := runMockConfigServer(handlerMap, notifyResponse)
+	defer server.Close()
+	url, err := common.NewURL(strings.ReplaceAll(server.URL, "http", "apollo"), common.WithParams(map[string][]string{
+		constant.CONFIG_APP_ID_KEY:        {mockAppId},
+		constant.CONFIG_CLUSTER_KEY:       {mockCluster},
+		constant.CONFIG_NAMESPACE_KEY:     {"mockMultiPrimary.yaml"},
+		constant.CONFIG_BACKUP_CONFIG_KEY: {"false"},
+	}))
+	assert.NoError(t, err)
+	configuration, err := newApolloConfiguration(url)
+	assert.NoError(t, err)
+
+	// the empty namespace is omitted
+	contents, err := configuration.GetPropertiesMulti([]string{"mockMultiPrimary.yaml", "mockMultiCommon", "mockMultiEmpty"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"mockMultiPrimary.yaml": "dubbo:\n  registries: {}\n\n",
+		"mockMultiCommon":       "timeout=5s\n",
+	}, contents)
+
+	// none of them is read
+	_, err = configuration.GetPropertiesMulti([]string{"mockMultiEmpty"})
+	assert.EqualError(t, err, "all the namespaces [mockMultiEmpty] are empty")
+	_, err = configuration.GetPropertiesMulti([]string{"mockMultiEmpty", "mockMultiMissing"})
+	assert.EqualError(t, err, "all the namespaces [mockMultiEmpty mockMultiMissing] are empty")
+}
+
+func TestEmptyAddress(t *testing.T) {
+	url, err := common.NewURL("apollo://?" + constant.CONFIG_APP_ID_KEY + "=" + mockAppId)
+	assert.NoError(t, err)
+	_, err = newApolloConfiguration(url)
+	assert.True(t, errors.Is(perrors.Cause(err), config_center.ErrNoAddress))
+	assert.EqualError(t, err, "create apollo config center: no address of the config center")
+}
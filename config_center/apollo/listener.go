@@ -17,6 +17,11 @@
 
 package apollo
 
+import (
+	"sync"
+	"time"
+)
+
 import (
 	"github.com/zouyx/agollo/v3"
 	"github.com/zouyx/agollo/v3/storage"
@@ -27,51 +32,114 @@ import (
 import (
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
 
 type apolloListener struct {
-	listeners map[config_center.ConfigurationListener]struct{}
+	lock      sync.RWMutex
+	listeners config_center.ListenerEntries
+	// agollo notifies each listener of the changes of all the namespaces, only the ones of namespace are processed
+	namespace string
+	// registered with agollo once, as agollo removes a single registration of a listener at a time
+	registered bool
+	// the last time AddListener is called
+	refreshed time.Time
 }
 
 // nolint
-func newApolloListener() *apolloListener {
-	return &apolloListener{
-		listeners: make(map[config_center.ConfigurationListener]struct{}),
-	}
+func newApolloListener(namespace string) *apolloListener {
+	return &apolloListener{namespace: namespace}
 }
 
-// OnChange process each listener
+// OnChange process each listener with the change of each item of the namespace
 func (a *apolloListener) OnChange(changeEvent *storage.ChangeEvent) {
-}
-
-// OnNewestChange process each listener by all changes
-func (a *apolloListener) OnNewestChange(changeEvent *storage.FullChangeEvent) {
-	b, err := yaml.Marshal(changeEvent.Changes)
+	if changeEvent.Namespace != a.namespace {
+		return
+	}
+	b, err := yaml.Marshal(getConfigurations(changeEvent.Namespace))
+	defer metrics.RecordOperation(apolloProtocol, metrics.OperationEvent, time.Now(), err)
 	if err != nil {
-		logger.Errorf("apollo onNewestChange err %+v",
+		logger.Errorf("apollo onChange err %+v",
 			err)
 		return
 	}
 	content := string(b)
-	for listener := range a.listeners {
-		listener.Process(&config_center.ConfigChangeEvent{
+	changes := make(map[string]*config_center.ConfigItemChange, len(changeEvent.Changes))
+	for k, change := range changeEvent.Changes {
+		changes[k] = &config_center.ConfigItemChange{
+			OldValue:   change.OldValue,
+			NewValue:   change.NewValue,
+			ChangeType: toEventType(change.ChangeType),
+		}
+	}
+	a.lock.RLock()
+	listeners := a.listeners
+	a.lock.RUnlock()
+	for _, entry := range listeners {
+		entry.Listener.Process(&config_center.ConfigChangeEvent{
 			ConfigType: remoting.EventTypeUpdate,
 			Key:        changeEvent.Namespace,
 			Value:      content,
+			Changes:    changes,
 		})
 	}
 }
 
-// AddListener adds a listener for apollo
-func (a *apolloListener) AddListener(l config_center.ConfigurationListener) {
-	if _, ok := a.listeners[l]; !ok {
-		a.listeners[l] = struct{}{}
+// OnNewestChange does nothing, the listeners are processed by OnChange
+func (a *apolloListener) OnNewestChange(*storage.FullChangeEvent) {
+}
+
+// getConfigurations returns the configurations of @namespace cached by agollo, which are updated before the change
+// is notified
+func getConfigurations(namespace string) map[string]interface{} {
+	configurations := make(map[string]interface{})
+	if config := storage.GetConfig(namespace); config != nil {
+		config.GetCache().Range(func(key, value interface{}) bool {
+			configurations[key.(string)] = value
+			return true
+		})
+	}
+	return configurations
+}
+
+func toEventType(changeType storage.ConfigChangeType) remoting.EventType {
+	switch changeType {
+	case storage.ADDED:
+		return remoting.EventTypeAdd
+	case storage.DELETED:
+		return remoting.EventTypeDel
+	default:
+		return remoting.EventTypeUpdate
+	}
+}
+
+// AddListener adds a listener for apollo, listeners are notified in the order of their priority
+func (a *apolloListener) AddListener(l config_center.ConfigurationListener, opts ...config_center.Option) {
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if !a.registered {
 		agollo.AddChangeListener(a)
+		a.registered = true
 	}
+	a.listeners = a.listeners.Add(config_center.ListenerEntry{Listener: l, Priority: tmpOpts.Priority})
+	a.refreshed = time.Now()
 }
 
 // RemoveListener removes listeners of apollo
 func (a *apolloListener) RemoveListener(l config_center.ConfigurationListener) {
-	delete(a.listeners, l)
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.listeners = a.listeners.Remove(l)
+}
+
+// expired checks whether the listeners are not refreshed within @ttl until @now
+func (a *apolloListener) expired(now time.Time, ttl time.Duration) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return now.Sub(a.refreshed) > ttl
 }
@@ -0,0 +1,107 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apollo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	cc "dubbo.apache.org/dubbo-go/v3/config_center"
+)
+
+const (
+	defaultEnv     = "DEV"
+	defaultCluster = "default"
+	// the item keeping the whole content of the namespaces not in the properties format, e.g. yaml
+	contentItem = "content"
+)
+
+// apolloRelease is the release of a namespace returned by the apollo open api
+type apolloRelease struct {
+	Name           string            `json:"name"`
+	Configurations map[string]string `json:"configurations"`
+}
+
+// getRelease reads the historical release @id of @namespace through the open api of the apollo portal,
+// which requires the portal address and token. The content is in the same format as the current one read by
+// GetProperties, i.e. the whole content of the namespaces not in the properties format, or the items as properties.
+func (c *apolloConfiguration) getRelease(namespace string, id string) (string, error) {
+	portal := c.url.GetParam(constant.CONFIG_PORTAL_ADDRESS_KEY, "")
+	token := c.url.GetParam(constant.CONFIG_PORTAL_TOKEN_KEY, "")
+	if len(portal) == 0 || len(token) == 0 {
+		return "", perrors.Errorf("reading the release %s of namespace %s requires the apollo portal address and token, "+
+			"set by %s and %s", id, namespace, constant.CONFIG_PORTAL_ADDRESS_KEY, constant.CONFIG_PORTAL_TOKEN_KEY)
+	}
+	if !strings.HasPrefix(portal, "http://") && !strings.HasPrefix(portal, "https://") {
+		portal = apolloProtocolPrefix + portal
+	}
+	cluster := c.appConf.Cluster
+	if len(cluster) == 0 {
+		cluster = defaultCluster
+	}
+	api := fmt.Sprintf("%s/openapi/v1/envs/%s/apps/%s/clusters/%s/namespaces/%s/releases/%s", strings.TrimSuffix(portal, "/"),
+		url.PathEscape(c.url.GetParam(constant.CONFIG_ENV_KEY, defaultEnv)), url.PathEscape(c.appConf.AppID),
+		url.PathEscape(cluster), url.PathEscape(namespace), url.PathEscape(id))
+	request, err := http.NewRequest(http.MethodGet, api, nil)
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	request.Header.Set("Authorization", token)
+	client := &http.Client{Timeout: c.url.GetParamDuration(constant.CONFIG_TIMEOUT_KEY, cc.DEFAULT_CONFIG_TIMEOUT)}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", perrors.Wrapf(err, "read the release %s of namespace %s", id, namespace)
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", perrors.Wrapf(err, "read the release %s of namespace %s", id, namespace)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", perrors.Errorf("read the release %s of namespace %s: %s %s", id, namespace, response.Status, body)
+	}
+	release := &apolloRelease{}
+	if err = json.Unmarshal(body, release); err != nil {
+		return "", perrors.Wrapf(err, "decode the release %s of namespace %s", id, namespace)
+	}
+	if content, ok := release.Configurations[contentItem]; ok && len(release.Configurations) == 1 {
+		return content, nil
+	}
+	keys := make([]string, 0, len(release.Configurations))
+	for key := range release.Configurations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(key + "=" + release.Configurations[key] + "\n")
+	}
+	return b.String(), nil
+}
@@ -17,10 +17,372 @@
 
 package config_center
 
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+)
+
 // BaseDynamicConfiguration will default implementation DynamicConfiguration some method
-type BaseDynamicConfiguration struct{}
+type BaseDynamicConfiguration struct {
+	availabilityLock      sync.Mutex
+	unavailable           bool
+	availabilityListeners []func(available bool)
+
+	// the values read with WithMaxAge by group and key
+	cacheLock sync.Mutex
+	cache     map[string]cachedValue
+
+	// the parsers by group, which take precedence over the parser of the configuration
+	groupParsersLock sync.RWMutex
+	groupParsers     map[string]parser.ConfigurationParser
+}
+
+// cachedValue is a value together with when it is read from the backend
+type cachedValue struct {
+	value  string
+	readAt time.Time
+}
 
 // RemoveConfig
-func (bdc *BaseDynamicConfiguration) RemoveConfig(string, string) error {
+func (bdc *BaseDynamicConfiguration) RemoveConfig(string, string, ...Option) error {
 	return nil
 }
+
+// BackendInfo knows nothing about the backend, which should be described by the backends themselves
+func (bdc *BaseDynamicConfiguration) BackendInfo() BackendInfo {
+	return BackendInfo{}
+}
+
+// OnAvailabilityChange registers @listener to be called once the config center becomes unavailable or available again
+func (bdc *BaseDynamicConfiguration) OnAvailabilityChange(listener func(available bool)) {
+	bdc.availabilityLock.Lock()
+	defer bdc.availabilityLock.Unlock()
+	bdc.availabilityListeners = append(bdc.availabilityListeners, listener)
+}
+
+// SetGroupParser registers @p as the parser of the configs in @group, see ParserOf
+func (bdc *BaseDynamicConfiguration) SetGroupParser(group string, p parser.ConfigurationParser) {
+	bdc.groupParsersLock.Lock()
+	defer bdc.groupParsersLock.Unlock()
+	if bdc.groupParsers == nil {
+		bdc.groupParsers = make(map[string]parser.ConfigurationParser)
+	}
+	bdc.groupParsers[group] = p
+}
+
+// GroupParser returns the parser registered for @group, nil if it is absent
+func (bdc *BaseDynamicConfiguration) GroupParser(group string) parser.ConfigurationParser {
+	bdc.groupParsersLock.RLock()
+	defer bdc.groupParsersLock.RUnlock()
+	return bdc.groupParsers[group]
+}
+
+// Available returns whether the config center is available as notified by the backend
+func (bdc *BaseDynamicConfiguration) Available() bool {
+	bdc.availabilityLock.Lock()
+	defer bdc.availabilityLock.Unlock()
+	return !bdc.unavailable
+}
+
+// NotifyAvailability is called by the backends with the current availability,
+// the listeners are only called when it differs from the last one. The config center is available at first.
+func (bdc *BaseDynamicConfiguration) NotifyAvailability(available bool) {
+	bdc.availabilityLock.Lock()
+	if bdc.unavailable != available {
+		bdc.availabilityLock.Unlock()
+		return
+	}
+	bdc.unavailable = !available
+	listeners := make([]func(bool), len(bdc.availabilityListeners))
+	copy(listeners, bdc.availabilityListeners)
+	bdc.availabilityLock.Unlock()
+
+	for _, listener := range listeners {
+		listener(available)
+	}
+}
+
+// ReadWithMaxAge returns the cached value of @key in @group if it is younger than @maxAge, otherwise it reads
+// the value from the backend by @read and caches it. Nothing is cached if @maxAge is not positive.
+func (bdc *BaseDynamicConfiguration) ReadWithMaxAge(key string, group string, maxAge time.Duration,
+	read func() (string, error)) (string, error) {
+	if maxAge <= 0 {
+		return read()
+	}
+	cacheKey := group + "/" + key
+	bdc.cacheLock.Lock()
+	cached, ok := bdc.cache[cacheKey]
+	bdc.cacheLock.Unlock()
+	if ok && time.Since(cached.readAt) < maxAge {
+		return cached.value, nil
+	}
+
+	readAt := time.Now()
+	value, err := read()
+	if err != nil {
+		return "", err
+	}
+	bdc.cacheLock.Lock()
+	defer bdc.cacheLock.Unlock()
+	if bdc.cache == nil {
+		bdc.cache = make(map[string]cachedValue)
+	}
+	bdc.cache[cacheKey] = cachedValue{value: value, readAt: readAt}
+	return value, nil
+}
+
+// GetInt reads the value of @key from @c as an int. A missing key, i.e. IsNotFound, or an empty value gets
+// @defaultValue without error, while a malformed value is an error. The other errors of the backend, e.g. it is
+// unreachable, are returned together with @defaultValue, so the callers only caring about the value can ignore them.
+func GetInt(c DynamicConfiguration, key string, defaultValue int, opts ...Option) (int, error) {
+	value := defaultValue
+	err := getTyped(c, key, func(v string) (err error) {
+		value, err = strconv.Atoi(v)
+		return
+	}, opts...)
+	if err != nil {
+		return defaultValue, err
+	}
+	return value, nil
+}
+
+// GetBool reads the value of @key from @c as a bool, the same as GetInt
+func GetBool(c DynamicConfiguration, key string, defaultValue bool, opts ...Option) (bool, error) {
+	value := defaultValue
+	err := getTyped(c, key, func(v string) (err error) {
+		value, err = strconv.ParseBool(v)
+		return
+	}, opts...)
+	if err != nil {
+		return defaultValue, err
+	}
+	return value, nil
+}
+
+// GetDuration reads the value of @key from @c as a duration like "3s", the same as GetInt
+func GetDuration(c DynamicConfiguration, key string, defaultValue time.Duration, opts ...Option) (time.Duration, error) {
+	value := defaultValue
+	err := getTyped(c, key, func(v string) (err error) {
+		value, err = time.ParseDuration(v)
+		return
+	}, opts...)
+	if err != nil {
+		return defaultValue, err
+	}
+	return value, nil
+}
+
+// getTyped reads the value of @key and parses it by @parse if it exists and is not empty
+func getTyped(c DynamicConfiguration, key string, parse func(string) error, opts ...Option) error {
+	v, err := c.GetProperties(key, append(opts, WithEmptyAsBlank(true))...)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if v = strings.TrimSpace(v); len(v) == 0 {
+		return nil
+	}
+	if err = parse(v); err != nil {
+		return perrors.Wrapf(err, "malformed value %q of %s", v, key)
+	}
+	return nil
+}
+
+// DiffGroups compares the configs of @group in @a and @b, the backends can be of different types.
+// The values are paired as [value in a, value in b]: added are only in b, removed are only in a,
+// and changed are in both with different values.
+func DiffGroups(a, b DynamicConfiguration, group string) (added, removed, changed map[string][2]string, err error) {
+	aConfigs, err := readGroup(a, group)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bConfigs, err := readGroup(b, group)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	added, removed, changed = map[string][2]string{}, map[string][2]string{}, map[string][2]string{}
+	for key, aValue := range aConfigs {
+		bValue, ok := bConfigs[key]
+		if !ok {
+			removed[key] = [2]string{aValue, ""}
+		} else if aValue != bValue {
+			changed[key] = [2]string{aValue, bValue}
+		}
+	}
+	for key, bValue := range bConfigs {
+		if _, ok := aConfigs[key]; !ok {
+			added[key] = [2]string{"", bValue}
+		}
+	}
+	return added, removed, changed, nil
+}
+
+// ConfigKeysIterator is implemented by the backends able to iterate the keys of a group
+// without materializing them in a set, see IterateConfigKeys
+type ConfigKeysIterator interface {
+	IterateConfigKeys(group string, fn func(key string) bool) error
+}
+
+// IterateConfigKeys calls @fn with each key of @group in @c until it returns false.
+// The backends not implementing ConfigKeysIterator are iterated through GetConfigKeysByGroup.
+func IterateConfigKeys(c DynamicConfiguration, group string, fn func(key string) bool) error {
+	if iterator, ok := c.(ConfigKeysIterator); ok {
+		return iterator.IterateConfigKeys(group, fn)
+	}
+	keys, err := c.GetConfigKeysByGroup(group)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys.Values() {
+		if key, ok := k.(string); ok && !fn(key) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// KeyExistenceChecker is implemented by the backends able to check whether a key exists without reading its value
+type KeyExistenceChecker interface {
+	// Exists returns false without error if the key does not exist, the error means the existence is unknown
+	Exists(key string, opts ...Option) (bool, error)
+}
+
+// Exists checks whether @key exists in @c. The backends not implementing KeyExistenceChecker read the value
+// of the key, and only the key not found is taken as missing, any other error of the backend is returned.
+func Exists(c DynamicConfiguration, key string, opts ...Option) (bool, error) {
+	if checker, ok := c.(KeyExistenceChecker); ok {
+		return checker.Exists(key, opts...)
+	}
+	if _, err := c.GetProperties(key, opts...); err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// MissingKeysError lists the required keys missing in the config center, see RequireProperties
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	return "missing required keys: " + strings.Join(e.Keys, ", ")
+}
+
+// RequireProperties checks that all of @keys exist in @c, so that the missing configs fail the startup at once.
+// All the missing keys are listed by a *MissingKeysError, while the error of the backend is returned as it is,
+// for which the existence of the keys is unknown.
+func RequireProperties(c DynamicConfiguration, keys []string, opts ...Option) error {
+	var missing []string
+	for _, key := range keys {
+		exists, err := Exists(c, key, opts...)
+		if err != nil {
+			return perrors.WithMessagef(err, "check required key %s", key)
+		}
+		if !exists {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// readGroup reads all the configs of @group in @c
+func readGroup(c DynamicConfiguration, group string) (map[string]string, error) {
+	keys, err := c.GetConfigKeysByGroup(group)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "get config keys of group %s", group)
+	}
+	configs := make(map[string]string, keys.Size())
+	for _, k := range keys.Values() {
+		key, ok := k.(string)
+		if !ok {
+			continue
+		}
+		if configs[key], err = c.GetProperties(key, WithGroup(group)); err != nil {
+			return nil, perrors.WithMessagef(err, "get config %s of group %s", key, group)
+		}
+	}
+	return configs, nil
+}
+
+// GetPropertiesConcurrent reads @keys from @c by at most @parallelism reads at the same time, which suits
+// the backends reading key by key without a batch api. The values and the errors are returned by key, and
+// the failed keys are absent from the values. The reads are sequential if @parallelism is not positive.
+func GetPropertiesConcurrent(c DynamicConfiguration, keys []string, parallelism int,
+	opts ...Option) (map[string]string, map[string]error) {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	var (
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		values = make(map[string]string, len(keys))
+		errs   = make(map[string]error)
+		tokens = make(chan struct{}, parallelism)
+	)
+	for _, key := range keys {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			value, err := c.GetProperties(key, opts...)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs[key] = err
+				return
+			}
+			values[key] = value
+		}(key)
+	}
+	wg.Wait()
+	return values, errs
+}
+
+// ReadWithContext calls @read and waits for it until @ctx is done. The read keeps running in the background
+// once it is abandoned, the backends should call it to support the cancellation of hung reads.
+func ReadWithContext(ctx context.Context, read func() (string, error)) (string, error) {
+	if ctx.Done() == nil {
+		// never cancelled
+		return read()
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	type result struct {
+		value string
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		value, err := read()
+		ch <- result{value: value, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-ch:
+		return r.value, r.err
+	}
+}
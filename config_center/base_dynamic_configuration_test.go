@@ -0,0 +1,317 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+import (
+	gxset "github.com/dubbogo/gost/container/set"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errDynamicConfiguration struct {
+	MockDynamicConfiguration
+}
+
+func (c *errDynamicConfiguration) GetProperties(string, ...Option) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+// groupDynamicConfiguration keeps the configs in memory by group and key
+type groupDynamicConfiguration struct {
+	MockDynamicConfiguration
+	groups map[string]map[string]string
+}
+
+func (c *groupDynamicConfiguration) GetProperties(key string, opts ...Option) (string, error) {
+	tmpOpts := &Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	value, ok := c.groups[tmpOpts.Group][key]
+	if !ok {
+		return "", NotFound(errors.New("node does not exist"))
+	}
+	return value, nil
+}
+
+func (c *groupDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
+	keys := gxset.NewSet()
+	for key := range c.groups[group] {
+		keys.Add(key)
+	}
+	return keys, nil
+}
+
+// iteratorDynamicConfiguration iterates the keys without GetConfigKeysByGroup
+type iteratorDynamicConfiguration struct {
+	MockDynamicConfiguration
+	keys []string
+}
+
+func (c *iteratorDynamicConfiguration) GetConfigKeysByGroup(string) (*gxset.HashSet, error) {
+	return nil, errors.New("unexpected")
+}
+
+func (c *iteratorDynamicConfiguration) IterateConfigKeys(_ string, fn func(key string) bool) error {
+	for _, key := range c.keys {
+		if !fn(key) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func TestIterateConfigKeys(t *testing.T) {
+	configs := make(map[string]string, 10000)
+	keys := make([]string, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		key := "key" + strconv.Itoa(i)
+		configs[key] = strconv.Itoa(i)
+		keys = append(keys, key)
+	}
+
+	// through GetConfigKeysByGroup
+	c := &groupDynamicConfiguration{groups: map[string]map[string]string{"dubbo": configs}}
+	visited := make(map[string]bool)
+	assert.NoError(t, IterateConfigKeys(c, "dubbo", func(key string) bool {
+		visited[key] = true
+		return true
+	}))
+	assert.Equal(t, 10000, len(visited))
+	count := 0
+	assert.NoError(t, IterateConfigKeys(c, "dubbo", func(key string) bool {
+		count++
+		return count < 100
+	}))
+	assert.Equal(t, 100, count)
+
+	// through the iterator of the backend
+	iterator := &iteratorDynamicConfiguration{keys: keys}
+	var iterated []string
+	assert.NoError(t, IterateConfigKeys(iterator, "dubbo", func(key string) bool {
+		iterated = append(iterated, key)
+		return len(iterated) < 3
+	}))
+	assert.Equal(t, []string{"key0", "key1", "key2"}, iterated)
+}
+
+func TestGetInt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{name: "value", content: "20", want: 20},
+		{name: "blank", content: " 20\n", want: 20},
+		{name: "missing", content: "", want: 10},
+		{name: "malformed", content: "twenty", want: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetInt(&MockDynamicConfiguration{content: tt.content}, "dubbo.retries", 10)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := GetInt(&errDynamicConfiguration{}, "dubbo.retries", 10)
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 10, got)
+
+	// the key does not exist in the backend
+	got, err = GetInt(&groupDynamicConfiguration{}, "dubbo.retries", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, got)
+}
+
+func TestGetBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+		wantErr bool
+	}{
+		{name: "true", content: "true", want: true},
+		{name: "false", content: "false", want: false},
+		{name: "missing", content: "", want: true},
+		{name: "malformed", content: "yes", want: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetBool(&MockDynamicConfiguration{content: tt.content}, "dubbo.enabled", true)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := GetBool(&errDynamicConfiguration{}, "dubbo.enabled", true)
+	assert.Error(t, err)
+	assert.True(t, got)
+}
+
+func TestGetDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "value", content: "500ms", want: 500 * time.Millisecond},
+		{name: "missing", content: "", want: 3 * time.Second},
+		{name: "malformed", content: "500", want: 3 * time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetDuration(&MockDynamicConfiguration{content: tt.content}, "dubbo.timeout", 3*time.Second)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := GetDuration(&errDynamicConfiguration{}, "dubbo.timeout", 3*time.Second)
+	assert.Error(t, err)
+	assert.Equal(t, 3*time.Second, got)
+}
+
+func TestReadWithContext(t *testing.T) {
+	slow := func() (string, error) {
+		time.Sleep(time.Second)
+		return "slow", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := ReadWithContext(ctx, slow)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = ReadWithContext(ctx, slow)
+	assert.Equal(t, context.Canceled, err)
+
+	value, err := ReadWithContext(context.Background(), func() (string, error) {
+		return "fast", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", value)
+}
+
+func TestDiffGroups(t *testing.T) {
+	staging := &groupDynamicConfiguration{groups: map[string]map[string]string{
+		"dubbo": {"timeout": "3s", "retries": "2", "weight": "100"},
+		"other": {"ignored": "true"},
+	}}
+	production := &groupDynamicConfiguration{groups: map[string]map[string]string{
+		"dubbo": {"timeout": "5s", "weight": "100", "loadbalance": "random"},
+	}}
+
+	added, removed, changed, err := DiffGroups(staging, production, "dubbo")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][2]string{"loadbalance": {"", "random"}}, added)
+	assert.Equal(t, map[string][2]string{"retries": {"2", ""}}, removed)
+	assert.Equal(t, map[string][2]string{"timeout": {"3s", "5s"}}, changed)
+
+	added, removed, changed, err = DiffGroups(staging, staging, "dubbo")
+	assert.NoError(t, err)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+	assert.Empty(t, changed)
+}
+
+// slowDynamicConfiguration reads the configs slowly and records the max concurrent reads
+type slowDynamicConfiguration struct {
+	groupDynamicConfiguration
+	reading    int32
+	maxReading int32
+}
+
+func (c *slowDynamicConfiguration) GetProperties(key string, opts ...Option) (string, error) {
+	reading := atomic.AddInt32(&c.reading, 1)
+	defer atomic.AddInt32(&c.reading, -1)
+	for {
+		max := atomic.LoadInt32(&c.maxReading)
+		if reading <= max || atomic.CompareAndSwapInt32(&c.maxReading, max, reading) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return c.groupDynamicConfiguration.GetProperties(key, opts...)
+}
+
+func TestGetPropertiesConcurrent(t *testing.T) {
+	configs := map[string]string{}
+	keys := []string{"absent"}
+	for i := 0; i < 10; i++ {
+		key := "key" + strconv.Itoa(i)
+		configs[key] = strconv.Itoa(i)
+		keys = append(keys, key)
+	}
+	c := &slowDynamicConfiguration{groupDynamicConfiguration: groupDynamicConfiguration{
+		groups: map[string]map[string]string{"dubbo": configs}}}
+
+	values, errs := GetPropertiesConcurrent(c, keys, 3, WithGroup("dubbo"))
+	assert.Equal(t, configs, values)
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs["absent"], "node does not exist")
+	assert.True(t, c.maxReading > 1)
+	assert.True(t, c.maxReading <= 3)
+}
+
+// existsDynamicConfiguration checks the existence of the keys in memory, the check of the key broken fails
+type existsDynamicConfiguration struct {
+	groupDynamicConfiguration
+}
+
+func (c *existsDynamicConfiguration) Exists(key string, opts ...Option) (bool, error) {
+	if key == "broken" {
+		return false, errors.New("connection lost")
+	}
+	_, err := c.GetProperties(key, opts...)
+	return err == nil, nil
+}
+
+func TestRequireProperties(t *testing.T) {
+	c := &existsDynamicConfiguration{groupDynamicConfiguration{groups: map[string]map[string]string{
+		"dubbo": {"registry.address": "zookeeper://127.0.0.1:2181", "timeout": "3s"},
+	}}}
+
+	assert.NoError(t, RequireProperties(c, []string{"registry.address", "timeout"}, WithGroup("dubbo")))
+
+	// all the missing keys are listed
+	err := RequireProperties(c, []string{"registry.address", "app.name", "timeout", "app.owner"}, WithGroup("dubbo"))
+	var missing *MissingKeysError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, []string{"app.name", "app.owner"}, missing.Keys)
+	assert.EqualError(t, err, "missing required keys: app.name, app.owner")
+
+	// the backend error is not taken as missing
+	err = RequireProperties(c, []string{"app.name", "broken"}, WithGroup("dubbo"))
+	assert.False(t, errors.As(err, &missing))
+	assert.EqualError(t, err, "check required key broken: connection lost")
+}
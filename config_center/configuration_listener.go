@@ -36,8 +36,97 @@ type ConfigChangeEvent struct {
 	Key        string
 	Value      interface{}
 	ConfigType remoting.EventType
+	// Changes is the change of each item keyed by the item key,
+	// it is nil if the config center does not provide the detail
+	Changes map[string]*ConfigItemChange
+}
+
+// ConfigItemChange is the change of a single item within a ConfigChangeEvent
+type ConfigItemChange struct {
+	OldValue   interface{}
+	NewValue   interface{}
+	ChangeType remoting.EventType
 }
 
 func (c ConfigChangeEvent) String() string {
 	return fmt.Sprintf("ConfigChangeEvent{key = %v , value = %v , changeType = %v}", c.Key, c.Value, c.ConfigType)
 }
+
+// ListenerEntry is a ConfigurationListener together with the options it is registered with
+type ListenerEntry struct {
+	Listener ConfigurationListener
+	Priority int
+	// ChangeTypes are the change types dispatched to the listener, all of them if it is empty
+	ChangeTypes []remoting.EventType
+	// WatchType is which changes of the key are watched by the listener
+	WatchType WatchType
+}
+
+// WatchesData checks whether the changes of the value of the key should be dispatched to the listener
+func (e ListenerEntry) WatchesData() bool {
+	return e.WatchType != WatchChildren
+}
+
+// WatchesChildren checks whether the children added to or removed from the key should be dispatched to the listener
+func (e ListenerEntry) WatchesChildren() bool {
+	return e.WatchType == WatchChildren || e.WatchType == WatchBoth
+}
+
+// Accepts checks whether the change of @changeType should be dispatched to the listener
+func (e ListenerEntry) Accepts(changeType remoting.EventType) bool {
+	if len(e.ChangeTypes) == 0 {
+		return true
+	}
+	for _, t := range e.ChangeTypes {
+		if t == changeType {
+			return true
+		}
+	}
+	return false
+}
+
+// ListenerEntries keeps the listeners in dispatching order: higher priority first,
+// and registration order among listeners with the same priority.
+// It is never modified in place, so it is safe to range over it while it is being replaced.
+type ListenerEntries []ListenerEntry
+
+// Add returns the entries with @entry inserted, replacing the former registration of the same listener
+func (es ListenerEntries) Add(entry ListenerEntry) ListenerEntries {
+	result := make(ListenerEntries, 0, len(es)+1)
+	inserted := false
+	for _, e := range es {
+		if e.Listener == entry.Listener {
+			continue
+		}
+		if !inserted && e.Priority < entry.Priority {
+			result = append(result, entry)
+			inserted = true
+		}
+		result = append(result, e)
+	}
+	if !inserted {
+		result = append(result, entry)
+	}
+	return result
+}
+
+// Remove returns the entries without @listener
+func (es ListenerEntries) Remove(listener ConfigurationListener) ListenerEntries {
+	result := make(ListenerEntries, 0, len(es))
+	for _, e := range es {
+		if e.Listener != listener {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Contains checks whether @listener is registered
+func (es ListenerEntries) Contains(listener ConfigurationListener) bool {
+	for _, e := range es {
+		if e.Listener == listener {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+type mockListener struct {
+	name string
+}
+
+func (l *mockListener) Process(*ConfigChangeEvent) {}
+
+func TestListenerEntries(t *testing.T) {
+	a, b, c, d := &mockListener{"a"}, &mockListener{"b"}, &mockListener{"c"}, &mockListener{"d"}
+	var entries ListenerEntries
+	entries = entries.Add(ListenerEntry{Listener: a})
+	entries = entries.Add(ListenerEntry{Listener: b, Priority: 1})
+	entries = entries.Add(ListenerEntry{Listener: c})
+	entries = entries.Add(ListenerEntry{Listener: d, Priority: 1})
+	assert.Equal(t, ListenerEntries{{b, 1, nil, WatchData}, {d, 1, nil, WatchData}, {a, 0, nil, WatchData}, {c, 0, nil, WatchData}}, entries)
+	assert.True(t, entries.Contains(c))
+
+	// re-registering replaces the former priority
+	entries = entries.Add(ListenerEntry{Listener: a, Priority: 2})
+	assert.Equal(t, ListenerEntries{{a, 2, nil, WatchData}, {b, 1, nil, WatchData}, {d, 1, nil, WatchData}, {c, 0, nil, WatchData}}, entries)
+
+	removed := entries.Remove(b)
+	assert.Equal(t, ListenerEntries{{a, 2, nil, WatchData}, {d, 1, nil, WatchData}, {c, 0, nil, WatchData}}, removed)
+	assert.False(t, removed.Contains(b))
+	// the original entries are untouched
+	assert.True(t, entries.Contains(b))
+}
@@ -18,16 +18,21 @@
 package config_center
 
 import (
+	"errors"
+	"strings"
 	"time"
 )
 
 import (
 	gxset "github.com/dubbogo/gost/container/set"
+
+	perrors "github.com/pkg/errors"
 )
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
 
 // ////////////////////////////////////////
@@ -40,6 +45,50 @@ const (
 	DEFAULT_CONFIG_TIMEOUT = "10s"
 )
 
+var (
+	// ErrNamespaceNotFound means the namespace is not loaded from the config center
+	ErrNamespaceNotFound = perrors.New("namespace not found")
+	// ErrKeyNotFound means the namespace is loaded, but the key is not present in it
+	ErrKeyNotFound = perrors.New("key not found")
+	// ErrNoAddress means no address of the servers of the config center is configured
+	ErrNoAddress = perrors.New("no address of the config center")
+)
+
+// notFoundError is the error of the backend meaning the config does not exist, see NotFound
+type notFoundError struct {
+	err error
+}
+
+// NotFound marks @err of the backend as the config does not exist, so that IsNotFound reports true,
+// while the message and the cause of @err are kept, e.g. zk.ErrNoNode
+func NotFound(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &notFoundError{err: err}
+}
+
+func (e *notFoundError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notFoundError) Cause() error {
+	return perrors.Cause(e.err)
+}
+
+func (e *notFoundError) Unwrap() error {
+	return e.err
+}
+
+func (e *notFoundError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
+
+// IsNotFound checks whether @err means the config does not exist in the backend, rather than failing to read it
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrKeyNotFound) || errors.Is(err, ErrNamespaceNotFound)
+}
+
 // DynamicConfiguration for modify listener and get properties file
 type DynamicConfiguration interface {
 	Parser() parser.ConfigurationParser
@@ -58,19 +107,74 @@ type DynamicConfiguration interface {
 	// PublishConfig will publish the config with the (key, group, value) pair
 	// for zk: path is /$(group)/config/$(key) -> value
 	// for nacos: group, key -> value
-	PublishConfig(string, string, string) error
+	PublishConfig(string, string, string, ...Option) error
 
-	// RemoveConfig will remove the config white the (key, group) pair
-	RemoveConfig(string, string) error
+	// RemoveConfig will remove the config white the (key, group) pair,
+	// an empty key removes the whole group, which must be confirmed by WithConfirm
+	RemoveConfig(string, string, ...Option) error
 
 	// GetConfigKeysByGroup will return all keys with the group
 	GetConfigKeysByGroup(group string) (*gxset.HashSet, error)
+
+	// OnAvailabilityChange registers the listener called once the config center becomes unavailable or available again
+	OnAvailabilityChange(func(available bool))
+
+	// BackendInfo describes the backend of the config center and what it supports
+	BackendInfo() BackendInfo
+}
+
+// BackendInfo describes the backend of a DynamicConfiguration
+type BackendInfo struct {
+	// Protocol is the type of the backend, e.g. zookeeper
+	Protocol string
+	// Endpoints are the addresses of the servers of the backend
+	Endpoints []string
+	// Namespace is where the configs are kept in the backend, e.g. the namespace of apollo or the root path of zookeeper
+	Namespace string
+	// SupportsPublish is whether PublishConfig is supported
+	SupportsPublish bool
+	// SupportsKeysByGroup is whether GetConfigKeysByGroup is supported
+	SupportsKeysByGroup bool
+	// ReadOnly is whether the configs can not be changed through the config center at all
+	ReadOnly bool
 }
 
+// WatchType is which changes of the node of a key are watched by the listener
+type WatchType int
+
+const (
+	// WatchData watches the value of the key itself, which is the default
+	WatchData WatchType = iota
+	// WatchChildren watches the children added to or removed from the key
+	WatchChildren
+	// WatchBoth watches both the value and the children of the key
+	WatchBoth
+)
+
 // Options ...
 type Options struct {
-	Group   string
-	Timeout time.Duration
+	Group     string
+	Timeout   time.Duration
+	Ephemeral bool
+	Priority  int
+	// EmptyAsBlank reads an empty or missing config as "" rather than an error
+	EmptyAsBlank bool
+	// Confirm must be the group to remove a whole group
+	Confirm string
+	// ChangeTypes are the change types dispatched to the listener, all of them if it is empty
+	ChangeTypes []remoting.EventType
+	// WatchType is which changes of the key are watched by the listener, only supported by zookeeper
+	WatchType WatchType
+	// ValidateRule validates the published value as a governance rule of the type of the key before writing it
+	ValidateRule bool
+	// MaxAge is how old the cached value can be to be read instead of the backend, only supported by zookeeper
+	MaxAge time.Duration
+	// JSONPath extracts the value at the path from the json value read, see ExtractJSONPath
+	JSONPath string
+	// Schema is the json schema the value read must match, see ValidateJSONSchema
+	Schema string
+	// Release is the id of the historical release to read rather than the current one, only supported by apollo
+	Release string
 }
 
 // Option ...
@@ -90,6 +194,129 @@ func WithTimeout(time time.Duration) Option {
 	}
 }
 
+// WithEphemeral assigns ephemeral to opt.Ephemeral, the published config will be removed once the session closes
+func WithEphemeral(ephemeral bool) Option {
+	return func(opt *Options) {
+		opt.Ephemeral = ephemeral
+	}
+}
+
+// WithPriority assigns priority to opt.Priority, listeners with higher priority are notified first
+func WithPriority(priority int) Option {
+	return func(opt *Options) {
+		opt.Priority = priority
+	}
+}
+
+// WithEmptyAsBlank assigns emptyAsBlank to opt.EmptyAsBlank, an empty or missing config is read as "" rather than an error
+func WithEmptyAsBlank(emptyAsBlank bool) Option {
+	return func(opt *Options) {
+		opt.EmptyAsBlank = emptyAsBlank
+	}
+}
+
+// WithChangeTypes assigns types to opt.ChangeTypes, the listener is only notified of the changes of these types
+func WithChangeTypes(types ...remoting.EventType) Option {
+	return func(opt *Options) {
+		opt.ChangeTypes = types
+	}
+}
+
+// WithWatchType assigns watchType to opt.WatchType, which selects the value or the children of the key to watch
+func WithWatchType(watchType WatchType) Option {
+	return func(opt *Options) {
+		opt.WatchType = watchType
+	}
+}
+
+// WithValidateRule assigns validate to opt.ValidateRule, the published governance rule is validated before written
+func WithValidateRule(validate bool) Option {
+	return func(opt *Options) {
+		opt.ValidateRule = validate
+	}
+}
+
+// WithMaxAge assigns maxAge to opt.MaxAge, the value read from the backend within @maxAge is read from the cache
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(opt *Options) {
+		opt.MaxAge = maxAge
+	}
+}
+
+// WithJSONPath assigns expr to opt.JSONPath, only the value at the json path @expr of the json value read is returned
+func WithJSONPath(expr string) Option {
+	return func(opt *Options) {
+		opt.JSONPath = expr
+	}
+}
+
+// WithSchema assigns schema to opt.Schema, the value read is validated against the json @schema before returned
+func WithSchema(schema string) Option {
+	return func(opt *Options) {
+		opt.Schema = schema
+	}
+}
+
+// WithRelease assigns id to opt.Release, the historical release @id is read rather than the current one
+func WithRelease(id string) Option {
+	return func(opt *Options) {
+		opt.Release = id
+	}
+}
+
+// WithConfirm assigns group to opt.Confirm, which confirms removing the whole @group
+func WithConfirm(group string) Option {
+	return func(opt *Options) {
+		opt.Confirm = group
+	}
+}
+
+// CheckAddress makes sure there is at least one address of the servers in the location of @url, for the backends
+// which can not work without any
+func CheckAddress(url *common.URL) error {
+	for _, address := range strings.Split(url.Location, ",") {
+		if len(strings.TrimSpace(address)) > 0 {
+			return nil
+		}
+	}
+	return perrors.WithMessagef(ErrNoAddress, "create %s config center", url.Protocol)
+}
+
+// CheckRemoveConfirmed refuses removing the whole @group, i.e. @key is empty, unless it is confirmed by WithConfirm.
+// Removing without both key and group is always refused, which would remove all the configs.
+func CheckRemoveConfirmed(key string, group string, opts ...Option) error {
+	if len(key) > 0 {
+		return nil
+	}
+	if len(group) == 0 {
+		return perrors.New("removing without key and group is not allowed")
+	}
+	tmpOpts := &Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if tmpOpts.Confirm != group {
+		return perrors.Errorf("removing the whole group %s must be confirmed with the group name", group)
+	}
+	return nil
+}
+
+// CheckPublishedRule validates @value published with @key as the governance rule of the type of the key,
+// if it is required by WithValidateRule. The keys which are not governance rules are not validated.
+func CheckPublishedRule(key string, value string, opts ...Option) error {
+	tmpOpts := &Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if !tmpOpts.ValidateRule {
+		return nil
+	}
+	if ruleType := parser.RuleTypeOfKey(key); len(ruleType) > 0 {
+		return parser.ValidateRule(value, ruleType)
+	}
+	return nil
+}
+
 // GetRuleKey The format is '{interfaceName}:[version]:[group]'
 func GetRuleKey(url *common.URL) string {
 	return url.ColonSeparatedKey()
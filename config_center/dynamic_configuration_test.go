@@ -37,6 +37,26 @@ func TestWithTimeout(t *testing.T) {
 	assert.Equal(t, 12*time.Second, opt.Timeout)
 }
 
+func TestWithEphemeral(t *testing.T) {
+	opt := &Options{}
+	assert.False(t, opt.Ephemeral)
+	WithEphemeral(true)(opt)
+	assert.True(t, opt.Ephemeral)
+}
+
+func TestWithPriority(t *testing.T) {
+	opt := &Options{}
+	WithPriority(5)(opt)
+	assert.Equal(t, 5, opt.Priority)
+}
+
+func TestWithEmptyAsBlank(t *testing.T) {
+	fn := WithEmptyAsBlank(true)
+	opt := &Options{}
+	fn(opt)
+	assert.True(t, opt.EmptyAsBlank)
+}
+
 func TestGetRuleKey(t *testing.T) {
 	url, err := common.NewURL("dubbo://192.168.1.1:20000/com.ikurento.user.UserProvider?interface=test&group=groupA&version=0")
 	assert.NoError(t, err)
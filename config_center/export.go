@@ -0,0 +1,77 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+import (
+	"github.com/magiconair/properties"
+
+	perrors "github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// ExportFormatProperties is the format of dubbo.properties, which is read by the default parser
+	ExportFormatProperties = "properties"
+	// ExportFormatYaml exports the configs as a yaml map
+	ExportFormatYaml = "yaml"
+	// ExportFormatJson exports the configs as a json object
+	ExportFormatJson = "json"
+)
+
+// ExportGroupSerialized serializes all the configs of @group in @c into a single document of @format,
+// properties by default, which can be re-imported config by config. The configs are read by GetProperties,
+// so the values published in base64 are decoded by the backend before serialization.
+func ExportGroupSerialized(c DynamicConfiguration, group string, format string) ([]byte, error) {
+	configs, err := readGroup(c, group)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(format) {
+	case "", ExportFormatProperties:
+		keys := make([]string, 0, len(configs))
+		for key := range configs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		p := properties.NewProperties()
+		for _, key := range keys {
+			if _, _, err = p.Set(key, configs[key]); err != nil {
+				return nil, perrors.WithMessagef(err, "export config %s of group %s", key, group)
+			}
+		}
+		buf := &bytes.Buffer{}
+		if _, err = p.Write(buf, properties.UTF8); err != nil {
+			return nil, perrors.WithStack(err)
+		}
+		return buf.Bytes(), nil
+	case ExportFormatYaml:
+		return yaml.Marshal(configs)
+	case ExportFormatJson:
+		return json.Marshal(configs)
+	default:
+		return nil, perrors.Errorf("unsupported export format %s", format)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+)
+
+func newExportDynamicConfiguration() *groupDynamicConfiguration {
+	return &groupDynamicConfiguration{groups: map[string]map[string]string{
+		"dubbo": {
+			"dubbo.registries.zk.address": "127.0.0.1:2181",
+			"dubbo.application.name":      "BDTService",
+			"dubbo.rule":                  "line1\nline2",
+		},
+	}}
+}
+
+func TestExportGroupSerializedProperties(t *testing.T) {
+	c := newExportDynamicConfiguration()
+	content, err := ExportGroupSerialized(c, "dubbo", ExportFormatProperties)
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo.application.name = BDTService\n"+
+		"dubbo.registries.zk.address = 127.0.0.1:2181\n"+
+		"dubbo.rule = line1\\nline2\n", string(content))
+
+	// re-import
+	configs, err := (&parser.DefaultConfigurationParser{}).Parse(string(content))
+	assert.NoError(t, err)
+	assert.Equal(t, c.groups["dubbo"], configs)
+
+	_, err = ExportGroupSerialized(c, "dubbo", "toml")
+	assert.Error(t, err)
+}
+
+func TestExportGroupSerializedYaml(t *testing.T) {
+	c := newExportDynamicConfiguration()
+	content, err := ExportGroupSerialized(c, "dubbo", ExportFormatYaml)
+	assert.NoError(t, err)
+
+	configs := map[string]string{}
+	assert.NoError(t, yaml.Unmarshal(content, &configs))
+	assert.Equal(t, c.groups["dubbo"], configs)
+
+	content, err = ExportGroupSerialized(c, "empty", ExportFormatYaml)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}\n", string(content))
+}
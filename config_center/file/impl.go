@@ -91,6 +91,16 @@ func (fsdc *FileSystemDynamicConfiguration) RootPath() string {
 	return fsdc.rootPath
 }
 
+// BackendInfo describes the file system, in which the configs are files under the root path
+func (fsdc *FileSystemDynamicConfiguration) BackendInfo() config_center.BackendInfo {
+	return config_center.BackendInfo{
+		Protocol:            fsdc.url.Protocol,
+		Namespace:           fsdc.rootPath,
+		SupportsPublish:     true,
+		SupportsKeysByGroup: true,
+	}
+}
+
 // Parser Get Parser
 func (fsdc *FileSystemDynamicConfiguration) Parser() parser.ConfigurationParser {
 	return fsdc.parser
@@ -134,10 +144,13 @@ func (fsdc *FileSystemDynamicConfiguration) GetProperties(key string, opts ...co
 
 	tmpPath := fsdc.GetPath(key, tmpOpts.Group)
 	file, err := ioutil.ReadFile(tmpPath)
+	if os.IsNotExist(err) {
+		return "", config_center.NotFound(perrors.WithStack(err))
+	}
 	if err != nil {
 		return "", perrors.WithStack(err)
 	}
-	return string(file), nil
+	return config_center.ApplyReadOptions(string(file), opts...)
 }
 
 // GetRule get Router rule properties file
@@ -152,7 +165,10 @@ func (fsdc *FileSystemDynamicConfiguration) GetInternalProperty(key string, opts
 }
 
 // PublishConfig will publish the config with the (key, group, value) pair
-func (fsdc *FileSystemDynamicConfiguration) PublishConfig(key string, group string, value string) error {
+func (fsdc *FileSystemDynamicConfiguration) PublishConfig(key string, group string, value string, opts ...config_center.Option) error {
+	if err := config_center.CheckPublishedRule(key, value, opts...); err != nil {
+		return err
+	}
 	tmpPath := fsdc.GetPath(key, group)
 	return fsdc.write2File(tmpPath, value)
 }
@@ -177,7 +193,10 @@ func (fsdc *FileSystemDynamicConfiguration) GetConfigKeysByGroup(group string) (
 }
 
 // RemoveConfig will remove tconfig_center/nacos/impl_testhe config whit hte (key, group)
-func (fsdc *FileSystemDynamicConfiguration) RemoveConfig(key string, group string) error {
+func (fsdc *FileSystemDynamicConfiguration) RemoveConfig(key string, group string, opts ...config_center.Option) error {
+	if err := config_center.CheckRemoveConfirmed(key, group, opts...); err != nil {
+		return err
+	}
 	tmpPath := fsdc.GetPath(key, group)
 	_, err := fsdc.deleteDelay(tmpPath)
 	return err
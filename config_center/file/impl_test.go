@@ -18,6 +18,7 @@
 package file
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -25,6 +26,8 @@ import (
 )
 
 import (
+	perrors "github.com/pkg/errors"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -135,6 +138,57 @@ func TestGetConfig(t *testing.T) {
 	defer destroy(file.rootPath, file)
 }
 
+func TestGetTypedMissingKey(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+
+	_, err = file.GetProperties("dubbo.missing", config_center.WithGroup("dubbo"))
+	assert.True(t, os.IsNotExist(perrors.Cause(err)))
+	assert.True(t, config_center.IsNotFound(err))
+	retries, err := config_center.GetInt(file, "dubbo.missing", 3, config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, retries)
+
+	assert.NoError(t, file.PublishConfig("dubbo.retries", "dubbo", "five"))
+	retries, err = config_center.GetInt(file, "dubbo.retries", 3, config_center.WithGroup("dubbo"))
+	assert.Error(t, err)
+	assert.Equal(t, 3, retries)
+}
+
+func TestExists(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+
+	exists, err := config_center.Exists(file, "dubbo.missing", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, file.PublishConfig("dubbo.retries", "dubbo", "3"))
+	exists, err = config_center.Exists(file, "dubbo.retries", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestRequireProperties(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+	assert.NoError(t, file.PublishConfig("registry.address", "dubbo", "zookeeper://127.0.0.1:2181"))
+	assert.NoError(t, file.PublishConfig("timeout", "dubbo", "3s"))
+
+	assert.NoError(t, config_center.RequireProperties(file, []string{"registry.address", "timeout"},
+		config_center.WithGroup("dubbo")))
+
+	// all the missing keys are listed
+	err = config_center.RequireProperties(file, []string{"registry.address", "app.name", "timeout", "app.owner"},
+		config_center.WithGroup("dubbo"))
+	var missing *config_center.MissingKeysError
+	assert.True(t, errors.As(err, &missing))
+	assert.Equal(t, []string{"app.name", "app.owner"}, missing.Keys)
+}
+
 func TestPublishConfig(t *testing.T) {
 	file, err := initFileData(t)
 	assert.NoError(t, err)
@@ -148,6 +202,108 @@ func TestPublishConfig(t *testing.T) {
 	defer destroy(file.rootPath, file)
 }
 
+func TestRemoveGroupConfirm(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+	group := "dubbogo"
+	err = file.PublishConfig(key, group, "Test Value")
+	assert.NoError(t, err)
+
+	err = file.RemoveConfig("", group)
+	assert.Error(t, err)
+	err = file.RemoveConfig("", group, config_center.WithConfirm("dubbo"))
+	assert.Error(t, err)
+	_, err = file.GetProperties(key, config_center.WithGroup(group))
+	assert.NoError(t, err)
+
+	err = file.RemoveConfig("", group, config_center.WithConfirm(group))
+	assert.NoError(t, err)
+	_, err = file.GetProperties(key, config_center.WithGroup(group))
+	assert.Error(t, err)
+}
+
+func TestRemoveWithoutKeyAndGroup(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+	err = file.PublishConfig(key, "dubbogo", "Test Value")
+	assert.NoError(t, err)
+
+	// the whole root is never removed, confirmed or not
+	assert.Error(t, file.RemoveConfig("", ""))
+	assert.Error(t, file.RemoveConfig("", "", config_center.WithConfirm("")))
+	_, err = os.Stat(file.rootPath)
+	assert.NoError(t, err)
+	_, err = file.GetProperties(key, config_center.WithGroup("dubbogo"))
+	assert.NoError(t, err)
+}
+
+func TestPublishValidatedRule(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+	group := "dubbogo"
+	ruleKey := "demo-app.condition-router"
+	invalid := "scope: application\nkey: demo-app\nconditions:\n- host = => \n"
+
+	// published as it is without validation
+	err = file.PublishConfig(ruleKey, group, invalid)
+	assert.NoError(t, err)
+
+	err = file.PublishConfig(ruleKey, group, invalid+"- method = find*\n", config_center.WithValidateRule(true))
+	assert.Error(t, err)
+	prop, err := file.GetProperties(ruleKey, config_center.WithGroup(group))
+	assert.NoError(t, err)
+	assert.Equal(t, invalid, prop)
+
+	valid := "scope: application\nkey: demo-app\nconditions:\n- host = 127.0.0.1 => host = 127.0.0.2\n"
+	err = file.PublishConfig(ruleKey, group, valid, config_center.WithValidateRule(true))
+	assert.NoError(t, err)
+	prop, err = file.GetProperties(ruleKey, config_center.WithGroup(group))
+	assert.NoError(t, err)
+	assert.Equal(t, valid, prop)
+}
+
+func TestGetConfigWithJSONPath(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+	group := "dubbogo"
+	key := "datasource.json"
+	err = file.PublishConfig(key, group, `{"datasource": {"replicas": [{"host": "10.0.0.1"}, {"host": "10.0.0.2"}]}}`)
+	assert.NoError(t, err)
+
+	prop, err := file.GetProperties(key, config_center.WithGroup(group), config_center.WithJSONPath("$.datasource.replicas[1].host"))
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", prop)
+	_, err = file.GetProperties(key, config_center.WithGroup(group), config_center.WithJSONPath("$.datasource.primary"))
+	assert.Equal(t, config_center.ErrJSONPathNotMatched, perrors.Cause(err))
+}
+
+func TestGetConfigWithSchema(t *testing.T) {
+	file, err := initFileData(t)
+	assert.NoError(t, err)
+	defer destroy(file.rootPath, file)
+	group := "dubbogo"
+	schema := `{"type": "object", "required": ["host"], "properties": {"port": {"type": "integer"}}}`
+	err = file.PublishConfig("valid.json", group, `{"host": "10.0.0.1", "port": 3306}`)
+	assert.NoError(t, err)
+	err = file.PublishConfig("invalid.json", group, `{"host": "10.0.0.1", "port": "3306"}`)
+	assert.NoError(t, err)
+
+	prop, err := file.GetProperties("valid.json", config_center.WithGroup(group), config_center.WithSchema(schema),
+		config_center.WithJSONPath("$.host"))
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", prop)
+	_, err = file.GetProperties("invalid.json", config_center.WithGroup(group), config_center.WithSchema(schema))
+	assert.Equal(t, config_center.ErrSchemaMismatch, perrors.Cause(err))
+	assert.EqualError(t, err, "$.port: expected integer, got string: schema mismatch")
+	// not validated without the schema
+	_, err = file.GetProperties("invalid.json", config_center.WithGroup(group))
+	assert.NoError(t, err)
+}
+
 func destroy(path string, fdc *FileSystemDynamicConfiguration) {
 	fdc.Close()
 	os.RemoveAll(path)
@@ -0,0 +1,121 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+// ErrJSONPathNotMatched means the json path doesn't match any value in the json content
+var ErrJSONPathNotMatched = perrors.New("json path not matched")
+
+// jsonPathStep is either a field name or an array index of the json path
+type jsonPathStep struct {
+	field   string
+	index   int
+	isIndex bool
+}
+
+// ExtractJSONPath returns the value at @expr in the json @content. The path is made of the field names separated
+// by '.' and the array indexes like "[0]", optionally starting with "$", e.g. "$.datasource.replicas[0].host".
+// The string value is returned as is, and the other values are returned as json.
+func ExtractJSONPath(content string, expr string) (string, error) {
+	steps, err := parseJSONPath(expr)
+	if err != nil {
+		return "", err
+	}
+	var value interface{}
+	if err = json.Unmarshal([]byte(content), &value); err != nil {
+		return "", perrors.Wrap(err, "the content is not valid json")
+	}
+	for _, step := range steps {
+		if step.isIndex {
+			items, ok := value.([]interface{})
+			if !ok || step.index >= len(items) {
+				return "", perrors.WithMessagef(ErrJSONPathNotMatched, "no item [%d] in %s", step.index, expr)
+			}
+			value = items[step.index]
+			continue
+		}
+		fields, ok := value.(map[string]interface{})
+		if ok {
+			value, ok = fields[step.field]
+		}
+		if !ok {
+			return "", perrors.WithMessagef(ErrJSONPathNotMatched, "no field %s in %s", step.field, expr)
+		}
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return "", perrors.WithStack(err)
+	}
+	return string(bytes), nil
+}
+
+// parseJSONPath splits @expr into the field names and the array indexes
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	path := strings.TrimPrefix(strings.TrimPrefix(expr, "$"), ".")
+	var steps []jsonPathStep
+	for _, part := range strings.Split(path, ".") {
+		field := part
+		if i := strings.Index(part, "["); i >= 0 {
+			field = part[:i]
+		}
+		if len(field) > 0 {
+			steps = append(steps, jsonPathStep{field: field})
+		}
+		for rest := part[len(field):]; len(rest) > 0; {
+			end := strings.Index(rest, "]")
+			if rest[0] != '[' || end < 0 {
+				return nil, perrors.Errorf("invalid json path %s", expr)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, perrors.Errorf("invalid array index %s in json path %s", rest[1:end], expr)
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+			rest = rest[end+1:]
+		}
+		if len(part) == 0 && len(path) > 0 {
+			return nil, perrors.Errorf("invalid json path %s", expr)
+		}
+	}
+	return steps, nil
+}
+
+// ApplyJSONPath extracts the value at the json path given by WithJSONPath from @value read from the config center,
+// @value is returned as is if there is no json path
+func ApplyJSONPath(value string, opts ...Option) (string, error) {
+	tmpOpts := &Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if len(tmpOpts.JSONPath) == 0 {
+		return value, nil
+	}
+	return ExtractJSONPath(value, tmpOpts.JSONPath)
+}
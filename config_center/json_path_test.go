@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"testing"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractJSONPath(t *testing.T) {
+	content := `{"datasource": {"url": "jdbc:mysql://db:3306", "pool": {"max": 20, "idle": true},
+		"replicas": [{"host": "10.0.0.1"}, {"host": "10.0.0.2", "ports": [3306, 3307]}]}}`
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "$.datasource.url", want: "jdbc:mysql://db:3306"},
+		{expr: "datasource.url", want: "jdbc:mysql://db:3306"},
+		{expr: "$.datasource.pool.max", want: "20"},
+		{expr: "$.datasource.pool", want: `{"idle":true,"max":20}`},
+		{expr: "$.datasource.replicas[1].host", want: "10.0.0.2"},
+		{expr: "$.datasource.replicas[1].ports[0]", want: "3306"},
+		{expr: "$", want: `{"datasource":{"pool":{"idle":true,"max":20},"replicas":[{"host":"10.0.0.1"},{"host":"10.0.0.2","ports":[3306,3307]}],"url":"jdbc:mysql://db:3306"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			value, err := ExtractJSONPath(content, tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+
+	// the path missing
+	for _, expr := range []string{"$.datasource.user", "$.datasource.replicas[2]", "$.datasource.url.host", "$.datasource.pool[0]"} {
+		_, err := ExtractJSONPath(content, expr)
+		assert.Equal(t, ErrJSONPathNotMatched, perrors.Cause(err), expr)
+	}
+	// the path or content invalid
+	for _, expr := range []string{"$.datasource..url", "$.datasource.replicas[x]", "$.datasource.replicas[1"} {
+		_, err := ExtractJSONPath(content, expr)
+		assert.Error(t, err, expr)
+		assert.NotEqual(t, ErrJSONPathNotMatched, perrors.Cause(err), expr)
+	}
+	_, err := ExtractJSONPath("datasource.url=jdbc:mysql://db:3306", "$.datasource.url")
+	assert.EqualError(t, err, "the content is not valid json: invalid character 'd' looking for beginning of value")
+
+	// only extracted with the path
+	value, err := ApplyJSONPath(content)
+	assert.NoError(t, err)
+	assert.Equal(t, content, value)
+	value, err = ApplyJSONPath(content, WithJSONPath("$.datasource.replicas[0].host"))
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", value)
+}
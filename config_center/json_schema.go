@@ -0,0 +1,213 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+// ErrSchemaMismatch means the value read from the config center doesn't match the json schema given by WithSchema
+var ErrSchemaMismatch = perrors.New("schema mismatch")
+
+// jsonSchema is the subset of the json schema supported by ValidateJSONSchema
+type jsonSchema struct {
+	Type                 interface{}            `json:"type"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	Enum                 []interface{}          `json:"enum"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+}
+
+// ValidateJSONSchema validates the json @content against the json @schema. The keywords type, properties, required,
+// additionalProperties, items, enum, minimum, maximum, minLength, maxLength, pattern, minItems and maxItems are
+// supported, and the others are ignored. The mismatch is ErrSchemaMismatch with the json path of the invalid value.
+func ValidateJSONSchema(content string, schema string) error {
+	s := &jsonSchema{}
+	if err := json.Unmarshal([]byte(schema), s); err != nil {
+		return perrors.Wrap(err, "the schema is not valid json")
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return perrors.WithMessage(ErrSchemaMismatch, "the content is not valid json: "+err.Error())
+	}
+	return s.validate("$", value)
+}
+
+func (s *jsonSchema) validate(path string, value interface{}) error {
+	if err := s.validateType(path, value); err != nil {
+		return err
+	}
+	if len(s.Enum) > 0 {
+		matched := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return schemaMismatch(path, "%v is not one of %v", value, s.Enum)
+		}
+	}
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return schemaMismatch(path, "%v is less than the minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return schemaMismatch(path, "%v is greater than the maximum %v", v, *s.Maximum)
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			return schemaMismatch(path, "the length %d is less than the min length %d", length, *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return schemaMismatch(path, "the length %d is greater than the max length %d", length, *s.MaxLength)
+		}
+		if len(s.Pattern) > 0 {
+			matched, err := regexp.MatchString(s.Pattern, v)
+			if err != nil {
+				return perrors.Wrapf(err, "invalid pattern %s at %s of the schema", s.Pattern, path)
+			}
+			if !matched {
+				return schemaMismatch(path, "%q doesn't match the pattern %s", v, s.Pattern)
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return schemaMismatch(path, "%d items are less than the min items %d", len(v), *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return schemaMismatch(path, "%d items are more than the max items %d", len(v), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return schemaMismatch(path, "the required field %s is missing", name)
+			}
+		}
+		// the fields are validated in order so that the error is deterministic
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return schemaMismatch(path, "the field %s is not allowed", name)
+				}
+				continue
+			}
+			if err := property.validate(path+"."+name, v[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateType checks the type of @value against the type, or any of the types, of the schema
+func (s *jsonSchema) validateType(path string, value interface{}) error {
+	var types []string
+	switch t := s.Type.(type) {
+	case nil:
+		return nil
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+	actual := jsonTypeOf(value)
+	for _, name := range types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return schemaMismatch(path, "expected %s, got %s", strings.Join(types, " or "), actual)
+}
+
+// jsonTypeOf returns the json schema type of @value decoded by encoding/json
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func schemaMismatch(path string, format string, args ...interface{}) error {
+	return perrors.WithMessagef(ErrSchemaMismatch, "%s: %s", path, fmt.Sprintf(format, args...))
+}
+
+// ApplyReadOptions applies the options on @value read from the config center. @value is validated against the
+// json schema given by WithSchema, and then the value at the json path given by WithJSONPath is extracted.
+func ApplyReadOptions(value string, opts ...Option) (string, error) {
+	tmpOpts := &Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	if len(tmpOpts.Schema) > 0 {
+		if err := ValidateJSONSchema(value, tmpOpts.Schema); err != nil {
+			return "", err
+		}
+	}
+	return ApplyJSONPath(value, opts...)
+}
@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"testing"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := `{
+		"type": "object",
+		"required": ["url", "replicas"],
+		"additionalProperties": false,
+		"properties": {
+			"url": {"type": "string", "pattern": "^jdbc:", "maxLength": 64},
+			"mode": {"enum": ["primary", "replica"]},
+			"pool": {"type": "integer", "minimum": 1, "maximum": 100},
+			"ratio": {"type": ["number", "null"]},
+			"replicas": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["host"]}}
+		}
+	}`
+	assert.NoError(t, ValidateJSONSchema(`{"url": "jdbc:mysql://db:3306", "mode": "primary", "pool": 20,
+		"ratio": 0.5, "replicas": [{"host": "10.0.0.1"}]}`, schema))
+	assert.NoError(t, ValidateJSONSchema(`{"url": "jdbc:mysql://db:3306", "ratio": null, "replicas": [{"host": "10.0.0.1"}]}`, schema))
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: `[]`, want: "$: expected object, got array"},
+		{content: `{"replicas": []}`, want: "$: the required field url is missing"},
+		{content: `{"url": "mysql://db", "replicas": [{"host": "10.0.0.1"}]}`, want: `$.url: "mysql://db" doesn't match the pattern ^jdbc:`},
+		{content: `{"url": "jdbc:", "replicas": []}`, want: "$.replicas: 0 items are less than the min items 1"},
+		{content: `{"url": "jdbc:", "replicas": [{"port": 3306}]}`, want: "$.replicas[0]: the required field host is missing"},
+		{content: `{"url": "jdbc:", "replicas": [{}], "pool": 0}`, want: "$.pool: 0 is less than the minimum 1"},
+		{content: `{"url": "jdbc:", "replicas": [{}], "pool": 1.5}`, want: "$.pool: expected integer, got number"},
+		{content: `{"url": "jdbc:", "replicas": [{}], "mode": "backup"}`, want: "$.mode: backup is not one of [primary replica]"},
+		{content: `{"url": "jdbc:", "replicas": [{}], "ratio": "0.5"}`, want: "$.ratio: expected number or null, got string"},
+		{content: `{"url": "jdbc:", "replicas": [{"host": "10.0.0.1"}], "user": "root"}`, want: "$: the field user is not allowed"},
+		{content: `url=jdbc:`, want: "the content is not valid json: invalid character 'u' looking for beginning of value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			err := ValidateJSONSchema(tt.content, schema)
+			assert.Equal(t, ErrSchemaMismatch, perrors.Cause(err))
+			assert.EqualError(t, err, tt.want+": schema mismatch")
+		})
+	}
+
+	_, err := ApplyReadOptions(`{"url": "jdbc:"}`, WithSchema("{"))
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrSchemaMismatch, perrors.Cause(err))
+	// only validated with the schema
+	value, err := ApplyReadOptions(`{"url": "jdbc:"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"url": "jdbc:"}`, value)
+}
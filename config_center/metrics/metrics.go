@@ -0,0 +1,129 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+import (
+	uatomic "go.uber.org/atomic"
+)
+
+// Hook receives the metrics of the config centers, it must be safe for concurrent use
+type Hook interface {
+	// OnCacheHit is called when a read of @key is served by the local cache of the config center
+	OnCacheHit(protocol string, key string)
+	// OnCacheMiss is called when a read of @key goes to the backend of the config center
+	OnCacheMiss(protocol string, key string)
+}
+
+// OperationHook is implemented by the hooks receiving the operations of the config centers besides the cache stats
+type OperationHook interface {
+	// OnOperation is called once @operation of the config center of @protocol finishes in @duration,
+	// @err is the error of the operation, nil if it succeeded
+	OnOperation(protocol string, operation string, duration time.Duration, err error)
+}
+
+const (
+	// OperationRead reads a config from the backend
+	OperationRead = "read"
+	// OperationWrite publishes a config to the backend
+	OperationWrite = "write"
+	// OperationEvent dispatches a change of the backend to the listeners
+	OperationEvent = "event"
+)
+
+// CacheStats is the number of the reads served by the local cache and the backend
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+type cacheCounter struct {
+	hits   uatomic.Uint64
+	misses uatomic.Uint64
+}
+
+var (
+	hooksLock sync.RWMutex
+	hooks     = make(map[string]Hook)
+	// cacheCounters stores the cacheCounter of each protocol
+	cacheCounters sync.Map
+)
+
+// SetHook registers @hook with @name
+func SetHook(name string, hook Hook) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	hooks[name] = hook
+}
+
+// RemoveHook removes the hook registered with @name
+func RemoveHook(name string) {
+	hooksLock.Lock()
+	defer hooksLock.Unlock()
+	delete(hooks, name)
+}
+
+// CacheHit records a read of @key served by the local cache of the config center of @protocol
+func CacheHit(protocol string, key string) {
+	getCacheCounter(protocol).hits.Inc()
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	for _, hook := range hooks {
+		hook.OnCacheHit(protocol, key)
+	}
+}
+
+// CacheMiss records a read of @key going to the backend of the config center of @protocol
+func CacheMiss(protocol string, key string) {
+	getCacheCounter(protocol).misses.Inc()
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	for _, hook := range hooks {
+		hook.OnCacheMiss(protocol, key)
+	}
+}
+
+// RecordOperation records @operation of the config center of @protocol started at @start and failed with @err if any
+func RecordOperation(protocol string, operation string, start time.Time, err error) {
+	duration := time.Since(start)
+	hooksLock.RLock()
+	defer hooksLock.RUnlock()
+	for _, hook := range hooks {
+		if operationHook, ok := hook.(OperationHook); ok {
+			operationHook.OnOperation(protocol, operation, duration, err)
+		}
+	}
+}
+
+// GetCacheStats returns the cache stats of the config center of @protocol
+func GetCacheStats(protocol string) CacheStats {
+	counter := getCacheCounter(protocol)
+	return CacheStats{Hits: counter.hits.Load(), Misses: counter.misses.Load()}
+}
+
+func getCacheCounter(protocol string) *cacheCounter {
+	if counter, ok := cacheCounters.Load(protocol); ok {
+		return counter.(*cacheCounter)
+	}
+	counter, _ := cacheCounters.LoadOrStore(protocol, &cacheCounter{})
+	return counter.(*cacheCounter)
+}
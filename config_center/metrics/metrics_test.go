@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHook struct {
+	hits   []string
+	misses []string
+}
+
+func (h *mockHook) OnCacheHit(_ string, key string) {
+	h.hits = append(h.hits, key)
+}
+
+func (h *mockHook) OnCacheMiss(_ string, key string) {
+	h.misses = append(h.misses, key)
+}
+
+type mockOperationHook struct {
+	mockHook
+	operations []string
+	errors     int
+}
+
+func (h *mockOperationHook) OnOperation(_ string, operation string, _ time.Duration, err error) {
+	h.operations = append(h.operations, operation)
+	if err != nil {
+		h.errors++
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	hook := &mockHook{}
+	SetHook("mock", hook)
+	CacheMiss("mock", "dubbo.properties")
+	CacheHit("mock", "dubbo.properties")
+	CacheHit("mock", "dubbo.properties")
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, GetCacheStats("mock"))
+	assert.Equal(t, CacheStats{}, GetCacheStats("unknown"))
+	assert.Equal(t, []string{"dubbo.properties", "dubbo.properties"}, hook.hits)
+	assert.Equal(t, []string{"dubbo.properties"}, hook.misses)
+
+	RemoveHook("mock")
+	CacheHit("mock", "dubbo.properties")
+	assert.Equal(t, 2, len(hook.hits))
+	assert.Equal(t, uint64(3), GetCacheStats("mock").Hits)
+}
+
+func TestRecordOperation(t *testing.T) {
+	hook := &mockOperationHook{}
+	SetHook("operation", hook)
+	// the hooks not receiving the operations are skipped
+	SetHook("cache", &mockHook{})
+	defer RemoveHook("operation")
+	defer RemoveHook("cache")
+
+	RecordOperation("mock", OperationRead, time.Now(), nil)
+	RecordOperation("mock", OperationWrite, time.Now(), assert.AnError)
+	assert.Equal(t, []string{OperationRead, OperationWrite}, hook.operations)
+	assert.Equal(t, 1, hook.errors)
+}
@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"sync"
+	"time"
+)
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
+)
+
+const (
+	// the name of the hook feeding the collector
+	hookName = "prometheus"
+
+	namespace = "dubbo"
+	subsystem = "config_center"
+
+	backendLabel   = "backend"
+	operationLabel = "operation"
+	resultLabel    = "result"
+)
+
+var (
+	collector     *Collector
+	collectorOnce sync.Once
+)
+
+// Collector is a prometheus.Collector exporting the metrics of the config centers, which is fed through
+// the metrics hooks. The operations are labeled by the backend, e.g. zookeeper, and the operation, e.g. read.
+type Collector struct {
+	operations *prom.CounterVec
+	errors     *prom.CounterVec
+	durations  *prom.HistogramVec
+	cache      *prom.CounterVec
+}
+
+// NewCollector returns a collector not fed by any config center yet, see Register
+func NewCollector() *Collector {
+	return &Collector{
+		operations: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "operations_total",
+			Help:      "The number of the operations of the config centers.",
+		}, []string{backendLabel, operationLabel}),
+		errors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "errors_total",
+			Help:      "The number of the failed operations of the config centers.",
+		}, []string{backendLabel, operationLabel}),
+		durations: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "operation_duration_seconds",
+			Help:      "The duration of the operations of the config centers.",
+			Buckets:   prom.DefBuckets,
+		}, []string{backendLabel, operationLabel}),
+		cache: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "cache_total",
+			Help:      "The number of the reads served by the local cache (hit) or the backend (miss).",
+		}, []string{backendLabel, resultLabel}),
+	}
+}
+
+// Register creates the collector at the first call, feeds it with the metrics of the config centers, and registers
+// it to @registerer, the default registerer of prometheus if it is nil. Nothing is collected until it is called.
+func Register(registerer prom.Registerer) (*Collector, error) {
+	collectorOnce.Do(func() {
+		collector = NewCollector()
+		metrics.SetHook(hookName, collector)
+	})
+	if registerer == nil {
+		registerer = prom.DefaultRegisterer
+	}
+	if err := registerer.Register(collector); err != nil {
+		if _, ok := err.(prom.AlreadyRegisteredError); !ok {
+			return nil, err
+		}
+	}
+	return collector, nil
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	c.operations.Describe(ch)
+	c.errors.Describe(ch)
+	c.durations.Describe(ch)
+	c.cache.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	c.operations.Collect(ch)
+	c.errors.Collect(ch)
+	c.durations.Collect(ch)
+	c.cache.Collect(ch)
+}
+
+// OnCacheHit implements metrics.Hook
+func (c *Collector) OnCacheHit(protocol string, _ string) {
+	c.cache.WithLabelValues(protocol, "hit").Inc()
+}
+
+// OnCacheMiss implements metrics.Hook
+func (c *Collector) OnCacheMiss(protocol string, _ string) {
+	c.cache.WithLabelValues(protocol, "miss").Inc()
+}
+
+// OnOperation implements metrics.OperationHook
+func (c *Collector) OnOperation(protocol string, operation string, duration time.Duration, err error) {
+	c.operations.WithLabelValues(protocol, operation).Inc()
+	c.durations.WithLabelValues(protocol, operation).Observe(duration.Seconds())
+	if err != nil {
+		c.errors.WithLabelValues(protocol, operation).Inc()
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
+)
+
+func TestCollectorScrape(t *testing.T) {
+	registry := prom.NewRegistry()
+	collector, err := Register(registry)
+	assert.NoError(t, err)
+	defer metrics.RemoveHook(hookName)
+
+	// registering again is tolerated
+	again, err := Register(registry)
+	assert.NoError(t, err)
+	assert.Equal(t, collector, again)
+
+	start := time.Now()
+	metrics.CacheHit("zookeeper", "key")
+	metrics.CacheMiss("zookeeper", "key")
+	metrics.CacheMiss("zookeeper", "key")
+	metrics.RecordOperation("zookeeper", metrics.OperationRead, start, nil)
+	metrics.RecordOperation("zookeeper", metrics.OperationRead, start, perrors.New("mock"))
+	metrics.RecordOperation("apollo", metrics.OperationEvent, start, nil)
+
+	expected := `
+# HELP dubbo_config_center_cache_total The number of the reads served by the local cache (hit) or the backend (miss).
+# TYPE dubbo_config_center_cache_total counter
+dubbo_config_center_cache_total{backend="zookeeper",result="hit"} 1
+dubbo_config_center_cache_total{backend="zookeeper",result="miss"} 2
+# HELP dubbo_config_center_errors_total The number of the failed operations of the config centers.
+# TYPE dubbo_config_center_errors_total counter
+dubbo_config_center_errors_total{backend="zookeeper",operation="read"} 1
+# HELP dubbo_config_center_operations_total The number of the operations of the config centers.
+# TYPE dubbo_config_center_operations_total counter
+dubbo_config_center_operations_total{backend="apollo",operation="event"} 1
+dubbo_config_center_operations_total{backend="zookeeper",operation="read"} 2
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"dubbo_config_center_cache_total", "dubbo_config_center_errors_total", "dubbo_config_center_operations_total"))
+	assert.Equal(t, 2, testutil.CollectAndCount(collector.durations))
+}
@@ -87,7 +87,7 @@ func (f *MockDynamicConfigurationFactory) GetDynamicConfiguration(_ *common.URL)
 }
 
 // PublishConfig will publish the config with the (key, group, value) pair
-func (c *MockDynamicConfiguration) PublishConfig(string, string, string) error {
+func (c *MockDynamicConfiguration) PublishConfig(string, string, string, ...Option) error {
 	return nil
 }
 
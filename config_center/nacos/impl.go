@@ -62,6 +62,9 @@ type nacosDynamicConfiguration struct {
 }
 
 func newNacosDynamicConfiguration(url *common.URL) (*nacosDynamicConfiguration, error) {
+	if err := config_center.CheckAddress(url); err != nil {
+		return nil, err
+	}
 	c := &nacosDynamicConfiguration{
 		rootPath: "/" + url.GetParam(constant.CONFIG_NAMESPACE_KEY, config_center.DEFAULT_GROUP) + "/config",
 		url:      url,
@@ -89,7 +92,11 @@ func (n *nacosDynamicConfiguration) RemoveListener(key string, listener config_c
 
 // GetProperties nacos distinguishes configuration files based on group and dataId. defalut group = "dubbo" and dataId = key
 func (n *nacosDynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
-	return n.GetRule(key, opts...)
+	content, err := n.GetRule(key, opts...)
+	if err != nil {
+		return "", err
+	}
+	return config_center.ApplyReadOptions(content, opts...)
 }
 
 // GetInternalProperty Get properties value by key
@@ -98,7 +105,10 @@ func (n *nacosDynamicConfiguration) GetInternalProperty(key string, opts ...conf
 }
 
 // PublishConfig will publish the config with the (key, group, value) pair
-func (n *nacosDynamicConfiguration) PublishConfig(key string, group string, value string) error {
+func (n *nacosDynamicConfiguration) PublishConfig(key string, group string, value string, opts ...config_center.Option) error {
+	if err := config_center.CheckPublishedRule(key, value, opts...); err != nil {
+		return err
+	}
 	group = n.resolvedGroup(group)
 
 	ok, err := n.client.Client().PublishConfig(vo.ConfigParam{
@@ -136,6 +146,17 @@ func (n *nacosDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.H
 	return result, nil
 }
 
+// BackendInfo describes nacos, which supports both publishing and listing the keys of a group
+func (n *nacosDynamicConfiguration) BackendInfo() config_center.BackendInfo {
+	return config_center.BackendInfo{
+		Protocol:            n.url.Protocol,
+		Endpoints:           strings.Split(n.url.Location, ","),
+		Namespace:           n.url.GetParam(constant.CONFIG_NAMESPACE_KEY, ""),
+		SupportsPublish:     true,
+		SupportsKeysByGroup: true,
+	}
+}
+
 // GetRule Get router rule
 func (n *nacosDynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
 	tmpOpts := &config_center.Options{}
@@ -18,6 +18,7 @@
 package parser
 
 import (
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -49,6 +50,11 @@ type ConfigurationParser interface {
 	ParseToUrls(content string) ([]*common.URL, error)
 }
 
+// ConfigurationSerializer is implemented by the ConfigurationParser which also serializes the values back to the content
+type ConfigurationSerializer interface {
+	Serialize(map[string]string) (string, error)
+}
+
 // DefaultConfigurationParser for supporting properties file in config center
 type DefaultConfigurationParser struct{}
 
@@ -83,12 +89,38 @@ func (parser *DefaultConfigurationParser) Parse(content string) (map[string]stri
 	return pps.Map(), nil
 }
 
+// Serialize writes the values as properties, sorted by key
+func (parser *DefaultConfigurationParser) Serialize(values map[string]string) (string, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pps := properties.NewProperties()
+	pps.DisableExpansion = true
+	for _, k := range keys {
+		if _, _, err := pps.Set(k, values[k]); err != nil {
+			return "", err
+		}
+	}
+	var buf strings.Builder
+	if _, err := pps.Write(&buf, properties.UTF8); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 // ParseToUrls is used to parse content to urls
 func (parser *DefaultConfigurationParser) ParseToUrls(content string) ([]*common.URL, error) {
 	config := ConfiguratorConfig{}
 	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
 		return nil, err
 	}
+	return configToUrls(config)
+}
+
+// configToUrls converts the items of @config to override urls
+func configToUrls(config ConfiguratorConfig) ([]*common.URL, error) {
 	scope := config.Scope
 	items := config.Configs
 	var allUrls []*common.URL
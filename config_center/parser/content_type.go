@@ -0,0 +1,190 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+import (
+	"github.com/knadh/koanf"
+	koanfjson "github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml"
+	koanfyaml "github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/rawbytes"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+)
+
+const (
+	// ContentTypeProperties is the content type of the properties, e.g. dubbo.properties
+	ContentTypeProperties = "properties"
+	// ContentTypeYAML is the content type of yaml
+	ContentTypeYAML = "yaml"
+	// ContentTypeJSON is the content type of json
+	ContentTypeJSON = "json"
+	// ContentTypeTOML is the content type of toml
+	ContentTypeTOML = "toml"
+)
+
+var (
+	contentTypeParsers = map[string]ConfigurationParser{
+		ContentTypeProperties: &DefaultConfigurationParser{},
+		ContentTypeYAML:       &koanfConfigurationParser{parser: koanfyaml.Parser()},
+		ContentTypeJSON:       &koanfConfigurationParser{parser: koanfjson.Parser()},
+		ContentTypeTOML:       &koanfConfigurationParser{parser: toml.Parser()},
+	}
+	contentTypeParsersLock sync.RWMutex
+)
+
+// SetConfigurationParser registers the parser of the content of @contentType
+func SetConfigurationParser(contentType string, parser ConfigurationParser) {
+	contentTypeParsersLock.Lock()
+	defer contentTypeParsersLock.Unlock()
+	contentTypeParsers[contentType] = parser
+}
+
+// GetConfigurationParser returns the parser registered for @contentType, nil if it is absent
+func GetConfigurationParser(contentType string) ConfigurationParser {
+	contentTypeParsersLock.RLock()
+	defer contentTypeParsersLock.RUnlock()
+	return contentTypeParsers[contentType]
+}
+
+// SelectParser returns the parser of the content type of @content with @key,
+// or @fallback, i.e. the parser set explicitly, if the content type is ambiguous or has no parser registered
+func SelectParser(key string, content string, fallback ConfigurationParser) ConfigurationParser {
+	if p := GetConfigurationParser(DetectContentType(key, content)); p != nil {
+		return p
+	}
+	return fallback
+}
+
+// DetectContentType returns the content type of @content by the extension of @key, e.g. dubbo.yaml,
+// or by the content itself if the key has no known extension, empty if it is ambiguous.
+// The flat "key: value" lines are ambiguous, for they are both yaml and properties.
+func DetectContentType(key string, content string) string {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".properties":
+		return ContentTypeProperties
+	case ".yaml", ".yml":
+		return ContentTypeYAML
+	case ".json":
+		return ContentTypeJSON
+	case ".toml":
+		return ContentTypeTOML
+	}
+
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "{") && json.Valid([]byte(content)) {
+		return ContentTypeJSON
+	}
+	var equals, colons, sections, nested int
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case len(trimmed) == 0 || trimmed[0] == '#' || trimmed[0] == '!':
+		case trimmed == "---" || strings.HasPrefix(trimmed, "- ") || line[0] == ' ' || line[0] == '\t':
+			nested++
+		case trimmed[0] == '[' && trimmed[len(trimmed)-1] == ']':
+			sections++
+		default:
+			equal, colon := strings.Index(trimmed, "="), strings.Index(trimmed, ":")
+			if equal >= 0 && (colon < 0 || equal < colon) {
+				equals++
+			} else if colon >= 0 {
+				colons++
+			}
+		}
+	}
+	switch {
+	case sections > 0 && colons == 0 && nested == 0:
+		return ContentTypeTOML
+	case equals > 0 && colons == 0 && sections == 0 && nested == 0:
+		return ContentTypeProperties
+	case nested > 0 && colons > 0 && equals == 0 && sections == 0:
+		return ContentTypeYAML
+	}
+	return ""
+}
+
+// koanfConfigurationParser parses the content by the koanf parser, the nested keys are flattened with "."
+type koanfConfigurationParser struct {
+	parser koanf.Parser
+}
+
+func (p *koanfConfigurationParser) load(content string) (*koanf.Koanf, error) {
+	k := koanf.New(".")
+	if err := k.Load(rawbytes.Provider([]byte(content)), p.parser); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Parse flattens the content into the values by key, a list is joined with ","
+func (p *koanfConfigurationParser) Parse(content string) (map[string]string, error) {
+	k, err := p.load(content)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for key, value := range k.All() {
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, 0, len(list))
+			for _, item := range list {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+// Serialize marshals the values by the koanf parser, the keys are kept flat
+func (p *koanfConfigurationParser) Serialize(values map[string]string) (string, error) {
+	m := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+	content, err := p.parser.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// ParseToUrls is used to parse content to urls
+func (p *koanfConfigurationParser) ParseToUrls(content string) ([]*common.URL, error) {
+	k, err := p.load(content)
+	if err != nil {
+		return nil, err
+	}
+	config := ConfiguratorConfig{}
+	if err = k.UnmarshalWithConf("", &config, koanf.UnmarshalConf{Tag: "yaml"}); err != nil {
+		return nil, err
+	}
+	return configToUrls(config)
+}
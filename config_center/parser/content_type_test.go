@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		content string
+		want    string
+	}{
+		{name: "yaml", key: "dubbo", content: "dubbo:\n  application:\n    name: BDTService\n", want: ContentTypeYAML},
+		{name: "yaml list", key: "dubbo", content: "addresses:\n- 127.0.0.1\n- 127.0.0.2\n", want: ContentTypeYAML},
+		{name: "json", key: "dubbo", content: `{"dubbo": {"application": {"name": "BDTService"}}}`, want: ContentTypeJSON},
+		{name: "properties", key: "dubbo", content: "# startup\ndubbo.application.name=BDTService\ndubbo.registry.address=zookeeper://127.0.0.1:2181\n", want: ContentTypeProperties},
+		{name: "toml", key: "dubbo", content: "[dubbo.application]\nname = \"BDTService\"\n", want: ContentTypeTOML},
+		{name: "flat colons", key: "dubbo", content: "dubbo.application.name: BDTService\n", want: ""},
+		{name: "invalid json", key: "dubbo", content: `{"dubbo": `, want: ""},
+		{name: "key extension", key: "dubbo.yml", content: "dubbo.application.name: BDTService\n", want: ContentTypeYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectContentType(tt.key, tt.content))
+		})
+	}
+}
+
+func TestSelectParser(t *testing.T) {
+	fallback := &DefaultConfigurationParser{}
+	contents := map[string]string{
+		ContentTypeYAML:       "dubbo:\n  application:\n    name: BDTService\n  registries:\n    zk:\n      address: 127.0.0.1:2181\n",
+		ContentTypeJSON:       `{"dubbo": {"application": {"name": "BDTService"}, "registries": {"zk": {"address": "127.0.0.1:2181"}}}}`,
+		ContentTypeProperties: "dubbo.application.name=BDTService\ndubbo.registries.zk.address=127.0.0.1:2181\n",
+	}
+	for contentType, content := range contents {
+		p := SelectParser("dubbo", content, fallback)
+		assert.Same(t, GetConfigurationParser(contentType), p, contentType)
+		values, err := p.Parse(content)
+		assert.NoError(t, err, contentType)
+		assert.Equal(t, map[string]string{
+			"dubbo.application.name":      "BDTService",
+			"dubbo.registries.zk.address": "127.0.0.1:2181",
+		}, values, contentType)
+	}
+
+	// ambiguous
+	assert.Same(t, fallback, SelectParser("dubbo", "dubbo.application.name: BDTService", fallback))
+	assert.Nil(t, SelectParser("dubbo", "dubbo.application.name: BDTService", nil))
+}
+
+func TestKoanfConfigurationParserParseToUrls(t *testing.T) {
+	content := `{"configVersion": "2.7.1", "scope": "application", "key": "org.apache.dubbo-go.mockService", "enabled": true,
+"configs": [{"type": "application", "enabled": true, "addresses": ["0.0.0.0"], "services": ["org.apache.dubbo-go.mockService"],
+"parameters": {"cluster": "mock1"}, "side": "provider"}]}`
+	urls, err := GetConfigurationParser(ContentTypeJSON).ParseToUrls(content)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(urls))
+	assert.Equal(t, "mock1", urls[0].GetParam("cluster", ""))
+	assert.Equal(t, "override", urls[0].Protocol)
+	assert.Equal(t, "0.0.0.0", urls[0].Location)
+}
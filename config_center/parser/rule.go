@@ -0,0 +1,171 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+
+	"gopkg.in/yaml.v2"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+const (
+	// RuleTypeConditionRouter is the type of the condition router rule, e.g. $(application).condition-router
+	RuleTypeConditionRouter = "condition-router"
+	// RuleTypeTagRouter is the type of the tag router rule, e.g. $(application).tag-router
+	RuleTypeTagRouter = "tag-router"
+	// RuleTypeConfigurators is the type of the override rule, e.g. $(service).configurators
+	RuleTypeConfigurators = "configurators"
+)
+
+// matches a single "key = value" or "key != value" clause of a condition
+var conditionClausePattern = regexp.MustCompile(`^[\w.\-]+\s*!?=\s*\S+$`)
+
+// ConditionRouterRule is the governance rule of the condition router
+type ConditionRouterRule struct {
+	Scope      string   `yaml:"scope"`
+	Key        string   `yaml:"key"`
+	Enabled    bool     `yaml:"enabled"`
+	Force      bool     `yaml:"force"`
+	Runtime    bool     `yaml:"runtime"`
+	Priority   int      `yaml:"priority"`
+	Conditions []string `yaml:"conditions"`
+}
+
+// TagRouterRule is the governance rule of the tag router
+type TagRouterRule struct {
+	Key     string `yaml:"key"`
+	Enabled bool   `yaml:"enabled"`
+	Force   bool   `yaml:"force"`
+	Tags    []struct {
+		Name      string   `yaml:"name"`
+		Addresses []string `yaml:"addresses"`
+	} `yaml:"tags"`
+}
+
+// RuleTypeOfKey returns the rule type by the suffix of @key, empty if it is not a governance rule
+func RuleTypeOfKey(key string) string {
+	switch {
+	case strings.HasSuffix(key, constant.ConditionRouterRuleSuffix):
+		return RuleTypeConditionRouter
+	case strings.HasSuffix(key, constant.TagRouterRuleSuffix):
+		return RuleTypeTagRouter
+	case strings.HasSuffix(key, constant.ConfiguratorSuffix):
+		return RuleTypeConfigurators
+	}
+	return ""
+}
+
+// ValidateRule parses the governance rule @content of @ruleType and checks its required fields,
+// so that a malformed rule is rejected before it is published and breaks the consumers reading it
+func ValidateRule(content string, ruleType string) error {
+	switch ruleType {
+	case RuleTypeConditionRouter:
+		return validateConditionRouterRule(content)
+	case RuleTypeTagRouter:
+		return validateTagRouterRule(content)
+	case RuleTypeConfigurators:
+		return validateConfiguratorsRule(content)
+	}
+	return perrors.Errorf("unknown rule type %s", ruleType)
+}
+
+func validateConditionRouterRule(content string) error {
+	rule := ConditionRouterRule{}
+	if err := yaml.Unmarshal([]byte(content), &rule); err != nil {
+		return perrors.WithMessage(err, "invalid condition router rule")
+	}
+	if len(rule.Key) == 0 {
+		return perrors.New("invalid condition router rule: the key is required")
+	}
+	if rule.Scope != ScopeApplication && rule.Scope != "service" {
+		return perrors.Errorf("invalid condition router rule: unknown scope %q", rule.Scope)
+	}
+	if len(rule.Conditions) == 0 {
+		return perrors.New("invalid condition router rule: no conditions")
+	}
+	for _, condition := range rule.Conditions {
+		if err := validateCondition(condition); err != nil {
+			return perrors.WithMessagef(err, "invalid condition router rule: condition %q", condition)
+		}
+	}
+	return nil
+}
+
+// validateCondition checks the "when => then" @condition, either side of which is the clauses joined with "&"
+func validateCondition(condition string) error {
+	parts := strings.Split(condition, "=>")
+	if len(parts) > 2 {
+		return perrors.New("more than one =>")
+	}
+	if len(strings.TrimSpace(parts[len(parts)-1])) == 0 && (len(parts) == 1 || len(strings.TrimSpace(parts[0])) == 0) {
+		return perrors.New("empty condition")
+	}
+	for _, part := range parts {
+		if len(strings.TrimSpace(part)) == 0 {
+			continue
+		}
+		for _, clause := range strings.Split(part, "&") {
+			if !conditionClausePattern.MatchString(strings.TrimSpace(clause)) {
+				return perrors.Errorf("malformed clause %q", strings.TrimSpace(clause))
+			}
+		}
+	}
+	return nil
+}
+
+func validateTagRouterRule(content string) error {
+	rule := TagRouterRule{}
+	if err := yaml.Unmarshal([]byte(content), &rule); err != nil {
+		return perrors.WithMessage(err, "invalid tag router rule")
+	}
+	if len(rule.Key) == 0 {
+		return perrors.New("invalid tag router rule: the key is required")
+	}
+	for i, tag := range rule.Tags {
+		if len(tag.Name) == 0 {
+			return perrors.Errorf("invalid tag router rule: the name of tag %d is required", i)
+		}
+	}
+	return nil
+}
+
+func validateConfiguratorsRule(content string) error {
+	config := ConfiguratorConfig{}
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return perrors.WithMessage(err, "invalid configurators rule")
+	}
+	if len(config.Key) == 0 {
+		return perrors.New("invalid configurators rule: the key is required")
+	}
+	if len(config.Configs) == 0 {
+		return perrors.New("invalid configurators rule: no configs")
+	}
+	if _, err := configToUrls(config); err != nil {
+		return perrors.WithMessage(err, "invalid configurators rule")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConditionRouterRule(t *testing.T) {
+	valid := `scope: application
+key: demo-app
+enabled: true
+force: false
+conditions:
+- method = find* => host = 192.168.1.1,192.168.1.2
+- host != 10.20.153.10 & application = demo-consumer =>
+- => host = 192.168.1.3`
+	assert.NoError(t, ValidateRule(valid, RuleTypeConditionRouter))
+
+	invalid := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{name: "malformed yaml", content: "conditions: [", message: "invalid condition router rule"},
+		{name: "no key", content: "scope: application\nconditions:\n- host = 1.1.1.1", message: "the key is required"},
+		{name: "unknown scope", content: "scope: app\nkey: demo-app\nconditions:\n- host = 1.1.1.1", message: `unknown scope "app"`},
+		{name: "no conditions", content: "scope: service\nkey: org.apache.DemoService", message: "no conditions"},
+		{name: "empty condition", content: "scope: application\nkey: demo-app\nconditions:\n- ' => '", message: "empty condition"},
+		{name: "malformed clause", content: "scope: application\nkey: demo-app\nconditions:\n- method find => host = 1.1.1.1", message: `malformed clause "method find"`},
+		{name: "two arrows", content: "scope: application\nkey: demo-app\nconditions:\n- host = 1 => host = 2 => host = 3", message: "more than one =>"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRule(tt.content, RuleTypeConditionRouter)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	assert.NoError(t, ValidateRule("key: demo-app\ntags:\n- name: gray\n  addresses: [127.0.0.1:20000]", RuleTypeTagRouter))
+	assert.Error(t, ValidateRule("key: demo-app\ntags:\n- addresses: [127.0.0.1:20000]", RuleTypeTagRouter))
+	assert.NoError(t, ValidateRule("scope: service\nkey: org.apache.DemoService\nconfigs:\n- parameters:\n    timeout: 6000",
+		RuleTypeConfigurators))
+	assert.Error(t, ValidateRule("scope: service\nkey: org.apache.DemoService", RuleTypeConfigurators))
+	assert.Error(t, ValidateRule("key: demo-app", "script-router"))
+
+	assert.Equal(t, RuleTypeConditionRouter, RuleTypeOfKey("demo-app.condition-router"))
+	assert.Equal(t, RuleTypeConfigurators, RuleTypeOfKey("org.apache.DemoService.configurators"))
+	assert.Equal(t, "", RuleTypeOfKey("dubbo.properties"))
+}
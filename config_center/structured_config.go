@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+)
+
+// GroupParsers is implemented by the backends able to parse the configs of different groups in different formats
+type GroupParsers interface {
+	SetGroupParser(group string, p parser.ConfigurationParser)
+	GroupParser(group string) parser.ConfigurationParser
+}
+
+// ParserOf returns the parser of the configs in @group of @c, which is the one registered for the group,
+// or the parser of @c if there is none, or the properties parser if neither is set
+func ParserOf(c DynamicConfiguration, group string) parser.ConfigurationParser {
+	if groupParsers, ok := c.(GroupParsers); ok {
+		if p := groupParsers.GroupParser(group); p != nil {
+			return p
+		}
+	}
+	if p := c.Parser(); p != nil {
+		return p
+	}
+	return &parser.DefaultConfigurationParser{}
+}
+
+// GetMap reads @key and parses it by the parser of its group in @c into a map, the empty content is an empty map
+func GetMap(c DynamicConfiguration, key string, opts ...Option) (map[string]string, error) {
+	content, err := c.GetProperties(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(content)) == 0 {
+		return map[string]string{}, nil
+	}
+	tmpOpts := &Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	values, err := ParserOf(c, tmpOpts.Group).Parse(content)
+	if err != nil {
+		return nil, perrors.WithMessagef(err, "parse the map of key %s", key)
+	}
+	return values, nil
+}
+
+// PublishMap serializes @values by the parser of @group in @c, which must be a parser.ConfigurationSerializer,
+// and publishes them with @key and @group
+func PublishMap(c DynamicConfiguration, key string, group string, values map[string]string, opts ...Option) error {
+	p := ParserOf(c, group)
+	serializer, ok := p.(parser.ConfigurationSerializer)
+	if !ok {
+		return perrors.Errorf("the parser %T can not serialize the map of key %s", p, key)
+	}
+	content, err := serializer.Serialize(values)
+	if err != nil {
+		return perrors.WithMessagef(err, "serialize the map of key %s", key)
+	}
+	return c.PublishConfig(key, group, content, opts...)
+}
+
+// GetList reads @key as a list of one item per line, the items are trimmed and the blank lines are skipped
+func GetList(c DynamicConfiguration, key string, opts ...Option) ([]string, error) {
+	content, err := c.GetProperties(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]string, 0)
+	for _, line := range strings.Split(content, "\n") {
+		if item := strings.TrimSpace(line); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// PublishList publishes @items with @key and @group one item per line,
+// the items must be neither blank nor multiline, which could not be read back
+func PublishList(c DynamicConfiguration, key string, group string, items []string, opts ...Option) error {
+	for i, item := range items {
+		if len(strings.TrimSpace(item)) == 0 || strings.ContainsAny(item, "\r\n") {
+			return perrors.Errorf("item %d %q of the list of key %s is blank or multiline", i, item, key)
+		}
+	}
+	return c.PublishConfig(key, group, strings.Join(items, "\n"), opts...)
+}
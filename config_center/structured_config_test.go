@@ -0,0 +1,111 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config_center
+
+import (
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
+)
+
+// publishDynamicConfiguration publishes the configs in memory
+type publishDynamicConfiguration struct {
+	groupDynamicConfiguration
+}
+
+func (c *publishDynamicConfiguration) PublishConfig(key string, group string, value string, _ ...Option) error {
+	if c.groups[group] == nil {
+		c.groups[group] = make(map[string]string)
+	}
+	c.groups[group][key] = value
+	return nil
+}
+
+func TestMap(t *testing.T) {
+	c := &publishDynamicConfiguration{groupDynamicConfiguration{groups: map[string]map[string]string{}}}
+	values := map[string]string{"weight.127.0.0.1": "100", "weight.127.0.0.2": "50", "note": "a=b: c\nd"}
+	assert.NoError(t, PublishMap(c, "weights", "dubbo", values))
+	read, err := GetMap(c, "weights", WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, values, read)
+
+	// serialized by the configured parser
+	c.SetParser(parser.GetConfigurationParser(parser.ContentTypeYAML))
+	assert.NoError(t, PublishMap(c, "weights", "dubbo", values))
+	assert.Contains(t, c.groups["dubbo"]["weights"], "weight.127.0.0.1: \"100\"")
+	read, err = GetMap(c, "weights", WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, values, read)
+
+	// empty and malformed
+	c.groups["dubbo"]["empty"] = " \n"
+	read, err = GetMap(c, "empty", WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Empty(t, read)
+	c.groups["dubbo"]["malformed"] = "weights: [100"
+	_, err = GetMap(c, "malformed", WithGroup("dubbo"))
+	assert.Error(t, err)
+	_, err = GetMap(c, "absent", WithGroup("dubbo"))
+	assert.Error(t, err)
+}
+
+func TestGroupParser(t *testing.T) {
+	c := &struct {
+		BaseDynamicConfiguration
+		*groupDynamicConfiguration
+	}{groupDynamicConfiguration: &groupDynamicConfiguration{groups: map[string]map[string]string{
+		"rules":      {"weights": "weight:\n  127.0.0.1: 100\n  127.0.0.2: 50"},
+		"properties": {"weights": "weight.127.0.0.1=100\nweight.127.0.0.2=50"},
+	}}}
+	c.SetParser(&parser.DefaultConfigurationParser{})
+	c.SetGroupParser("rules", parser.GetConfigurationParser(parser.ContentTypeYAML))
+	expected := map[string]string{"weight.127.0.0.1": "100", "weight.127.0.0.2": "50"}
+
+	// each group is parsed by its own parser
+	read, err := GetMap(c, "weights", WithGroup("rules"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, read)
+	// the group without a parser falls back to the parser of the configuration
+	read, err = GetMap(c, "weights", WithGroup("properties"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, read)
+	assert.IsType(t, &parser.DefaultConfigurationParser{}, ParserOf(c, "properties"))
+}
+
+func TestList(t *testing.T) {
+	c := &publishDynamicConfiguration{groupDynamicConfiguration{groups: map[string]map[string]string{}}}
+	items := []string{"127.0.0.1:20000", "127.0.0.2:20000"}
+	assert.NoError(t, PublishList(c, "blacklist", "dubbo", items))
+	read, err := GetList(c, "blacklist", WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, items, read)
+
+	assert.NoError(t, PublishList(c, "blacklist", "dubbo", nil))
+	read, err = GetList(c, "blacklist", WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Empty(t, read)
+
+	assert.Error(t, PublishList(c, "blacklist", "dubbo", []string{"127.0.0.1:20000", " "}))
+	assert.Error(t, PublishList(c, "blacklist", "dubbo", []string{"127.0.0.1:20000\n127.0.0.2:20000"}))
+}
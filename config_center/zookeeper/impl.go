@@ -18,13 +18,19 @@
 package zookeeper
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 import (
+	"github.com/dubbogo/go-zookeeper/zk"
+
 	gxset "github.com/dubbogo/gost/container/set"
 	gxzookeeper "github.com/dubbogo/gost/database/kv/zk"
 
@@ -34,17 +40,26 @@ import (
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	"dubbo.apache.org/dubbo-go/v3/config"
 	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
 	"dubbo.apache.org/dubbo-go/v3/config_center/parser"
 	"dubbo.apache.org/dubbo-go/v3/remoting/zookeeper"
 )
 
 const (
 	pathSeparator = "/"
+	// the protocol of the metrics of the config center
+	zkProtocol = "zookeeper"
+	// the interval to check whether the session is lost
+	sessionCheckInterval = time.Second
 )
 
+// the interval to retry connecting once the config center is unavailable at the lenient startup
+var connectRetryInterval = 3 * time.Second
+
 type zookeeperDynamicConfiguration struct {
 	config_center.BaseDynamicConfiguration
 	url      *common.URL
@@ -52,22 +67,86 @@ type zookeeperDynamicConfiguration struct {
 	wg       sync.WaitGroup
 	cltLock  sync.Mutex
 	done     chan struct{}
+	doneOnce sync.Once
 	client   *gxzookeeper.ZookeeperClient
 
+	// how long to keep reconnecting to the lost backend before giving up, 0 means forever
+	maxReconnectDuration time.Duration
+	// when the session was found lost, zero if it is valid, guarded by cltLock
+	lostSince time.Time
+
+	// the reads prefer the read replica if any, which fail over to the client of the primary
+	replica zkReader
+	// the writes go to the client of the primary if it is nil
+	publisher zkWriter
+	// skipIdentical skips publishing the value identical to the current one, which would bump the version
+	skipIdentical bool
+
 	// listenerLock  sync.Mutex
 	listener      *zookeeper.ZkEventListener
 	cacheListener *CacheListener
 	parser        parser.ConfigurationParser
 
 	base64Enabled bool
+	// base64Auto decodes the values looking like base64 and keeps the others raw, see decodeBase64Auto
+	base64Auto bool
+}
+
+const base64AutoMode = "auto"
+
+// zkReplica is the client of the read replica
+type zkReplica struct {
+	*gxzookeeper.ZookeeperClient
+}
+
+// Exists checks whether @path exists in the read replica
+func (r zkReplica) Exists(path string) (bool, error) {
+	return zkExists(r.ZookeeperClient, path)
+}
+
+// zkExists checks whether @path exists by its stat, the data of the znode is not read
+func zkExists(client *gxzookeeper.ZookeeperClient, path string) (bool, error) {
+	if client == nil || client.Conn == nil {
+		return false, perrors.WithStack(gxzookeeper.ErrNilZkClientConn)
+	}
+	exists, _, err := client.Conn.Exists(path)
+	return exists, err
+}
+
+// zkReader reads the znodes, it is implemented by zkReplica
+type zkReader interface {
+	GetContent(string) ([]byte, *zk.Stat, error)
+	GetChildren(string) ([]string, error)
+	Exists(string) (bool, error)
+	ZkConnValid() bool
+	Close()
+}
+
+// zkWriter publishes the znodes, it is implemented by *gxzookeeper.ZookeeperClient
+type zkWriter interface {
+	GetContent(string) ([]byte, *zk.Stat, error)
+	CreateWithValue(string, []byte) error
+	CreateTempWithValue(string, []byte) error
 }
 
 func newZookeeperDynamicConfiguration(url *common.URL) (*zookeeperDynamicConfiguration, error) {
+	if err := extension.ResolveSensitiveParams(url); err != nil {
+		return nil, perrors.WithMessage(err, "resolve zookeeper config center params")
+	}
+	if err := config_center.CheckAddress(url); err != nil {
+		return nil, err
+	}
 	c := &zookeeperDynamicConfiguration{
 		url:      url,
 		rootPath: "/" + url.GetParam(constant.CONFIG_NAMESPACE_KEY, config_center.DEFAULT_GROUP) + "/config",
+		done:     make(chan struct{}),
+		// off by default for compatibility
+		skipIdentical:        url.GetParamBool(constant.CONFIG_SKIP_IDENTICAL_KEY, false),
+		maxReconnectDuration: url.GetParamDuration(constant.CONFIG_MAX_RECONNECT_DURATION_KEY, "0s"),
 	}
-	if v, ok := config.GetRootConfig().ConfigCenter.Params["base64"]; ok {
+	if v, ok := config.GetRootConfig().ConfigCenter.Params["base64"]; ok && v == base64AutoMode {
+		c.base64Auto = true
+	} else if ok {
 		base64Enabled, err := strconv.ParseBool(v)
 		if err != nil {
 			panic("value of base64 must be bool, error=" + err.Error())
@@ -75,24 +154,92 @@ func newZookeeperDynamicConfiguration(url *common.URL) (*zookeeperDynamicConfigu
 		c.base64Enabled = base64Enabled
 	}
 
-	err := zookeeper.ValidateZookeeperClient(c, url.Location)
-	if err != nil {
-		logger.Errorf("zookeeper client start error ,error message is %v", err)
+	c.cacheListener = NewCacheListener(c.rootPath)
+	c.cacheListener.SetMaxListeners(int(url.GetParamInt(constant.CONFIG_MAX_LISTENERS_KEY, 0)))
+	c.cacheListener.SetDispatchConcurrency(int(url.GetParamInt(constant.CONFIG_DISPATCH_CONCURRENCY_KEY, 0)))
+
+	err := c.connect()
+	if err == nil {
+		return c, nil
+	}
+	if !url.GetParamBool(constant.CONFIG_LENIENT_STARTUP_KEY, false) {
 		return nil, err
 	}
+	// starts unavailable, and keeps connecting in the background
+	logger.Warnf("zookeeper config center %s is unavailable at startup, keep connecting in the background, error message is %v",
+		url.Location, err)
+	c.NotifyAvailability(false)
 	c.wg.Add(1)
+	go c.connectInBackground()
+	return c, nil
+}
+
+// connect starts the clients and listens to the changes under the root path
+func (c *zookeeperDynamicConfiguration) connect() error {
+	err := zookeeper.ValidateZookeeperClient(c, c.url.Location)
+	if err != nil {
+		logger.Errorf("zookeeper client start error ,error message is %v", err)
+		return err
+	}
+	if replica := c.url.GetParam(constant.CONFIG_READ_REPLICA_KEY, ""); len(replica) > 0 {
+		timeout := c.url.GetParamDuration(constant.CONFIG_TIMEOUT_KEY, constant.DEFAULT_REG_TIMEOUT)
+		replicaClient, err := gxzookeeper.NewZookeeperClient("zk config center read replica", strings.Split(replica, ","),
+			false, gxzookeeper.WithZkTimeOut(timeout))
+		if err != nil {
+			// the reads go to the primary
+			logger.Warnf("zookeeper read replica %s start error, error message is %v", replica, err)
+		} else {
+			c.replica = zkReplica{ZookeeperClient: replicaClient}
+		}
+	}
+	c.wg.Add(2)
 	go zookeeper.HandleClientRestart(c)
+	go c.watchSession()
 
-	c.listener = zookeeper.NewZkEventListener(c.client)
-	c.cacheListener = NewCacheListener(c.rootPath)
+	client := c.getClient()
+	c.listener = zookeeper.NewZkEventListener(client)
+	err = client.Create(c.rootPath)
+	c.listener.ListenServiceEvent(c.url, c.rootPath, c.cacheListener)
+	return err
+}
 
-	err = c.client.Create(c.rootPath)
-	c.listener.ListenServiceEvent(url, c.rootPath, c.cacheListener)
-	return c, err
+// connectInBackground retries connecting until it succeeds, which is notified to the availability listeners,
+// the config center is destroyed, or it gives up after maxReconnectDuration
+func (c *zookeeperDynamicConfiguration) connectInBackground() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(connectRetryInterval)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		if c.maxReconnectDuration > 0 && time.Since(start) >= c.maxReconnectDuration {
+			c.giveUp()
+			return
+		}
+		var err error
+		if c.listener == nil {
+			err = c.connect()
+		} else {
+			// the client is started, but the root path is not created
+			err = c.getClient().Create(c.rootPath)
+		}
+		if err == nil {
+			logger.Infof("zookeeper config center %s is connected", c.url.Location)
+			c.NotifyAvailability(true)
+			return
+		}
+		logger.Debugf("connect zookeeper config center %s error %v, retry in %v", c.url.Location, err, connectRetryInterval)
+	}
 }
 
 func (c *zookeeperDynamicConfiguration) AddListener(key string, listener config_center.ConfigurationListener, opions ...config_center.Option) {
-	c.cacheListener.AddListener(key, listener)
+	if err := c.cacheListener.AddListener(key, listener, opions...); err != nil {
+		logger.Errorf("add listener of key %s error: %v", key, err)
+	}
 }
 
 func (c *zookeeperDynamicConfiguration) RemoveListener(key string, listener config_center.ConfigurationListener, opions ...config_center.Option) {
@@ -100,16 +247,88 @@ func (c *zookeeperDynamicConfiguration) RemoveListener(key string, listener conf
 }
 
 func (c *zookeeperDynamicConfiguration) GetProperties(key string, opts ...config_center.Option) (string, error) {
+	return c.GetPropertiesCtx(context.Background(), key, opts...)
+}
+
+// GetPropertiesCtx is the same as GetProperties, but gives up waiting for the read once @ctx is done
+func (c *zookeeperDynamicConfiguration) GetPropertiesCtx(ctx context.Context, key string, opts ...config_center.Option) (string, error) {
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	value, err := c.ReadWithMaxAge(key, tmpOpts.Group, tmpOpts.MaxAge, func() (string, error) {
+		return config_center.ReadWithContext(ctx, func() (string, error) {
+			value, _, err := c.GetPropertiesWithStat(key, opts...)
+			return value, err
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return config_center.ApplyReadOptions(value, opts...)
+}
+
+// GetPropertiesWithStat returns the value together with the version of its znode,
+// both of them come from a single read so that they are consistent with each other
+func (c *zookeeperDynamicConfiguration) GetPropertiesWithStat(key string, opts ...config_center.Option) (_ string, _ int32, err error) {
+	defer func(start time.Time) {
+		metrics.RecordOperation(zkProtocol, metrics.OperationRead, start, err)
+	}(time.Now())
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	content, stat, err := c.getContent(c.propertiesPath(key, tmpOpts.Group))
+	if perrors.Cause(err) == zk.ErrNoNode {
+		return "", 0, config_center.NotFound(perrors.WithStack(err))
+	}
+	if err != nil {
+		return "", 0, perrors.WithStack(err)
+	}
+	if c.base64Auto {
+		return decodeBase64Auto(string(content)), stat.Version, nil
+	}
+	if !c.base64Enabled {
+		return string(content), stat.Version, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(content))
+	if err != nil {
+		return "", 0, perrors.WithStack(err)
+	}
+	return string(decoded), stat.Version, nil
+}
+
+// Exists checks whether @key exists by the existence of its znode, the data of which is not read.
+// A missing key is false without error, while the error means the existence is unknown.
+func (c *zookeeperDynamicConfiguration) Exists(key string, opts ...config_center.Option) (bool, error) {
 	tmpOpts := &config_center.Options{}
 	for _, opt := range opts {
 		opt(tmpOpts)
 	}
+	path := c.propertiesPath(key, tmpOpts.Group)
+	if c.replica != nil && c.replica.ZkConnValid() {
+		exists, err := c.replica.Exists(path)
+		if err == nil {
+			return exists, nil
+		}
+		logger.Debugf("check %s in the zookeeper read replica error %v, fall back to the primary", path, err)
+	}
+	exists, err := zkExists(c.getClient(), path)
+	if err != nil {
+		return false, perrors.WithMessagef(err, "check the existence of %s", path)
+	}
+	return exists, nil
+}
+
+// propertiesPath is the path of the znode of @key in @group
+func (c *zookeeperDynamicConfiguration) propertiesPath(key string, group string) string {
 	/**
 	 * when group is not null, we are getting startup configs from Config Center, for example:
 	 * group=dubbo, key=dubbo.properties
 	 */
-	if len(tmpOpts.Group) != 0 {
-		key = tmpOpts.Group + "/" + key
+	if len(group) != 0 {
+		key = group + "/" + key
 	} else {
 		/**
 		 * when group is null, we are fetching governance rules, for example:
@@ -119,19 +338,74 @@ func (c *zookeeperDynamicConfiguration) GetProperties(key string, opts ...config
 		i := strings.LastIndex(key, ".")
 		key = key[0:i] + "/" + key[i+1:]
 	}
-	content, _, err := c.client.GetContent(c.rootPath + "/" + key)
-	if err != nil {
-		return "", perrors.WithStack(err)
+	return c.rootPath + "/" + key
+}
+
+// getContent reads @path from the read replica if it is connected, and from the primary once the replica fails.
+// The missing node answered by the replica is not read again from the primary, which would double the reads of
+// the missing configs, e.g. the rules of most applications.
+func (c *zookeeperDynamicConfiguration) getContent(path string) ([]byte, *zk.Stat, error) {
+	if c.replica != nil && c.replica.ZkConnValid() {
+		content, stat, err := c.replica.GetContent(path)
+		if err == nil || err == zk.ErrNoNode {
+			return content, stat, err
+		}
+		logger.Debugf("read %s from the zookeeper read replica error %v, fall back to the primary", path, err)
 	}
-	if !c.base64Enabled {
-		return string(content), nil
+	client := c.getClient()
+	if client == nil {
+		// not connected yet at the lenient startup
+		return nil, nil, perrors.WithStack(gxzookeeper.ErrNilZkClientConn)
+	}
+	return client.GetContent(path)
+}
+
+// getChildren lists the children of @path like getContent
+func (c *zookeeperDynamicConfiguration) getChildren(path string) ([]string, error) {
+	if c.replica != nil && c.replica.ZkConnValid() {
+		children, err := c.replica.GetChildren(path)
+		if err == nil {
+			return children, nil
+		}
+		logger.Debugf("list %s from the zookeeper read replica error %v, fall back to the primary", path, err)
 	}
+	client := c.getClient()
+	if client == nil {
+		return nil, perrors.WithStack(gxzookeeper.ErrNilZkClientConn)
+	}
+	return client.GetChildren(path)
+}
 
-	decoded, err := base64.StdEncoding.DecodeString(string(content))
-	if err != nil {
-		return "", perrors.WithStack(err)
+// decodeBase64Auto returns the decoded @content if it is base64 of utf-8 text, i.e. it decodes and
+// encodes back to itself, otherwise @content is returned as it is.
+// It is a heuristic: a plain value which happens to be such base64, e.g. "YWJj", is decoded as well,
+// so the values should be published in a single way whenever possible.
+func decodeBase64Auto(content string) string {
+	if len(content) == 0 {
+		return content
 	}
-	return string(decoded), nil
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil || !utf8.Valid(decoded) || base64.StdEncoding.EncodeToString(decoded) != content {
+		return content
+	}
+	return string(decoded)
+}
+
+// GetPropertiesAcrossGroups reads @key in each of @groups and returns the values by group, the groups missing
+// the key are omitted, while any other error fails the whole read
+func (c *zookeeperDynamicConfiguration) GetPropertiesAcrossGroups(key string, groups []string) (map[string]string, error) {
+	values := make(map[string]string, len(groups))
+	for _, group := range groups {
+		value, err := c.GetProperties(key, config_center.WithGroup(group))
+		if err != nil {
+			if perrors.Cause(err) == zk.ErrNoNode {
+				continue
+			}
+			return nil, perrors.WithMessagef(err, "get key %s in group %s", key, group)
+		}
+		values[group] = value
+	}
+	return values, nil
 }
 
 // GetInternalProperty For zookeeper, getConfig and getConfigs have the same meaning.
@@ -139,24 +413,71 @@ func (c *zookeeperDynamicConfiguration) GetInternalProperty(key string, opts ...
 	return c.GetProperties(key, opts...)
 }
 
-// PublishConfig will put the value into Zk with specific path
-func (c *zookeeperDynamicConfiguration) PublishConfig(key string, group string, value string) error {
+// PublishConfig will put the value into Zk with specific path,
+// the node is ephemeral and bound to the session when WithEphemeral(true) is given
+func (c *zookeeperDynamicConfiguration) PublishConfig(key string, group string, value string, opts ...config_center.Option) (err error) {
+	defer func(start time.Time) {
+		metrics.RecordOperation(zkProtocol, metrics.OperationWrite, start, err)
+	}(time.Now())
+	if err := config_center.CheckPublishedRule(key, value, opts...); err != nil {
+		return err
+	}
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
 	path := c.getPath(key, group)
 	valueBytes := []byte(value)
 	if c.base64Enabled {
 		valueBytes = []byte(base64.StdEncoding.EncodeToString(valueBytes))
 	}
-	err := c.client.CreateWithValue(path, valueBytes)
+	writer, err := c.writer()
+	if err != nil {
+		return err
+	}
+	if c.skipIdentical && !tmpOpts.Ephemeral {
+		// read from the primary, the replica may lag behind
+		if current, _, err := writer.GetContent(path); err == nil && bytes.Equal(current, valueBytes) {
+			logger.Debugf("skip publishing the identical value of %s", path)
+			return nil
+		}
+	}
+	if tmpOpts.Ephemeral {
+		err = writer.CreateTempWithValue(path, valueBytes)
+	} else {
+		err = writer.CreateWithValue(path, valueBytes)
+	}
 	if err != nil {
 		return perrors.WithStack(err)
 	}
 	return nil
 }
 
+// writer returns where the configs are published to, which fails if the client is not connected yet at the
+// lenient startup
+func (c *zookeeperDynamicConfiguration) writer() (zkWriter, error) {
+	if c.publisher != nil {
+		return c.publisher, nil
+	}
+	client := c.getClient()
+	if client == nil {
+		return nil, perrors.WithMessagef(gxzookeeper.ErrNilZkClientConn, "zookeeper config center %s is not connected",
+			c.url.Location)
+	}
+	return client, nil
+}
+
+// getClient returns the client of the primary under cltLock, nil until it is connected at the lenient startup
+func (c *zookeeperDynamicConfiguration) getClient() *gxzookeeper.ZookeeperClient {
+	c.cltLock.Lock()
+	defer c.cltLock.Unlock()
+	return c.client
+}
+
 // GetConfigKeysByGroup will return all keys with the group
 func (c *zookeeperDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxset.HashSet, error) {
 	path := c.getPath("", group)
-	result, err := c.client.GetChildren(path)
+	result, err := c.getChildren(path)
 	if err != nil {
 		return nil, perrors.WithStack(err)
 	}
@@ -171,10 +492,37 @@ func (c *zookeeperDynamicConfiguration) GetConfigKeysByGroup(group string) (*gxs
 	return set, nil
 }
 
+// IterateConfigKeys calls @fn with each key of @group until it returns false. Zookeeper lists the children
+// at once, but they are not copied into a set as GetConfigKeysByGroup does.
+func (c *zookeeperDynamicConfiguration) IterateConfigKeys(group string, fn func(key string) bool) error {
+	path := c.getPath("", group)
+	children, err := c.getChildren(path)
+	if err != nil {
+		return perrors.WithStack(err)
+	}
+	for _, key := range children {
+		if !fn(key) {
+			return nil
+		}
+	}
+	return nil
+}
+
 func (c *zookeeperDynamicConfiguration) GetRule(key string, opts ...config_center.Option) (string, error) {
 	return c.GetProperties(key, opts...)
 }
 
+// BackendInfo describes zookeeper, which supports both publishing and listing the keys of a group
+func (c *zookeeperDynamicConfiguration) BackendInfo() config_center.BackendInfo {
+	return config_center.BackendInfo{
+		Protocol:            c.url.Protocol,
+		Endpoints:           strings.Split(c.url.Location, ","),
+		Namespace:           c.rootPath,
+		SupportsPublish:     true,
+		SupportsKeysByGroup: true,
+	}
+}
+
 func (c *zookeeperDynamicConfiguration) Parser() parser.ConfigurationParser {
 	return c.parser
 }
@@ -183,10 +531,12 @@ func (c *zookeeperDynamicConfiguration) SetParser(p parser.ConfigurationParser)
 	c.parser = p
 }
 
+// ZkClient returns the client, the caller holds ZkClientLock to read it across goroutines
 func (c *zookeeperDynamicConfiguration) ZkClient() *gxzookeeper.ZookeeperClient {
 	return c.client
 }
 
+// SetZkClient sets the client, the caller holds ZkClientLock as ValidateZookeeperClient does
 func (c *zookeeperDynamicConfiguration) SetZkClient(client *gxzookeeper.ZookeeperClient) {
 	c.client = client
 }
@@ -211,11 +561,32 @@ func (c *zookeeperDynamicConfiguration) Destroy() {
 	if c.listener != nil {
 		c.listener.Close()
 	}
-	close(c.done)
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
 	c.wg.Wait()
 	c.closeConfigs()
 }
 
+// giveUp stops reconnecting to the lost backend, the config center stays unavailable until it is created again
+func (c *zookeeperDynamicConfiguration) giveUp() {
+	if !c.IsAvailable() {
+		// destroyed or given up already
+		return
+	}
+	logger.Errorf("give up reconnecting to zookeeper config center %s after %v, it stays unavailable",
+		c.url.Location, c.maxReconnectDuration)
+	c.NotifyAvailability(false)
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+	// the clients keep reconnecting until they are closed, once the goroutines using them quit
+	go func() {
+		c.wg.Wait()
+		c.closeConfigs()
+	}()
+}
+
 func (c *zookeeperDynamicConfiguration) IsAvailable() bool {
 	select {
 	case <-c.done:
@@ -229,14 +600,57 @@ func (c *zookeeperDynamicConfiguration) closeConfigs() {
 	logger.Infof("begin to close provider zk client")
 	c.cltLock.Lock()
 	defer c.cltLock.Unlock()
-	c.client.Close()
-	c.client = nil
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+	if c.replica != nil {
+		c.replica.Close()
+		c.replica = nil
+	}
 }
 
 func (c *zookeeperDynamicConfiguration) RestartCallBack() bool {
+	c.NotifyAvailability(true)
 	return true
 }
 
+// watchSession notifies the availability listeners once the session is lost, the restore is notified by RestartCallBack
+func (c *zookeeperDynamicConfiguration) watchSession() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(sessionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			c.NotifyAvailability(false)
+			return
+		case <-ticker.C:
+			c.checkSession()
+		}
+	}
+}
+
+// checkSession notifies the unavailability once the session is lost, and gives up once it has been lost
+// for maxReconnectDuration
+func (c *zookeeperDynamicConfiguration) checkSession() {
+	c.cltLock.Lock()
+	if c.client != nil && c.client.ZkConnValid() {
+		c.lostSince = time.Time{}
+		c.cltLock.Unlock()
+		return
+	}
+	if c.lostSince.IsZero() {
+		c.lostSince = time.Now()
+	}
+	lost := time.Since(c.lostSince)
+	c.cltLock.Unlock()
+	c.NotifyAvailability(false)
+	if c.maxReconnectDuration > 0 && lost >= c.maxReconnectDuration {
+		c.giveUp()
+	}
+}
+
 func (c *zookeeperDynamicConfiguration) getPath(key string, group string) string {
 	if len(key) == 0 {
 		return c.buildPath(group)
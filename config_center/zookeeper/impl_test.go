@@ -0,0 +1,453 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/dubbogo/go-zookeeper/zk"
+
+	gxzookeeper "github.com/dubbogo/gost/database/kv/zk"
+
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+)
+
+func TestDecodeBase64Auto(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "base64", content: "ZHViYm8ucHJvdG9jb2wubmFtZT1kdWJibw==", want: "dubbo.protocol.name=dubbo"},
+		{name: "plain", content: "dubbo.protocol.name=dubbo", want: "dubbo.protocol.name=dubbo"},
+		{name: "empty", content: "", want: ""},
+		// decodes to bytes which are not utf-8
+		{name: "binary", content: "true", want: "true"},
+		// not the canonical encoding of its decoded bytes
+		{name: "non canonical", content: "YWJjZB==", want: "YWJjZB=="},
+		// ambiguous, a plain value looking like base64 is decoded
+		{name: "ambiguous", content: "YWJj", want: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, decodeBase64Auto(tt.content))
+		})
+	}
+}
+
+func TestOnAvailabilityChange(t *testing.T) {
+	// the session of a client never connected is not valid
+	c := &zookeeperDynamicConfiguration{client: &gxzookeeper.ZookeeperClient{}}
+	var changes []bool
+	c.OnAvailabilityChange(func(available bool) {
+		changes = append(changes, available)
+	})
+
+	// the session is lost
+	c.checkSession()
+	c.checkSession()
+	assert.Equal(t, []bool{false}, changes)
+
+	// the session is restored
+	c.RestartCallBack()
+	c.RestartCallBack()
+	assert.Equal(t, []bool{false, true}, changes)
+}
+
+// mockReplica keeps the znodes of the read replica in memory
+type mockReplica struct {
+	nodes map[string]string
+	reads int
+	down  bool
+}
+
+func (r *mockReplica) GetContent(path string) ([]byte, *zk.Stat, error) {
+	r.reads++
+	if content, ok := r.nodes[path]; ok {
+		return []byte(content), &zk.Stat{Version: 1}, nil
+	}
+	return nil, nil, zk.ErrNoNode
+}
+
+func (r *mockReplica) GetChildren(path string) ([]string, error) {
+	r.reads++
+	if r.down {
+		return nil, errors.New("replica is down")
+	}
+	var children []string
+	for node := range r.nodes {
+		if strings.HasPrefix(node, path+"/") {
+			children = append(children, strings.TrimPrefix(node, path+"/"))
+		}
+	}
+	return children, nil
+}
+
+func (r *mockReplica) Exists(path string) (bool, error) {
+	if r.down {
+		return false, errors.New("replica is down")
+	}
+	_, ok := r.nodes[path]
+	return ok, nil
+}
+
+func (r *mockReplica) ZkConnValid() bool {
+	return true
+}
+
+func (r *mockReplica) Close() {}
+
+func TestReadReplica(t *testing.T) {
+	replica := &mockReplica{nodes: map[string]string{"/dubbo/config/dubbo/dubbo.properties": "dubbo.protocol.name=dubbo"}}
+	// the primary never connected fails the reads and writes
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: &gxzookeeper.ZookeeperClient{}, replica: replica}
+
+	// the reads hit the replica
+	content, err := c.GetProperties("dubbo.properties", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo.protocol.name=dubbo", content)
+	keys, err := c.GetConfigKeysByGroup("dubbo")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"dubbo.properties"}, keys.Values())
+	assert.Equal(t, 2, replica.reads)
+
+	// the writes hit the primary
+	err = c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=tri")
+	assert.True(t, errors.Is(perrors.Cause(err), gxzookeeper.ErrNilZkClientConn))
+	assert.Equal(t, 2, replica.reads)
+
+	// the reads fail over to the primary
+	replica.down = true
+	_, err = c.GetConfigKeysByGroup("dubbo")
+	assert.True(t, errors.Is(perrors.Cause(err), gxzookeeper.ErrNilZkClientConn))
+	assert.Equal(t, 3, replica.reads)
+}
+
+func TestGetPropertiesWithStatConcurrentWrite(t *testing.T) {
+	ts, client, _, err := gxzookeeper.NewMockZookeeperClient("test", 15*time.Second)
+	if err != nil {
+		t.Skipf("the zookeeper test cluster is not available: %v", err)
+	}
+	defer func() {
+		client.Close()
+		_ = ts.Stop()
+	}()
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: client}
+	path := "/dubbo/config/dubbo/dubbo.properties"
+	assert.NoError(t, client.CreateWithValue(path, []byte("v=0")))
+
+	// every write bumps the version of the znode by one, which is recorded in the value
+	const writes = 200
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= writes; i++ {
+			if _, err := client.SetContent(path, []byte(fmt.Sprintf("v=%d", i)), -1); err != nil {
+				t.Errorf("write %d: %v", i, err)
+				return
+			}
+		}
+	}()
+
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		value, version, err := c.GetPropertiesWithStat("dubbo.properties", config_center.WithGroup("dubbo"))
+		assert.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("v=%d", version), value)
+	}
+	value, version, err := c.GetPropertiesWithStat("dubbo.properties", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(writes), version)
+	assert.Equal(t, fmt.Sprintf("v=%d", writes), value)
+}
+
+func TestGetTypedMissingKey(t *testing.T) {
+	replica := &mockReplica{nodes: map[string]string{"/dubbo/config/dubbo/dubbo.retries": "five"}}
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: &gxzookeeper.ZookeeperClient{}, replica: replica}
+
+	_, err := c.GetProperties("dubbo.missing", config_center.WithGroup("dubbo"))
+	assert.Equal(t, zk.ErrNoNode, perrors.Cause(err))
+	assert.True(t, config_center.IsNotFound(err))
+	retries, err := config_center.GetInt(c, "dubbo.missing", 3, config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, retries)
+
+	retries, err = config_center.GetInt(c, "dubbo.retries", 3, config_center.WithGroup("dubbo"))
+	assert.Error(t, err)
+	assert.Equal(t, 3, retries)
+}
+
+func TestGetPropertiesAcrossGroups(t *testing.T) {
+	replica := &mockReplica{nodes: map[string]string{
+		"/dubbo/config/app-a/tag-router": "force: true",
+		"/dubbo/config/app-b/tag-router": "force: false",
+		"/dubbo/config/app-d/tag-router": "%%",
+	}}
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: &gxzookeeper.ZookeeperClient{}, replica: replica}
+
+	// app-c lacks the key
+	values, err := c.GetPropertiesAcrossGroups("tag-router", []string{"app-a", "app-b", "app-c"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app-a": "force: true", "app-b": "force: false"}, values)
+
+	// the value of app-d fails to be decoded
+	c.base64Enabled = true
+	replica.nodes["/dubbo/config/app-a/tag-router"] = "Zm9yY2U6IHRydWU="
+	_, err = c.GetPropertiesAcrossGroups("tag-router", []string{"app-a", "app-c", "app-d"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "get key tag-router in group app-d")
+}
+
+func TestExists(t *testing.T) {
+	replica := &mockReplica{nodes: map[string]string{"/dubbo/config/dubbo/feature.enabled": "true"}}
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: &gxzookeeper.ZookeeperClient{}, replica: replica}
+
+	exists, err := config_center.Exists(c, "feature.enabled", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = c.Exists("feature.disabled", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	// the data is never read
+	assert.Equal(t, 0, replica.reads)
+
+	// the primary never connected fails the check
+	replica.down = true
+	_, err = c.Exists("feature.enabled", config_center.WithGroup("dubbo"))
+	assert.True(t, errors.Is(perrors.Cause(err), gxzookeeper.ErrNilZkClientConn))
+}
+
+func TestBackendInfo(t *testing.T) {
+	url, err := common.NewURL("zookeeper://127.0.0.1:2181,127.0.0.2:2181")
+	assert.NoError(t, err)
+	c := &zookeeperDynamicConfiguration{url: url, rootPath: "/dubbo/config"}
+	assert.Equal(t, config_center.BackendInfo{
+		Protocol:            "zookeeper",
+		Endpoints:           []string{"127.0.0.1:2181", "127.0.0.2:2181"},
+		Namespace:           "/dubbo/config",
+		SupportsPublish:     true,
+		SupportsKeysByGroup: true,
+	}, c.BackendInfo())
+}
+
+// mockPublisher keeps the znodes of the primary in memory, each write bumps the version
+type mockPublisher struct {
+	nodes    map[string]string
+	versions map[string]int32
+}
+
+func (p *mockPublisher) GetContent(path string) ([]byte, *zk.Stat, error) {
+	if content, ok := p.nodes[path]; ok {
+		return []byte(content), &zk.Stat{Version: p.versions[path]}, nil
+	}
+	return nil, nil, zk.ErrNoNode
+}
+
+func (p *mockPublisher) CreateWithValue(path string, value []byte) error {
+	p.nodes[path] = string(value)
+	p.versions[path]++
+	return nil
+}
+
+func (p *mockPublisher) CreateTempWithValue(path string, value []byte) error {
+	return p.CreateWithValue(path, value)
+}
+
+func TestPublishIdenticalConfig(t *testing.T) {
+	publisher := &mockPublisher{nodes: map[string]string{}, versions: map[string]int32{}}
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", publisher: publisher}
+	path := "/dubbo/config/dubbo/dubbo.properties"
+
+	// written each time by default
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=dubbo"))
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=dubbo"))
+	assert.Equal(t, int32(2), publisher.versions[path])
+
+	c.skipIdentical = true
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=dubbo"))
+	assert.Equal(t, int32(2), publisher.versions[path])
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=tri"))
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=tri"))
+	assert.Equal(t, int32(3), publisher.versions[path])
+	assert.Equal(t, "dubbo.protocol.name=tri", publisher.nodes[path])
+}
+
+func TestPublishEphemeralConfig(t *testing.T) {
+	ts, reader, _, err := gxzookeeper.NewMockZookeeperClient("reader", 15*time.Second)
+	if err != nil {
+		t.Skipf("the zookeeper test cluster is not available: %v", err)
+	}
+	defer func() {
+		reader.Close()
+		_ = ts.Stop()
+	}()
+	_, publisher, _, err := gxzookeeper.NewMockZookeeperClient("publisher", 15*time.Second, gxzookeeper.WithTestCluster(ts))
+	assert.NoError(t, err)
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: publisher}
+	path := "/dubbo/config/dubbo/dubbo.properties"
+
+	assert.NoError(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=dubbo", config_center.WithEphemeral(true)))
+	content, _, err := reader.GetContent(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo.protocol.name=dubbo", string(content))
+
+	// the node goes away with the session of the publisher
+	publisher.Close()
+	assert.Eventually(t, func() bool {
+		_, _, err := reader.GetContent(path)
+		return errors.Is(perrors.Cause(err), zk.ErrNoNode)
+	}, 15*time.Second, 100*time.Millisecond)
+}
+
+func TestGetPropertiesWithMaxAge(t *testing.T) {
+	replica := &mockReplica{nodes: map[string]string{"/dubbo/config/dubbo/dubbo.properties": "dubbo.protocol.name=dubbo"}}
+	c := &zookeeperDynamicConfiguration{rootPath: "/dubbo/config", client: &gxzookeeper.ZookeeperClient{}, replica: replica}
+
+	// the fresh cached value is read without the backend
+	content, err := c.GetProperties("dubbo.properties", config_center.WithGroup("dubbo"), config_center.WithMaxAge(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo.protocol.name=dubbo", content)
+	replica.nodes["/dubbo/config/dubbo/dubbo.properties"] = "dubbo.protocol.name=tri"
+	content, err = c.GetProperties("dubbo.properties", config_center.WithGroup("dubbo"), config_center.WithMaxAge(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo.protocol.name=dubbo", content)
+	assert.Equal(t, 1, replica.reads)
+
+	// the stale one is refreshed
+	time.Sleep(10 * time.Millisecond)
+	content, err = c.GetProperties("dubbo.properties", config_center.WithGroup("dubbo"), config_center.WithMaxAge(5*time.Millisecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "dubbo.protocol.name=tri", content)
+	assert.Equal(t, 2, replica.reads)
+
+	// always read from the backend without the max age
+	_, err = c.GetProperties("dubbo.properties", config_center.WithGroup("dubbo"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, replica.reads)
+}
+
+func TestLenientStartup(t *testing.T) {
+	config.SetRootConfig(config.RootConfig{ConfigCenter: &config.CenterConfig{}})
+	retryInterval := connectRetryInterval
+	connectRetryInterval = 10 * time.Millisecond
+	defer func() {
+		connectRetryInterval = retryInterval
+	}()
+
+	// strict by default, nothing listens on the port
+	url, err := common.NewURL("registry://127.0.0.1:1?timeout=100ms")
+	assert.NoError(t, err)
+	_, err = newZookeeperDynamicConfiguration(url)
+	assert.Error(t, err)
+
+	url.SetParam(constant.CONFIG_LENIENT_STARTUP_KEY, "true")
+	c, err := newZookeeperDynamicConfiguration(url)
+	assert.NoError(t, err)
+	assert.False(t, c.Available())
+	_, err = c.GetProperties("dubbo.properties", config_center.WithGroup("dubbo"))
+	assert.Error(t, err)
+	_, err = c.GetConfigKeysByGroup("dubbo")
+	assert.Error(t, err)
+	c.skipIdentical = true
+	assert.Error(t, c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=dubbo"))
+	c.AddListener("dubbo.properties", &recordingListener{})
+
+	// still connecting in the background
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, c.Available())
+	c.Destroy()
+}
+
+func TestPublishNotConnected(t *testing.T) {
+	url, err := common.NewURL("registry://127.0.0.1:1")
+	assert.NoError(t, err)
+	// the client of the primary is not started yet
+	c := &zookeeperDynamicConfiguration{url: url, rootPath: "/dubbo/config", skipIdentical: true}
+	err = c.PublishConfig("dubbo.properties", "dubbo", "dubbo.protocol.name=dubbo")
+	assert.True(t, errors.Is(err, gxzookeeper.ErrNilZkClientConn))
+	assert.Contains(t, err.Error(), "not connected")
+}
+
+func TestEmptyAddress(t *testing.T) {
+	config.SetRootConfig(config.RootConfig{ConfigCenter: &config.CenterConfig{}})
+	for _, location := range []string{"", ","} {
+		url, err := common.NewURL("zookeeper://" + location + "?timeout=100ms")
+		assert.NoError(t, err)
+		url.SetParam(constant.CONFIG_LENIENT_STARTUP_KEY, "true")
+		_, err = newZookeeperDynamicConfiguration(url)
+		assert.True(t, errors.Is(perrors.Cause(err), config_center.ErrNoAddress))
+		assert.EqualError(t, err, "create zookeeper config center: no address of the config center")
+	}
+}
+
+func TestMaxReconnectDuration(t *testing.T) {
+	config.SetRootConfig(config.RootConfig{ConfigCenter: &config.CenterConfig{}})
+	retryInterval := connectRetryInterval
+	connectRetryInterval = 10 * time.Millisecond
+	defer func() {
+		connectRetryInterval = retryInterval
+	}()
+
+	// gives up connecting in the background, nothing listens on the port
+	url, err := common.NewURL("registry://127.0.0.1:1?timeout=100ms&" + constant.CONFIG_LENIENT_STARTUP_KEY + "=true&" +
+		constant.CONFIG_MAX_RECONNECT_DURATION_KEY + "=50ms")
+	assert.NoError(t, err)
+	c, err := newZookeeperDynamicConfiguration(url)
+	assert.NoError(t, err)
+	assert.True(t, c.IsAvailable())
+	// each attempt blocks until zookeeper times out creating the root path
+	assert.Eventually(t, func() bool {
+		return !c.IsAvailable()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, c.Available())
+	c.Destroy()
+
+	// gives up once the session has been lost for the max duration
+	c = &zookeeperDynamicConfiguration{url: url, done: make(chan struct{}), maxReconnectDuration: time.Minute}
+	var notified []bool
+	c.OnAvailabilityChange(func(available bool) {
+		notified = append(notified, available)
+	})
+	c.checkSession()
+	assert.True(t, c.IsAvailable())
+	assert.Equal(t, []bool{false}, notified)
+	c.lostSince = time.Now().Add(-time.Minute)
+	c.checkSession()
+	assert.False(t, c.IsAvailable())
+	assert.False(t, c.Available())
+	c.Destroy()
+}
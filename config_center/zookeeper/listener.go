@@ -20,19 +20,31 @@ package zookeeper
 import (
 	"strings"
 	"sync"
+	"time"
+)
+
+import (
+	perrors "github.com/pkg/errors"
 )
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/metrics"
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
 
 // CacheListener defines keyListeners and rootPath
 type CacheListener struct {
 	keyListeners sync.Map
-	rootPath     string
+	// guards the replacement of the listeners of a key
+	lock     sync.Mutex
+	rootPath string
+	// the max number of listeners of a key, 0 means unlimited
+	maxListeners int
+	// bounds the listeners notified concurrently, nil means they are notified serially
+	dispatchTokens chan struct{}
 }
 
 // NewCacheListener creates a new CacheListener
@@ -40,40 +52,84 @@ func NewCacheListener(rootPath string) *CacheListener {
 	return &CacheListener{rootPath: rootPath}
 }
 
-// AddListener will add a listener if loaded
-func (l *CacheListener) AddListener(key string, listener config_center.ConfigurationListener) {
-	// reference from https://stackoverflow.com/questions/34018908/golang-why-dont-we-have-a-set-datastructure
-	// make a map[your type]struct{} like set in java
-	listeners, loaded := l.keyListeners.LoadOrStore(key, map[config_center.ConfigurationListener]struct{}{listener: {}})
-	if loaded {
-		listeners.(map[config_center.ConfigurationListener]struct{})[listener] = struct{}{}
-		l.keyListeners.Store(key, listeners)
+// SetMaxListeners limits the number of listeners of a key to @max, 0 means unlimited
+func (l *CacheListener) SetMaxListeners(max int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.maxListeners = max
+}
+
+// SetDispatchConcurrency notifies the listeners concurrently, up to @concurrency of them at once, so a slow listener
+// does not hold up the others or the watching of zookeeper. The listeners are no longer notified in the order of
+// their priority then, nor the changes in the order they happen. 0 or 1 means serially, which is the default.
+// It must be set before any change is dispatched.
+func (l *CacheListener) SetDispatchConcurrency(concurrency int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.dispatchTokens = nil
+	if concurrency > 1 {
+		l.dispatchTokens = make(chan struct{}, concurrency)
+	}
+}
+
+// AddListener will add a listener, listeners are notified in the order of their priority.
+// The listener is rejected once the key has reached the max number of listeners.
+func (l *CacheListener) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) error {
+	tmpOpts := &config_center.Options{}
+	for _, opt := range opts {
+		opt(tmpOpts)
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	listeners := l.loadListeners(key)
+	if l.maxListeners > 0 && len(listeners) >= l.maxListeners && !listeners.Contains(listener) {
+		return perrors.Errorf("the listeners of key %s have reached the max number %d", key, l.maxListeners)
 	}
+	l.keyListeners.Store(key, listeners.Add(config_center.ListenerEntry{
+		Listener:    listener,
+		Priority:    tmpOpts.Priority,
+		ChangeTypes: tmpOpts.ChangeTypes,
+		WatchType:   tmpOpts.WatchType,
+	}))
+	return nil
 }
 
 // RemoveListener will delete a listener if loaded
 func (l *CacheListener) RemoveListener(key string, listener config_center.ConfigurationListener) {
-	listeners, loaded := l.keyListeners.Load(key)
-	if loaded {
-		delete(listeners.(map[config_center.ConfigurationListener]struct{}), listener)
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, loaded := l.keyListeners.Load(key); loaded {
+		l.keyListeners.Store(key, l.loadListeners(key).Remove(listener))
 	}
 }
 
-// DataChange changes all listeners' event
+func (l *CacheListener) loadListeners(key string) config_center.ListenerEntries {
+	if listeners, ok := l.keyListeners.Load(key); ok {
+		return listeners.(config_center.ListenerEntries)
+	}
+	return nil
+}
+
+// DataChange changes all listeners' event.
+// The change of a node is dispatched to the data watchers of its own key, and its addition or removal is
+// dispatched to the children watchers of the key of its parent as well, with the name of the node as the value.
 func (l *CacheListener) DataChange(event remoting.Event) bool {
-	if event.Content == "" {
+	defer metrics.RecordOperation(zkProtocol, metrics.OperationEvent, time.Now(), nil)
+	if i := strings.LastIndex(event.Path, "/"); i > len(l.rootPath) && event.Action != remoting.EventTypeUpdate {
+		l.dispatchChild(l.eventKey(event.Path[:i]), event.Path[i+1:], event)
+	}
+	if event.Content == "" && event.Action != remoting.EventTypeDel {
 		// meanings new node
 		return true
 	}
-	key := l.pathToKey(event.Path)
-	// TODO use common way
-	if strings.HasSuffix(key, constant.MeshRouteSuffix) {
-		key = key[:strings.Index(key, constant.MeshRouteSuffix)]
-	}
+	key := l.eventKey(event.Path)
 	if key != "" {
 		if listeners, ok := l.keyListeners.Load(key); ok {
-			for listener := range listeners.(map[config_center.ConfigurationListener]struct{}) {
-				listener.Process(&config_center.ConfigChangeEvent{Key: key, Value: event.Content, ConfigType: event.Action})
+			for _, entry := range listeners.(config_center.ListenerEntries) {
+				if !entry.WatchesData() || !entry.Accepts(event.Action) {
+					continue
+				}
+				l.notify(entry.Listener, &config_center.ConfigChangeEvent{Key: key, Value: event.Content, ConfigType: event.Action})
 			}
 			return true
 		}
@@ -81,6 +137,59 @@ func (l *CacheListener) DataChange(event remoting.Event) bool {
 	return false
 }
 
+// dispatchChild dispatches the addition or removal of the @child node to the children watchers of @key
+func (l *CacheListener) dispatchChild(key string, child string, event remoting.Event) {
+	if key == "" {
+		return
+	}
+	listeners, ok := l.keyListeners.Load(key)
+	if !ok {
+		return
+	}
+	for _, entry := range listeners.(config_center.ListenerEntries) {
+		if !entry.WatchesChildren() || !entry.Accepts(event.Action) {
+			continue
+		}
+		l.notify(entry.Listener, &config_center.ConfigChangeEvent{Key: key, Value: child, ConfigType: event.Action,
+			Changes: map[string]*config_center.ConfigItemChange{child: {NewValue: event.Content, ChangeType: event.Action}}})
+	}
+}
+
+// notify dispatches @event to @listener, in a new goroutine once a token is available if the dispatch is concurrent.
+// The panic of the listener is recovered, which does not stop the dispatch to the others.
+func (l *CacheListener) notify(listener config_center.ConfigurationListener, event *config_center.ConfigChangeEvent) {
+	process := func() {
+		defer func() {
+			if e := recover(); e != nil {
+				logger.Errorf("the listener of key %s panics on the change %v: %v", event.Key, event.ConfigType, e)
+			}
+		}()
+		listener.Process(event)
+	}
+	tokens := l.dispatchTokens
+	if tokens == nil {
+		process()
+		return
+	}
+	tokens <- struct{}{}
+	go func() {
+		defer func() {
+			<-tokens
+		}()
+		process()
+	}()
+}
+
+// eventKey returns the key of the listeners of the node at @path
+func (l *CacheListener) eventKey(path string) string {
+	key := l.pathToKey(path)
+	// TODO use common way
+	if strings.HasSuffix(key, constant.MeshRouteSuffix) {
+		key = key[:strings.Index(key, constant.MeshRouteSuffix)]
+	}
+	return key
+}
+
 func (l *CacheListener) pathToKey(path string) string {
 	key := strings.Replace(strings.Replace(path, l.rootPath+"/", "", -1), "/", ".", -1)
 	if strings.HasSuffix(key, constant.ConfiguratorSuffix) ||
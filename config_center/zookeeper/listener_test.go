@@ -0,0 +1,192 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package zookeeper
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const mockRootPath = "/dubbo/config"
+
+// orderedListener records the name of the listener in the shared order once it is notified
+type orderedListener struct {
+	name  string
+	lock  *sync.Mutex
+	order *[]string
+}
+
+func (l *orderedListener) Process(*config_center.ConfigChangeEvent) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	*l.order = append(*l.order, l.name)
+}
+
+func newOrderedListeners(names ...string) ([]*orderedListener, *[]string) {
+	lock := &sync.Mutex{}
+	order := &[]string{}
+	listeners := make([]*orderedListener, 0, len(names))
+	for _, name := range names {
+		listeners = append(listeners, &orderedListener{name: name, lock: lock, order: order})
+	}
+	return listeners, order
+}
+
+func TestCacheListenerPriority(t *testing.T) {
+	listeners, order := newOrderedListeners("reinit", "invalidate", "audit")
+	cl := NewCacheListener(mockRootPath)
+	cl.AddListener("dubbo.test", listeners[0])
+	cl.AddListener("dubbo.test", listeners[1], config_center.WithPriority(10))
+	cl.AddListener("dubbo.test", listeners[2])
+
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeUpdate, Content: "v1"}))
+	assert.Equal(t, []string{"invalidate", "reinit", "audit"}, *order)
+
+	cl.RemoveListener("dubbo.test", listeners[1])
+	*order = nil
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeUpdate, Content: "v2"}))
+	assert.Equal(t, []string{"reinit", "audit"}, *order)
+}
+
+func TestCacheListenerChangeTypes(t *testing.T) {
+	listeners, order := newOrderedListeners("evict", "all")
+	cl := NewCacheListener(mockRootPath)
+	cl.AddListener("dubbo.test", listeners[0], config_center.WithChangeTypes(remoting.EventTypeDel))
+	cl.AddListener("dubbo.test", listeners[1])
+
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeUpdate, Content: "v1"}))
+	assert.Equal(t, []string{"all"}, *order)
+
+	*order = nil
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeDel}))
+	assert.Equal(t, []string{"evict", "all"}, *order)
+}
+
+func TestCacheListenerMaxListeners(t *testing.T) {
+	listeners, _ := newOrderedListeners("a", "b", "c")
+	cl := NewCacheListener(mockRootPath)
+	cl.SetMaxListeners(2)
+	assert.NoError(t, cl.AddListener("dubbo.test", listeners[0]))
+	assert.NoError(t, cl.AddListener("dubbo.test", listeners[1]))
+	assert.Error(t, cl.AddListener("dubbo.test", listeners[2]))
+	// re-registering does not grow the listeners
+	assert.NoError(t, cl.AddListener("dubbo.test", listeners[1], config_center.WithPriority(1)))
+	assert.Len(t, cl.loadListeners("dubbo.test"), 2)
+	// other keys are capped separately
+	assert.NoError(t, cl.AddListener("dubbo.other", listeners[2]))
+
+	cl.RemoveListener("dubbo.test", listeners[0])
+	assert.NoError(t, cl.AddListener("dubbo.test", listeners[2]))
+}
+
+// recordingListener records the events it is notified of
+type recordingListener struct {
+	events []*config_center.ConfigChangeEvent
+}
+
+func (l *recordingListener) Process(event *config_center.ConfigChangeEvent) {
+	l.events = append(l.events, event)
+}
+
+func TestCacheListenerWatchType(t *testing.T) {
+	data, children, both := &recordingListener{}, &recordingListener{}, &recordingListener{}
+	cl := NewCacheListener(mockRootPath)
+	cl.AddListener("dubbo.test", data)
+	cl.AddListener("dubbo.test", children, config_center.WithWatchType(config_center.WatchChildren))
+	cl.AddListener("dubbo.test", both, config_center.WithWatchType(config_center.WatchBoth))
+
+	// the value of the leaf node changes
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeUpdate, Content: "v1"}))
+	assert.Len(t, data.events, 1)
+	assert.Equal(t, "v1", data.events[0].Value)
+	assert.Len(t, children.events, 0)
+	assert.Len(t, both.events, 1)
+
+	// a child is added to the container node, and then removed
+	cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test/provider1", Action: remoting.EventTypeAdd, Content: "weight=100"})
+	cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test/provider1", Action: remoting.EventTypeUpdate, Content: "weight=50"})
+	cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test/provider1", Action: remoting.EventTypeDel})
+	assert.Len(t, data.events, 1)
+	assert.Len(t, children.events, 2)
+	assert.Equal(t, "dubbo.test", children.events[0].Key)
+	assert.Equal(t, "provider1", children.events[0].Value)
+	assert.EqualValues(t, remoting.EventTypeAdd, children.events[0].ConfigType)
+	assert.Equal(t, "weight=100", children.events[0].Changes["provider1"].NewValue)
+	assert.EqualValues(t, remoting.EventTypeDel, children.events[1].ConfigType)
+	assert.Len(t, both.events, 3)
+
+	// the empty child is dispatched to the children watchers as well
+	cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test/provider2", Action: remoting.EventTypeAdd})
+	assert.Len(t, children.events, 3)
+	assert.Equal(t, "provider2", children.events[2].Value)
+}
+
+// funcListener processes the events with the func
+type funcListener struct {
+	process func(event *config_center.ConfigChangeEvent)
+}
+
+func (l *funcListener) Process(event *config_center.ConfigChangeEvent) {
+	l.process(event)
+}
+
+func TestCacheListenerDispatchConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	notified := make(chan string, 10)
+	slow := &funcListener{func(*config_center.ConfigChangeEvent) {
+		<-release
+		notified <- "slow"
+	}}
+	panicking := &funcListener{func(*config_center.ConfigChangeEvent) {
+		panic("failed")
+	}}
+	fast := &funcListener{func(event *config_center.ConfigChangeEvent) {
+		notified <- event.Value.(string)
+	}}
+	cl := NewCacheListener(mockRootPath)
+	cl.SetDispatchConcurrency(4)
+	cl.AddListener("dubbo.test", slow, config_center.WithPriority(10))
+	cl.AddListener("dubbo.test", panicking, config_center.WithPriority(5))
+	cl.AddListener("dubbo.test", fast)
+
+	// the fast listener is not held up by the slow one with the higher priority, nor by the panicking one
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeUpdate, Content: "v1"}))
+	select {
+	case value := <-notified:
+		assert.Equal(t, "v1", value)
+	case <-time.After(time.Second):
+		t.Fatal("the fast listener is not notified")
+	}
+	close(release)
+	assert.Equal(t, "slow", <-notified)
+
+	// the panic is recovered in the serial dispatch as well
+	cl.SetDispatchConcurrency(0)
+	assert.True(t, cl.DataChange(remoting.Event{Path: mockRootPath + "/dubbo/test", Action: remoting.EventTypeUpdate, Content: "v2"}))
+	assert.Equal(t, []string{"slow", "v2"}, []string{<-notified, <-notified})
+}
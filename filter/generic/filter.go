@@ -53,8 +53,8 @@ func (f *Filter) Invoke(ctx context.Context, invoker protocol.Invoker, invocatio
 		types := make([]string, 0, len(oldargs))
 		args := make([]hessian.Object, 0, len(oldargs))
 
-		// get generic info from attachments of invocation, the default value is "true"
-		generic := invocation.AttachmentsByKey(constant.GENERIC_KEY, constant.GenericSerializationDefault)
+		// get generic info from attachments of invocation, the default value is the generic of the url
+		generic := invocation.AttachmentsByKey(constant.GENERIC_KEY, invoker.GetURL().GetParam(constant.GENERIC_KEY, ""))
 		// get generalizer according to value in the `generic`
 		g := getGeneralizer(generic)
 
@@ -81,7 +81,7 @@ func (f *Filter) Invoke(ctx context.Context, invoker protocol.Invoker, invocatio
 		}
 		newivc := invocation2.NewRPCInvocation(constant.GENERIC, newargs, invocation.Attachments())
 		newivc.SetReply(invocation.Reply())
-		newivc.Attachments()[constant.GENERIC_KEY] = invoker.GetURL().GetParam(constant.GENERIC_KEY, "")
+		newivc.Attachments()[constant.GENERIC_KEY] = generic
 
 		return invoker.Invoke(ctx, newivc)
 	} else if isMakingAGenericCall(invoker, invocation) {
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generalizer
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+var (
+	jsonStringGeneralizer     Generalizer
+	jsonStringGeneralizerOnce sync.Once
+)
+
+// GetJsonGeneralizer returns the generalizer passing the objects as json strings,
+// e.g. the arguments of the generic calls bridged from http
+func GetJsonGeneralizer() Generalizer {
+	jsonStringGeneralizerOnce.Do(func() {
+		jsonStringGeneralizer = &JsonGeneralizer{}
+	})
+	return jsonStringGeneralizer
+}
+
+// JsonGeneralizer generalizes an object of any type to a json string, unlike GsonGeneralizer it is not limited to POJO
+type JsonGeneralizer struct{}
+
+func (JsonGeneralizer) Generalize(obj interface{}) (interface{}, error) {
+	jsonbytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return string(jsonbytes), nil
+}
+
+func (JsonGeneralizer) Realize(obj interface{}, typ reflect.Type) (interface{}, error) {
+	var jsonbytes []byte
+	switch v := obj.(type) {
+	case string:
+		jsonbytes = []byte(v)
+	case []byte:
+		jsonbytes = v
+	default:
+		return nil, perrors.Errorf("unexpected type of obj(=%T), wanted is string", obj)
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		ret := reflect.New(typ.Elem())
+		if err := json.Unmarshal(jsonbytes, ret.Interface()); err != nil {
+			return nil, err
+		}
+		return ret.Interface(), nil
+	}
+	ret := reflect.New(typ)
+	if err := json.Unmarshal(jsonbytes, ret.Interface()); err != nil {
+		return nil, err
+	}
+	return ret.Elem().Interface(), nil
+}
+
+func (JsonGeneralizer) GetType(obj interface{}) (typ string, err error) {
+	return GsonGeneralizer{}.GetType(obj)
+}
@@ -0,0 +1,64 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generalizer
+
+import (
+	"reflect"
+	"testing"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+var mockJsonGeneralizer = GetJsonGeneralizer()
+
+type mockJsonUser struct {
+	Name string            `json:"name"`
+	Age  int               `json:"age"`
+	Tags map[string]string `json:"tags"`
+}
+
+func TestJsonGeneralizer(t *testing.T) {
+	u := &mockJsonUser{Name: "dubbo", Age: 10, Tags: map[string]string{"zone": "hz"}}
+
+	m, err := mockJsonGeneralizer.Generalize(u)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"dubbo","age":10,"tags":{"zone":"hz"}}`, m)
+
+	r, err := mockJsonGeneralizer.Realize(m, reflect.TypeOf(u))
+	assert.Nil(t, err)
+	assert.Equal(t, u, r)
+
+	r, err = mockJsonGeneralizer.Realize([]byte(`{"name":"dubbo"}`), reflect.TypeOf(mockJsonUser{}))
+	assert.Nil(t, err)
+	assert.Equal(t, mockJsonUser{Name: "dubbo"}, r)
+
+	// basic types are not limited to POJO
+	m, err = mockJsonGeneralizer.Generalize([]int{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, "[1,2]", m)
+	r, err = mockJsonGeneralizer.Realize(m, reflect.TypeOf([]int{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, r)
+
+	_, err = mockJsonGeneralizer.Realize(1, reflect.TypeOf(u))
+	assert.NotNil(t, err)
+	_, err = mockJsonGeneralizer.Realize("{", reflect.TypeOf(u))
+	assert.NotNil(t, err)
+}
@@ -19,6 +19,7 @@ package generic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"testing"
@@ -69,6 +70,66 @@ func (s *MockHelloService) HelloPB(req *generalizer.RequestType) (*generalizer.R
 	return nil, perrors.Errorf("people not found")
 }
 
+type MockUserRequest struct {
+	Id int64 `json:"id"`
+}
+
+type MockUserResponse struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type MockUserService struct{}
+
+func (s *MockUserService) GetUser(req *MockUserRequest) (*MockUserResponse, error) {
+	return &MockUserResponse{Id: req.Id, Name: "dubbo"}, nil
+}
+
+func (s *MockUserService) Reference() string {
+	return "org.apache.dubbo.user"
+}
+
+func TestServiceFilter_InvokeJson(t *testing.T) {
+	filter := &ServiceFilter{}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := &MockUserService{}
+	ivkUrl := common.NewURLWithOptions(
+		common.WithProtocol("test"),
+		common.WithParams(url.Values{}),
+		common.WithParamsValue(constant.INTERFACE_KEY, service.Reference()),
+		common.WithParamsValue(constant.GENERIC_KEY, constant.GenericSerializationJson))
+	_, err := common.ServiceMap.Register(ivkUrl.GetParam(constant.INTERFACE_KEY, ""), ivkUrl.Protocol, "", "", service)
+	assert.Nil(t, err)
+
+	mockInvoker := mock.NewMockInvoker(ctrl)
+	mockInvoker.EXPECT().GetUrl().Return(ivkUrl)
+	mockInvoker.EXPECT().Invoke(gomock.Any()).DoAndReturn(
+		func(invocation protocol.Invocation) protocol.Result {
+			result, err := service.GetUser(invocation.Arguments()[0].(*MockUserRequest))
+			return &protocol.RPCResult{Rest: result, Err: err}
+		})
+
+	ivc := invocation.NewRPCInvocation(constant.GENERIC,
+		[]interface{}{
+			"GetUser",
+			[]string{"org.apache.dubbo.UserRequest"},
+			[]hessian.Object{`{"id":1}`},
+		}, map[string]interface{}{
+			constant.GENERIC_KEY: constant.GenericSerializationJson,
+		})
+	result := filter.Invoke(context.Background(), mockInvoker, ivc)
+	assert.Nil(t, result.Error())
+	result = filter.OnResponse(context.Background(), result, mockInvoker, ivc)
+	assert.Nil(t, result.Error())
+
+	var resp MockUserResponse
+	assert.Nil(t, json.Unmarshal([]byte(result.Result().(string)), &resp))
+	assert.Equal(t, MockUserResponse{Id: 1, Name: "dubbo"}, resp)
+}
+
 func TestServiceFilter_Invoke(t *testing.T) {
 	filter := &ServiceFilter{}
 
@@ -45,7 +45,8 @@ func isMakingAGenericCall(invoker protocol.Invoker, invocation protocol.Invocati
 // isGeneric receives a generic field from url of invoker to determine whether the service is generic or not
 func isGeneric(generic string) bool {
 	lowerGeneric := strings.ToLower(generic)
-	return lowerGeneric == constant.GenericSerializationDefault
+	return lowerGeneric == constant.GenericSerializationDefault ||
+		lowerGeneric == constant.GenericSerializationJson
 }
 
 // isGenericInvocation determines if the invocation has generic format
@@ -71,6 +72,8 @@ func getGeneralizer(generic string) (g generalizer.Generalizer) {
 		g = generalizer.GetMapGeneralizer()
 	case constant.GenericSerializationGson:
 		g = generalizer.GetGsonGeneralizer()
+	case constant.GenericSerializationJson:
+		g = generalizer.GetJsonGeneralizer()
 
 	default:
 		logger.Debugf("\"%s\" is not supported, use the default generalizer(MapGeneralizer)", generic)
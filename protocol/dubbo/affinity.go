@@ -0,0 +1,48 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+// pinAffinityKey pins the invocation to the pooled connection selected by its affinity key, if it has one.
+// The key is handed to the remoting client by the attribute constant.AFFINITY_KEY.
+func pinAffinityKey(ctx context.Context, inv *invocation.RPCInvocation) {
+	if key := getAffinityKey(ctx, inv); len(key) > 0 {
+		inv.SetAttribute(constant.AFFINITY_KEY, key)
+	}
+}
+
+// getAffinityKey returns the affinity key of the invocation, the attachment takes precedence over the context
+func getAffinityKey(ctx context.Context, inv *invocation.RPCInvocation) string {
+	if key := inv.AttachmentsByKey(constant.AFFINITY_KEY, ""); len(key) > 0 {
+		return key
+	}
+	if ctx != nil {
+		if key, ok := ctx.Value(constant.AFFINITY_CTX_KEY).(string); ok {
+			return key
+		}
+	}
+	return ""
+}
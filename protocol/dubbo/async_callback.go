@@ -0,0 +1,48 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"time"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// callbackWithContext passes @ctx of the invocation to the async @callBack, which runs after Invoke returns
+// and may skip its work once @ctx is done. With async.callback.inherit.timeout, @ctx is done once @timeout
+// elapses as well.
+func (di *DubboInvoker) callbackWithContext(ctx context.Context, url *common.URL, timeout time.Duration,
+	callBack func(response common.CallbackResponse)) func(response common.CallbackResponse) {
+	cancel := context.CancelFunc(func() {})
+	if url.GetParamBool(constant.ASYNC_CALLBACK_INHERIT_TIMEOUT_KEY, false) {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	return func(response common.CallbackResponse) {
+		defer cancel()
+		if r, ok := response.(remoting.AsyncCallbackResponse); ok {
+			r.Ctx = ctx
+			response = r
+		}
+		callBack(response)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/logger"
+	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+// attachmentSize returns the approximate size in bytes of the attachments, counting both keys and values.
+func attachmentSize(attachments map[string]interface{}) int {
+	size := 0
+	for k, v := range attachments {
+		size += len(k) + attachmentValueSize(v)
+	}
+	return size
+}
+
+func attachmentValueSize(v interface{}) int {
+	switch value := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(value)
+	case []byte:
+		return len(value)
+	case []string:
+		size := 0
+		for _, s := range value {
+			size += len(s)
+		}
+		return size
+	default:
+		return len(fmt.Sprint(value))
+	}
+}
+
+// largestAttachments returns the @n largest attachments as "key(size)" in descending order of their sizes
+func largestAttachments(attachments map[string]interface{}, n int) string {
+	keys := make([]string, 0, len(attachments))
+	for k := range attachments {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		si, sj := len(keys[i])+attachmentValueSize(attachments[keys[i]]), len(keys[j])+attachmentValueSize(attachments[keys[j]])
+		if si != sj {
+			return si > sj
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	largest := make([]string, 0, len(keys))
+	for _, k := range keys {
+		largest = append(largest, fmt.Sprintf("%s(%d)", k, len(k)+attachmentValueSize(attachments[k])))
+	}
+	return strings.Join(largest, ", ")
+}
+
+// isReservedAttachment reports whether @key is set by the invoker itself, which is never truncated
+func isReservedAttachment(key string) bool {
+	if key == constant.PATH_KEY || key == constant.CORRELATION_ID_KEY {
+		return true
+	}
+	for _, k := range attachmentKey {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// canonicalize the keys of the reserved attachments like timeout and version, the others are kept
+	attachmentKeyCasingReserved = "reserved"
+	// lowercase all the attachment keys
+	attachmentKeyCasingLower = "lower"
+	// keep the attachment keys as they are
+	attachmentKeyCasingNone = "none"
+)
+
+// normalizeAttachmentKeys rewrites the attachment keys of @inv by @casing, so that e.g. "TIMEOUT" is sent as "timeout"
+// which the providers expect. The value of the key already in the normalized form takes precedence.
+func normalizeAttachmentKeys(inv *invocation_impl.RPCInvocation, casing string) {
+	if casing == attachmentKeyCasingNone {
+		return
+	}
+	attachments := inv.Attachments()
+	for k, v := range attachments {
+		normalized := strings.ToLower(k)
+		if normalized == k || (casing != attachmentKeyCasingLower && !isReservedAttachment(normalized)) {
+			continue
+		}
+		delete(attachments, k)
+		if _, ok := attachments[normalized]; !ok {
+			attachments[normalized] = v
+		}
+	}
+}
+
+// truncateAttachments removes the largest attachments of @inv other than the reserved ones
+// until their size is within @threshold, and returns the removed keys
+func truncateAttachments(inv *invocation_impl.RPCInvocation, threshold int64) []string {
+	attachments := inv.Attachments()
+	size := int64(attachmentSize(attachments))
+	var removed []string
+	for size > threshold {
+		largest, largestSize := "", -1
+		for k, v := range attachments {
+			if isReservedAttachment(k) {
+				continue
+			}
+			if kSize := len(k) + attachmentValueSize(v); kSize > largestSize || (kSize == largestSize && k < largest) {
+				largest, largestSize = k, kSize
+			}
+		}
+		if largestSize < 0 {
+			break
+		}
+		delete(attachments, largest)
+		size -= int64(largestSize)
+		removed = append(removed, largest)
+	}
+	return removed
+}
+
+// checkAttachmentSize warns when the attachments of @inv exceed the configured threshold,
+// or rejects the invocation or truncates the attachments if the invoker is configured to do so.
+// It reports whether the threshold is exceeded.
+func (di *DubboInvoker) checkAttachmentSize(inv *invocation_impl.RPCInvocation) (bool, error) {
+	url := di.GetURL()
+	threshold := url.GetParamInt(constant.ATTACHMENT_SIZE_THRESHOLD_KEY, 0)
+	if threshold <= 0 {
+		return false, nil
+	}
+	size := int64(attachmentSize(inv.Attachments()))
+	if size <= threshold {
+		return false, nil
+	}
+	largest := largestAttachments(inv.Attachments(), 3)
+	if url.GetParamBool(constant.ATTACHMENT_SIZE_REJECT_KEY, false) {
+		return true, perrors.Errorf("the attachments of %s.%s are %d bytes, exceeding the threshold of %d bytes, the largest are %s",
+			url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), size, threshold, largest)
+	}
+	if url.GetParamBool(constant.ATTACHMENT_SIZE_TRUNCATE_KEY, false) {
+		removed := truncateAttachments(inv, threshold)
+		logger.Warnf("The attachments of %s.%s are %d bytes, exceeding the threshold of %d bytes, the largest are %s, %v are removed",
+			url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), size, threshold, largest, removed)
+		return true, nil
+	}
+	logger.Warnf("The attachments of %s.%s are %d bytes, exceeding the threshold of %d bytes, the largest are %s",
+		url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), size, threshold, largest)
+	return true, nil
+}
+
+const (
+	// reject the invocation carrying more attachments than the max count
+	attachmentCountReject = "reject"
+	// remove the attachments beyond the max count other than the reserved ones
+	attachmentCountTrim = "trim"
+)
+
+// checkAttachmentCount rejects the invocation carrying more attachments than the configured max count,
+// or trims the attachments beyond the max count if the policy is "trim". The reserved attachments are kept
+// and the others are kept in the order of their keys.
+func (di *DubboInvoker) checkAttachmentCount(inv *invocation_impl.RPCInvocation) error {
+	url := di.GetURL()
+	max := int(url.GetParamInt(constant.ATTACHMENT_COUNT_MAX_KEY, 0))
+	attachments := inv.Attachments()
+	if max <= 0 || len(attachments) <= max {
+		return nil
+	}
+	count := len(attachments)
+	if url.GetParam(constant.ATTACHMENT_COUNT_POLICY_KEY, attachmentCountReject) != attachmentCountTrim {
+		logger.Warnf("The invocation of %s.%s carrying %d attachments is rejected, exceeding the max count of %d",
+			url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), count, max)
+		return perrors.Errorf("the invocation of %s.%s carries %d attachments, exceeding the max count of %d",
+			url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), count, max)
+	}
+	keys := make([]string, 0, count)
+	kept := 0
+	for k := range attachments {
+		if isReservedAttachment(k) {
+			kept++
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var removed []string
+	for _, k := range keys {
+		if kept < max {
+			kept++
+			continue
+		}
+		delete(attachments, k)
+		removed = append(removed, k)
+	}
+	logger.Warnf("The invocation of %s.%s carries %d attachments, exceeding the max count of %d, %v are removed",
+		url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), count, max, removed)
+	return nil
+}
+
+// returnedAttachments returns the attachments @returned by the provider, without the request-only attachments of
+// @inv echoed back. Only the attachment keys listed by attachment.bidirectional travel back with the values echoed,
+// while the attachments the provider returns on its own are kept.
+func (di *DubboInvoker) returnedAttachments(inv *invocation_impl.RPCInvocation, returned map[string]interface{}) map[string]interface{} {
+	if len(returned) == 0 {
+		return returned
+	}
+	url := di.GetURL()
+	bidirectional := make(map[string]struct{})
+	for _, key := range strings.Split(url.GetMethodParam(inv.MethodName(), constant.ATTACHMENT_BIDIRECTIONAL_KEY,
+		url.GetParam(constant.ATTACHMENT_BIDIRECTIONAL_KEY, "")), ",") {
+		if key = strings.TrimSpace(key); len(key) > 0 {
+			bidirectional[key] = struct{}{}
+		}
+	}
+	attachments := make(map[string]interface{}, len(returned))
+	for k, v := range returned {
+		if _, sent := inv.Attachments()[k]; sent {
+			if _, ok := bidirectional[k]; !ok {
+				continue
+			}
+		}
+		attachments[k] = v
+	}
+	return attachments
+}
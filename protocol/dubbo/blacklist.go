@@ -0,0 +1,88 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"sync"
+	"time"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const defaultBlacklistCooldown = "30s"
+
+// endpointFailures is the failure state of an endpoint
+type endpointFailures struct {
+	// the consecutive connection failures
+	failures int
+	// the endpoint is blacklisted until then
+	until time.Time
+}
+
+// endpointBlacklist tracks the connection failures of the endpoints across all the dubbo invokers in the process,
+// an endpoint failing repeatedly is skipped by Refer for a cooldown
+type endpointBlacklist struct {
+	lock      sync.Mutex
+	endpoints map[string]*endpointFailures
+	clock     Clock
+}
+
+var blacklist = newEndpointBlacklist()
+
+func newEndpointBlacklist() *endpointBlacklist {
+	return &endpointBlacklist{endpoints: make(map[string]*endpointFailures), clock: realClock{}}
+}
+
+// record counts the result of a call to the endpoint of @url. The connection errors are counted until they reach
+// blacklist.failures, which blacklists the endpoint for blacklist.cooldown, and any other result resets the count.
+func (b *endpointBlacklist) record(url *common.URL, err error) {
+	threshold := int(url.GetParamInt(constant.BLACKLIST_FAILURES_KEY, 0))
+	if threshold <= 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !remoting.IsConnectionError(err) {
+		delete(b.endpoints, url.Location)
+		return
+	}
+	state, ok := b.endpoints[url.Location]
+	if !ok {
+		state = &endpointFailures{}
+		b.endpoints[url.Location] = state
+	}
+	if state.failures++; state.failures >= threshold {
+		state.failures = 0
+		state.until = b.clock.Now().Add(url.GetParamDuration(constant.BLACKLIST_COOLDOWN_KEY, defaultBlacklistCooldown))
+	}
+}
+
+// isBlacklisted reports whether the endpoint of @url is in its cooldown
+func (b *endpointBlacklist) isBlacklisted(url *common.URL) bool {
+	if url.GetParamInt(constant.BLACKLIST_FAILURES_KEY, 0) <= 0 {
+		return false
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	state, ok := b.endpoints[url.Location]
+	return ok && b.clock.Now().Before(state.until)
+}
@@ -0,0 +1,102 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+import (
+	hessian "github.com/apache/dubbo-go-hessian2"
+
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+// BytesInterceptor sees the serialized bodies of the requests and responses on the wire, below the invoker filters,
+// e.g. to checksum, log or transform them. The frame header is kept by the codec, whose body length is
+// updated to the returned body, so that the interceptors never corrupt the framing.
+type BytesInterceptor interface {
+	// OnRequest is called with the body of the request before it is sent, and returns the body to send
+	OnRequest(body []byte) ([]byte, error)
+	// OnResponse is called with the body of the response before it is decoded, and returns the body to decode
+	OnResponse(body []byte) ([]byte, error)
+}
+
+var (
+	bytesInterceptors     = make(map[string]BytesInterceptor)
+	bytesInterceptorsLock sync.RWMutex
+)
+
+// SetBytesInterceptor registers the interceptor with @name, which is selected by the bytes.interceptor param of the url
+func SetBytesInterceptor(name string, interceptor BytesInterceptor) {
+	bytesInterceptorsLock.Lock()
+	defer bytesInterceptorsLock.Unlock()
+	bytesInterceptors[name] = interceptor
+}
+
+// GetBytesInterceptor returns the interceptor registered with @name, nil if it is absent
+func GetBytesInterceptor(name string) BytesInterceptor {
+	bytesInterceptorsLock.RLock()
+	defer bytesInterceptorsLock.RUnlock()
+	return bytesInterceptors[name]
+}
+
+// interceptFrame replaces the body of @frame with the one returned by @intercept, and updates the body length
+func interceptFrame(frame []byte, intercept func([]byte) ([]byte, error)) ([]byte, error) {
+	body := make([]byte, len(frame)-hessian.HEADER_LENGTH)
+	copy(body, frame[hessian.HEADER_LENGTH:])
+	body, err := intercept(body)
+	if err != nil {
+		return nil, perrors.WithMessage(err, "bytes interceptor")
+	}
+	intercepted := make([]byte, hessian.HEADER_LENGTH, hessian.HEADER_LENGTH+len(body))
+	copy(intercepted, frame[:hessian.HEADER_LENGTH])
+	binary.BigEndian.PutUint32(intercepted[12:], uint32(len(body)))
+	return append(intercepted, body...), nil
+}
+
+// interceptResponse passes the first response frame in @data to the interceptor of its pending request if any.
+// It returns the data to decode, and the length of the original frame consumed, 0 if it is not intercepted.
+func interceptResponse(data []byte) ([]byte, int, error) {
+	if len(data) < hessian.HEADER_LENGTH {
+		return data, 0, nil
+	}
+	length := hessian.HEADER_LENGTH + int(binary.BigEndian.Uint32(data[12:]))
+	if len(data) < length {
+		// waits for the whole frame
+		return data, 0, nil
+	}
+	pending := remoting.GetPendingResponse(remoting.SequenceType(int64(binary.BigEndian.Uint64(data[4:]))))
+	if pending == nil || len(pending.Interceptor) == 0 {
+		return data, 0, nil
+	}
+	interceptor := GetBytesInterceptor(pending.Interceptor)
+	if interceptor == nil {
+		return data, 0, nil
+	}
+	frame, err := interceptFrame(data[:length], interceptor.OnResponse)
+	if err != nil {
+		return nil, length, err
+	}
+	return frame, length, nil
+}
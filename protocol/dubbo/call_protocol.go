@@ -0,0 +1,67 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+)
+
+import (
+	perrors "github.com/pkg/errors"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+// CallProtocol overrides the protocol of a single call, the empty fields are taken from the url of the invoker
+type CallProtocol struct {
+	// Serialization is the name of the serialization registered by RegisterSerialization
+	Serialization string
+	// Compression is the name of the BytesInterceptor compressing the request body and decompressing the
+	// response body, registered by SetBytesInterceptor
+	Compression string
+}
+
+// WithCallProtocol returns the context overriding both the serialization and the compression of the call by @p
+func WithCallProtocol(ctx context.Context, p CallProtocol) context.Context {
+	return context.WithValue(ctx, constant.CALL_PROTOCOL_CTX_KEY, p)
+}
+
+// applyCallProtocol overrides the serialization and the compression of @inv by the protocol bundle in @ctx if any,
+// and returns the url to send @inv with. The compression must be registered, otherwise nothing is overridden.
+func applyCallProtocol(ctx context.Context, url *common.URL, inv *invocation_impl.RPCInvocation) (*common.URL, error) {
+	p, ok := ctx.Value(constant.CALL_PROTOCOL_CTX_KEY).(CallProtocol)
+	if !ok {
+		return url, nil
+	}
+	if len(p.Compression) > 0 {
+		if GetBytesInterceptor(p.Compression) == nil {
+			return url, perrors.Errorf("compression %s is not registered", p.Compression)
+		}
+		url = url.Clone()
+		url.SetParam(constant.BYTES_INTERCEPTOR_KEY, p.Compression)
+	}
+	if len(p.Serialization) > 0 {
+		// the attachment takes precedence over the url param
+		inv.SetAttachments(constant.SERIALIZATION_KEY, p.Serialization)
+	}
+	return url, nil
+}
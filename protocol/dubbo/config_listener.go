@@ -0,0 +1,60 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+)
+
+// ConfigListenerRegistry is where the config listeners are added and removed, e.g. a config_center.DynamicConfiguration
+type ConfigListenerRegistry interface {
+	AddListener(string, config_center.ConfigurationListener, ...config_center.Option)
+	RemoveListener(string, config_center.ConfigurationListener, ...config_center.Option)
+}
+
+// configListener is a config listener added on behalf of the invoker
+type configListener struct {
+	registry ConfigListenerRegistry
+	key      string
+	listener config_center.ConfigurationListener
+	opts     []config_center.Option
+}
+
+// AddConfigListener adds @listener of @key to @registry on behalf of the invoker, which is removed once the invoker
+// is destroyed, e.g. the listener of the dynamic routing rules of the provider. It is not added to the destroyed invoker.
+func (di *DubboInvoker) AddConfigListener(registry ConfigListenerRegistry, key string,
+	listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	di.configListenersLock.Lock()
+	defer di.configListenersLock.Unlock()
+	if di.IsDestroyed() {
+		return
+	}
+	registry.AddListener(key, listener, opts...)
+	di.configListeners = append(di.configListeners, configListener{registry: registry, key: key, listener: listener, opts: opts})
+}
+
+// removeConfigListeners removes the config listeners added on behalf of the invoker
+func (di *DubboInvoker) removeConfigListeners() {
+	di.configListenersLock.Lock()
+	listeners := di.configListeners
+	di.configListeners = nil
+	di.configListenersLock.Unlock()
+	for _, l := range listeners {
+		l.registry.RemoveListener(l.key, l.listener, l.opts...)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"strings"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/common/logger"
+	"dubbo.apache.org/dubbo-go/v3/remoting/getty"
+)
+
+// connectionCompressionProcessor is the name of the ConfigPostProcessor advertising the connection compressions
+const connectionCompressionProcessor = "dubbo-connection-compression"
+
+func init() {
+	extension.SetConfigPostProcessor(connectionCompressionProcessor, &connectionCompressionAdvertiser{})
+}
+
+// connectionCompressionAdvertiser exports the connection compressions the server of a dubbo provider reads
+// in the provider url, against which the consumers negotiate the compression of their connections.
+type connectionCompressionAdvertiser struct{}
+
+// PostProcessReferenceConfig leaves the consumer url as it is.
+func (a *connectionCompressionAdvertiser) PostProcessReferenceConfig(*common.URL) {}
+
+// PostProcessServiceConfig sets CONNECTION_COMPRESSIONS_KEY of the provider url from its server session config.
+func (a *connectionCompressionAdvertiser) PostProcessServiceConfig(url *common.URL) {
+	if url.Protocol != DUBBO {
+		return
+	}
+	if compressions := getty.ServerConnectionCompressions(url.Protocol); len(compressions) > 0 {
+		url.SetParam(constant.CONNECTION_COMPRESSIONS_KEY, strings.Join(compressions, ","))
+	}
+}
+
+// negotiateConnectionCompression returns the compression of the connection to the provider of @url. It is the one
+// asked by the consumer if the provider advertises it, otherwise the one the provider advertises, as its server
+// reads nothing else, or none if the provider advertises none.
+// The connection is shared by the services of the provider, so it is negotiated by the first one connecting.
+func negotiateConnectionCompression(url *common.URL) string {
+	compression := url.GetParam(constant.CONNECTION_COMPRESSION_KEY, "")
+	var advertised []string
+	for _, supported := range strings.Split(url.GetParam(constant.CONNECTION_COMPRESSIONS_KEY, ""), ",") {
+		if supported = strings.TrimSpace(supported); !getty.SupportsConnectionCompression(supported) {
+			continue
+		}
+		if supported == compression {
+			return compression
+		}
+		advertised = append(advertised, supported)
+	}
+	if len(advertised) == 0 {
+		if len(compression) > 0 {
+			logger.Warnf("The provider %s doesn't support the connection compression %s, the connection is not compressed",
+				url.Location, compression)
+		}
+		return ""
+	}
+	if len(compression) > 0 {
+		logger.Warnf("The provider %s doesn't support the connection compression %s, the connection is compressed by %s",
+			url.Location, compression, advertised[0])
+	}
+	return advertised[0]
+}
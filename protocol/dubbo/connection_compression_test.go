@@ -0,0 +1,142 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/extension"
+	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/getty"
+)
+
+func TestNegotiateConnectionCompression(t *testing.T) {
+	negotiate := func(params string) string {
+		url, err := common.NewURL(mockInvokerUrl + params)
+		assert.NoError(t, err)
+		return negotiateConnectionCompression(url)
+	}
+
+	// off by default
+	assert.Equal(t, "", negotiate(""))
+
+	// advertised by the provider
+	assert.Equal(t, "zip", negotiate("&"+constant.CONNECTION_COMPRESSION_KEY+"=zip&"+constant.CONNECTION_COMPRESSIONS_KEY+"=lz4,zip"))
+
+	// falls back to none if the provider advertises none
+	assert.Equal(t, "", negotiate("&"+constant.CONNECTION_COMPRESSION_KEY+"=zip"))
+	// or none supported
+	assert.Equal(t, "", negotiate("&"+constant.CONNECTION_COMPRESSION_KEY+"=lz4&"+constant.CONNECTION_COMPRESSIONS_KEY+"=lz4"))
+
+	// follows the provider reading the compressed connections only
+	assert.Equal(t, "zip", negotiate("&"+constant.CONNECTION_COMPRESSIONS_KEY+"=zip"))
+	assert.Equal(t, "zip", negotiate("&"+constant.CONNECTION_COMPRESSION_KEY+"=snappy&"+constant.CONNECTION_COMPRESSIONS_KEY+"=zip"))
+}
+
+func TestConnectionCompressionEndToEnd(t *testing.T) {
+	rootConfig := *config.GetRootConfig()
+	defer config.SetRootConfig(rootConfig)
+
+	for _, c := range []struct {
+		name       string
+		server     string
+		asked      string
+		negotiated string
+	}{
+		{name: "fallback", server: "", asked: "zip", negotiated: ""},
+		{name: "asked", server: "zip", asked: "zip", negotiated: "zip"},
+		{name: "provider", server: "zip", asked: "", negotiated: "zip"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			params := map[string]interface{}{}
+			if len(c.server) > 0 {
+				params["connection-compression"] = c.server
+			}
+			config.SetRootConfig(config.RootConfig{
+				Application: &config.ApplicationConfig{},
+				Protocols:   map[string]*config.ProtocolConfig{DUBBO: {Params: params}},
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(t, err)
+			port := listener.Addr().(*net.TCPAddr).Port
+			assert.NoError(t, listener.Close())
+
+			// the provider exports the compression of its server
+			url, err := common.NewURL(fmt.Sprintf("dubbo://127.0.0.1:%d/com.ikurento.user.UserProvider?"+
+				"interface=com.ikurento.user.UserProvider&timeout=3000", port))
+			assert.NoError(t, err)
+			extension.GetConfigPostProcessor(connectionCompressionProcessor).PostProcessServiceConfig(url)
+			assert.Equal(t, c.server, url.GetParam(constant.CONNECTION_COMPRESSIONS_KEY, ""))
+
+			server := getty.NewServer(url, func(inv *invocation.RPCInvocation) protocol.RPCResult {
+				return protocol.RPCResult{Rest: "hello " + inv.Arguments()[0].(string)}
+			})
+			server.Start()
+			defer server.Stop()
+
+			consumerURL := url.Clone()
+			consumerURL.SetParam(constant.CONNECTION_COMPRESSION_KEY, c.asked)
+			assert.Equal(t, c.negotiated, negotiateConnectionCompression(consumerURL))
+			client := newGettyExchangeClient(consumerURL)
+			if !assert.NotNil(t, client) {
+				return
+			}
+			invoker := NewDubboInvoker(consumerURL, client)
+			defer invoker.Destroy()
+
+			var reply string
+			inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("SayHello"),
+				invocation.WithArguments([]interface{}{"dubbo"}), invocation.WithReply(&reply))
+			res := invoker.Invoke(context.Background(), inv)
+			assert.NoError(t, res.Error())
+			assert.Equal(t, "hello dubbo", reply)
+
+			if len(c.negotiated) > 0 {
+				// the server compressing the connections doesn't read the ones not negotiated
+				plainURL := consumerURL.Clone()
+				plainURL.SetParam(constant.TIMEOUT_KEY, "500")
+				plain := remoting.NewExchangeClient(plainURL, getty.NewClient(getty.Options{
+					ConnectTimeout: time.Second,
+					RequestTimeout: time.Second,
+				}), time.Second, false)
+				if !assert.NotNil(t, plain) {
+					return
+				}
+				plainInvoker := NewDubboInvoker(plainURL, plain)
+				defer plainInvoker.Destroy()
+				assert.Error(t, plainInvoker.Invoke(context.Background(), inv).Error())
+			}
+		})
+	}
+}
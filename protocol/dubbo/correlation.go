@@ -0,0 +1,81 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"sync"
+)
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+var (
+	correlationCtxKey        interface{} = constant.CORRELATION_ID_CTX_KEY
+	correlationAttachmentKey             = constant.CORRELATION_ID_KEY
+	correlationKeysLock      sync.RWMutex
+)
+
+// SetCorrelationKeys customizes the context key the correlation id is read from,
+// and the attachment carrying it to the provider and back in the result.
+func SetCorrelationKeys(ctxKey interface{}, attachmentKey string) {
+	correlationKeysLock.Lock()
+	defer correlationKeysLock.Unlock()
+	correlationCtxKey = ctxKey
+	correlationAttachmentKey = attachmentKey
+}
+
+func getCorrelationKeys() (interface{}, string) {
+	correlationKeysLock.RLock()
+	defer correlationKeysLock.RUnlock()
+	return correlationCtxKey, correlationAttachmentKey
+}
+
+// appendCorrelationID attaches the correlation id of the call, which is taken from the attachment,
+// then the context, and generated if both are absent. The id is returned.
+func appendCorrelationID(ctx context.Context, inv *invocation_impl.RPCInvocation) string {
+	ctxKey, attachmentKey := getCorrelationKeys()
+	id := inv.AttachmentsByKey(attachmentKey, "")
+	if len(id) == 0 && ctx != nil {
+		id, _ = ctx.Value(ctxKey).(string)
+	}
+	if len(id) == 0 {
+		if u, err := uuid.NewV4(); err == nil {
+			id = u.String()
+		}
+	}
+	inv.SetAttachments(attachmentKey, id)
+	return id
+}
+
+// withCorrelationID echoes the correlation id in the attachments of @result
+func withCorrelationID(result protocol.Result, id string) protocol.Result {
+	_, attachmentKey := getCorrelationKeys()
+	if result.Attachments() == nil {
+		result.SetAttachments(make(map[string]interface{}, 1))
+	}
+	result.AddAttachment(attachmentKey, id)
+	return result
+}
@@ -0,0 +1,71 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"reflect"
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/logger"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+)
+
+// degradations stores the fallback values keyed by interface and method
+var degradations sync.Map
+
+// SetDegradation registers @value as the fallback value of @method of @interfaceName, which is returned instead of
+// the error once the call fails, if the degradation is enabled for the method by the url param degradation.
+// The value must be assignable to the reply of the method.
+func SetDegradation(interfaceName string, method string, value interface{}) {
+	degradations.Store(validatorKey(interfaceName, method), value)
+}
+
+// RemoveDegradation removes the fallback value of @method of @interfaceName
+func RemoveDegradation(interfaceName string, method string) {
+	degradations.Delete(validatorKey(interfaceName, method))
+}
+
+// degrade replaces the error of @result by the fallback value of the method of @inv, which is set to the reply,
+// if the degradation is enabled for the method and the value is registered. Otherwise @result is returned as it is.
+func (di *DubboInvoker) degrade(inv protocol.Invocation, result protocol.Result) protocol.Result {
+	if result.Error() == nil {
+		return result
+	}
+	url := di.GetURL()
+	if !url.GetMethodParamBool(inv.MethodName(), constant.DEGRADATION_KEY, url.GetParamBool(constant.DEGRADATION_KEY, false)) {
+		return result
+	}
+	interfaceName := url.GetParam(constant.INTERFACE_KEY, "")
+	value, ok := degradations.Load(validatorKey(interfaceName, inv.MethodName()))
+	if !ok {
+		return result
+	}
+	if reply := reflect.ValueOf(inv.Reply()); value != nil && reply.Kind() == reflect.Ptr && !reply.IsNil() {
+		if !reflect.TypeOf(value).AssignableTo(reply.Elem().Type()) {
+			logger.Warnf("the fallback value %T of %s.%s is not assignable to the reply %T, not degraded",
+				value, interfaceName, inv.MethodName(), inv.Reply())
+			return result
+		}
+		reply.Elem().Set(reflect.ValueOf(value))
+	}
+	logger.Warnf("the call of %s.%s failed with %v, degraded to the fallback value", interfaceName, inv.MethodName(), result.Error())
+	return &protocol.RPCResult{Rest: value, Attrs: result.Attachments()}
+}
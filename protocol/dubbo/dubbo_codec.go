@@ -19,6 +19,7 @@ package dubbo
 import (
 	"bytes"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -77,11 +78,13 @@ func (c *DubboCodec) EncodeRequest(request *remoting.Request) (*bytes.Buffer, er
 	svc.Timeout = time.Duration(timeout)
 
 	header := impl.DubboHeader{}
-	serialization := invocation.AttachmentsByKey(constant.SERIALIZATION_KEY, constant.HESSIAN2_SERIALIZATION)
-	if serialization == constant.PROTOBUF_SERIALIZATION {
-		header.SerialID = constant.S_Proto
-	} else {
-		header.SerialID = constant.S_Hessian2
+	// the serialization of the call takes precedence over the one of the invoker
+	serialization := invocation.AttachmentsByKey(constant.SERIALIZATION_KEY, request.Serialization)
+	if len(serialization) == 0 {
+		serialization = constant.HESSIAN2_SERIALIZATION
+	}
+	if header.SerialID, err = impl.GetSerializationID(serialization); err != nil {
+		return nil, perrors.WithStack(err)
 	}
 	header.ID = request.ID
 	if request.TwoWay {
@@ -102,7 +105,19 @@ func (c *DubboCodec) EncodeRequest(request *remoting.Request) (*bytes.Buffer, er
 		return nil, perrors.WithStack(err)
 	}
 
-	return pkg.Marshal()
+	buf, err := pkg.Marshal()
+	if err != nil || len(request.Interceptor) == 0 {
+		return buf, err
+	}
+	interceptor := GetBytesInterceptor(request.Interceptor)
+	if interceptor == nil {
+		return buf, nil
+	}
+	frame, err := interceptFrame(buf.Bytes(), interceptor.OnRequest)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewBuffer(frame), nil
 }
 
 // encode heartbeat request
@@ -169,10 +184,18 @@ func (c *DubboCodec) Decode(data []byte) (remoting.DecodeResult, int, error) {
 		return remoting.DecodeResult{IsRequest: true, Result: req}, len, perrors.WithStack(err)
 	}
 
+	data, frameLen, err := interceptResponse(data)
+	if err != nil {
+		return remoting.DecodeResult{}, 0, perrors.WithStack(err)
+	}
 	resp, len, err := c.decodeResponse(data)
 	if err != nil {
 		return remoting.DecodeResult{}, len, perrors.WithStack(err)
 	}
+	if frameLen > 0 {
+		// the length of the frame on the wire rather than the intercepted one
+		len = frameLen
+	}
 	return remoting.DecodeResult{IsRequest: false, Result: resp}, len, perrors.WithStack(err)
 }
 
@@ -235,7 +258,16 @@ func (c *DubboCodec) decodeRequest(data []byte) (*remoting.Request, int, error)
 func (c *DubboCodec) decodeResponse(data []byte) (*remoting.Response, int, error) {
 	buf := bytes.NewBuffer(data)
 	pkg := impl.NewDubboPackage(buf)
-	err := pkg.Unmarshal()
+	err := pkg.ReadHeader()
+	if err == nil {
+		if response := rejectOversizedResponse(pkg.Header); response != nil {
+			return response, hessian.HEADER_LENGTH + pkg.Header.BodyLen, nil
+		}
+		if err = pkg.Unmarshal(); err != nil {
+			pkg = unmarshalWithFallback(data, pkg, err)
+			err = pkg.Err
+		}
+	}
 	if err != nil {
 		originErr := perrors.Cause(err)
 		// if the data is very big, so the receive need much times.
@@ -284,3 +316,54 @@ func (c *DubboCodec) decodeResponse(data []byte) (*remoting.Response, int, error
 
 	return response, hessian.HEADER_LENGTH + pkg.Header.BodyLen, nil
 }
+
+// rejectOversizedResponse returns an error response if the body described by @header exceeds the max size
+// of its pending request, so that the body is skipped without being deserialized.
+func rejectOversizedResponse(header impl.DubboHeader) *remoting.Response {
+	if header.Type&impl.PackageHeartbeat != 0x00 || header.Type&impl.PackageResponse == 0x00 {
+		return nil
+	}
+	pending := remoting.GetPendingResponse(remoting.SequenceType(header.ID))
+	if pending == nil || pending.MaxSize <= 0 || header.BodyLen <= pending.MaxSize {
+		return nil
+	}
+	err := perrors.Errorf("the response body of %d bytes exceeds the max size of %d bytes", header.BodyLen, pending.MaxSize)
+	logger.Warnf("Reject the response %d: %v", header.ID, err)
+	return &remoting.Response{
+		ID:       header.ID,
+		SerialID: header.SerialID,
+		Status:   header.ResponseStatus,
+		Error:    err,
+		Result:   &protocol.RPCResult{Err: err},
+	}
+}
+
+// unmarshalWithFallback decodes @data again with the fallback serializations of the pending request in order
+// once @pkg fails to be decoded with @cause. It returns the first package decoded, or @pkg with its Err set to
+// @cause if all of them fail.
+func unmarshalWithFallback(data []byte, pkg *impl.DubboPackage, cause error) *impl.DubboPackage {
+	pkg.Err = cause
+	if originErr := perrors.Cause(cause); originErr == hessian.ErrHeaderNotEnough || originErr == hessian.ErrBodyNotEnough {
+		return pkg
+	}
+	pending := remoting.GetPendingResponse(remoting.SequenceType(pkg.Header.ID))
+	if pending == nil {
+		return pkg
+	}
+	for _, name := range pending.FallbackSerializations {
+		serializer, err := impl.GetSerializerByName(strings.TrimSpace(name))
+		if err != nil {
+			logger.Warnf("Skip the fallback serialization: %v", err)
+			continue
+		}
+		fallback := impl.NewDubboPackage(bytes.NewBuffer(data))
+		fallback.SetSerializer(serializer)
+		if err = fallback.Unmarshal(); err != nil {
+			logger.Warnf("Failed to decode the response %d with the fallback serialization %s: %v", pkg.Header.ID, name, err)
+			continue
+		}
+		logger.Warnf("Decode the response %d with the fallback serialization %s, because of %v", pkg.Header.ID, name, cause)
+		return fallback
+	}
+	return pkg
+}
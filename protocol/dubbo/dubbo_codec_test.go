@@ -0,0 +1,208 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+import (
+	hessian "github.com/apache/dubbo-go-hessian2"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	"dubbo.apache.org/dubbo-go/v3/protocol/dubbo/impl"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+func encodeMockResponse(t *testing.T, id int64, rest interface{}) []byte {
+	buf, err := (&DubboCodec{}).EncodeResponse(&remoting.Response{
+		ID:       id,
+		SerialID: constant.S_Hessian2,
+		Status:   hessian.Response_OK,
+		Result:   protocol.RPCResult{Rest: rest},
+	})
+	assert.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestDubboCodecResponseMaxSize(t *testing.T) {
+	codec := &DubboCodec{}
+
+	// unlimited by default
+	id := remoting.SequenceID()
+	pending := remoting.NewPendingResponse(id)
+	pending.Reply = new(string)
+	remoting.AddPendingResponse(pending)
+	data := encodeMockResponse(t, id, strings.Repeat("x", 1024))
+	result, length, err := codec.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), length)
+	response := result.Result.(*remoting.Response)
+	assert.NoError(t, response.Error)
+	assert.Equal(t, strings.Repeat("x", 1024), *response.Result.(*protocol.RPCResult).Rest.(*string))
+
+	// the oversized body is skipped without being decoded
+	id = remoting.SequenceID()
+	pending = remoting.NewPendingResponse(id)
+	pending.Reply = new(string)
+	pending.MaxSize = 512
+	remoting.AddPendingResponse(pending)
+	data = encodeMockResponse(t, id, strings.Repeat("x", 1024))
+	result, length, err = codec.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), length)
+	response = result.Result.(*remoting.Response)
+	assert.Error(t, response.Error)
+	assert.Contains(t, response.Error.Error(), "exceeds the max size of 512 bytes")
+	assert.Equal(t, response.Error, response.Result.(*protocol.RPCResult).Err)
+	assert.Equal(t, "", *pending.Reply.(*string))
+}
+
+// rawSerializer takes the whole body as the string reply
+type rawSerializer struct{}
+
+func (s rawSerializer) Marshal(impl.DubboPackage) ([]byte, error) {
+	return nil, nil
+}
+
+func (s rawSerializer) Unmarshal(body []byte, p *impl.DubboPackage) error {
+	response := impl.EnsureResponsePayload(p.Body)
+	*response.RspObj.(*string) = string(body)
+	return nil
+}
+
+func TestDubboCodecFallbackSerialization(t *testing.T) {
+	impl.SetSerializer("raw", rawSerializer{})
+	codec := &DubboCodec{}
+	// the body is not a hessian2 response, which misses the value
+	encoder := hessian.NewEncoder()
+	assert.NoError(t, encoder.Encode(impl.RESPONSE_VALUE))
+	body := encoder.Buffer()
+	newResponse := func(id int64) []byte {
+		header := []byte{impl.MAGIC_HIGH, impl.MAGIC_LOW, constant.S_Hessian2, hessian.Response_OK}
+		header = append(header, make([]byte, 12)...)
+		binary.BigEndian.PutUint64(header[4:], uint64(id))
+		binary.BigEndian.PutUint32(header[12:], uint32(len(body)))
+		return append(header, body...)
+	}
+
+	// no fallback
+	id := remoting.SequenceID()
+	pending := remoting.NewPendingResponse(id)
+	pending.Reply = new(string)
+	remoting.AddPendingResponse(pending)
+	_, _, err := codec.Decode(newResponse(id))
+	assert.Error(t, err)
+
+	id = remoting.SequenceID()
+	pending = remoting.NewPendingResponse(id)
+	pending.Reply = new(string)
+	pending.FallbackSerializations = []string{"msgpack", " raw"}
+	remoting.AddPendingResponse(pending)
+	data := newResponse(id)
+	result, length, err := codec.Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), length)
+	response := result.Result.(*remoting.Response)
+	assert.NoError(t, response.Error)
+	assert.Equal(t, string(body), *pending.Reply.(*string))
+}
+
+// reverseInterceptor reverses the bodies, and records the ones it sees
+type reverseInterceptor struct {
+	requests  [][]byte
+	responses [][]byte
+}
+
+func reverse(body []byte) []byte {
+	reversed := make([]byte, len(body))
+	for i, b := range body {
+		reversed[len(body)-1-i] = b
+	}
+	return reversed
+}
+
+func (r *reverseInterceptor) OnRequest(body []byte) ([]byte, error) {
+	r.requests = append(r.requests, body)
+	return append(reverse(body), 'x'), nil
+}
+
+func (r *reverseInterceptor) OnResponse(body []byte) ([]byte, error) {
+	r.responses = append(r.responses, body)
+	return reverse(body[:len(body)-1]), nil
+}
+
+func TestDubboCodecBytesInterceptor(t *testing.T) {
+	interceptor := &reverseInterceptor{}
+	SetBytesInterceptor("reverse", interceptor)
+	codec := &DubboCodec{}
+	var inv protocol.Invocation = newMockInvocation(map[string]interface{}{constant.PATH_KEY: "UserProvider"})
+	newRequest := func(name string) *remoting.Request {
+		request := remoting.NewRequest("2.0.2")
+		request.ID = 1
+		request.TwoWay = true
+		request.Data = &inv
+		request.Interceptor = name
+		return request
+	}
+
+	// the request bytes are passed to the interceptor, and the frame length follows the returned body
+	buf, err := codec.EncodeRequest(newRequest(""))
+	assert.NoError(t, err)
+	plain := buf.Bytes()
+	buf, err = codec.EncodeRequest(newRequest("reverse"))
+	assert.NoError(t, err)
+	intercepted := buf.Bytes()
+	assert.Len(t, interceptor.requests, 1)
+	// the attachments are encoded in any order
+	assert.Len(t, interceptor.requests[0], len(plain)-hessian.HEADER_LENGTH)
+	assert.Equal(t, plain[:12], intercepted[:12])
+	assert.Equal(t, uint32(len(plain)-hessian.HEADER_LENGTH+1), binary.BigEndian.Uint32(intercepted[12:]))
+	assert.Equal(t, append(reverse(interceptor.requests[0]), 'x'), intercepted[hessian.HEADER_LENGTH:])
+
+	// an unknown interceptor is ignored
+	buf, err = codec.EncodeRequest(newRequest("absent"))
+	assert.NoError(t, err)
+	assert.Equal(t, plain[:16], buf.Bytes()[:16])
+	assert.Len(t, interceptor.requests, 1)
+
+	// the response bytes are restored by the interceptor before being decoded
+	id := remoting.SequenceID()
+	pending := remoting.NewPendingResponse(id)
+	pending.Reply = new(string)
+	pending.Interceptor = "reverse"
+	remoting.AddPendingResponse(pending)
+	frame, err := interceptFrame(encodeMockResponse(t, id, "hello"), func(body []byte) ([]byte, error) {
+		return append(reverse(body), 'x'), nil
+	})
+	assert.NoError(t, err)
+	result, length, err := codec.Decode(frame)
+	assert.NoError(t, err)
+	assert.Equal(t, len(frame), length)
+	assert.Len(t, interceptor.responses, 1)
+	response := result.Result.(*remoting.Response)
+	assert.NoError(t, response.Error)
+	assert.Equal(t, "hello", *pending.Reply.(*string))
+}
@@ -19,6 +19,8 @@ package dubbo
 
 import (
 	"context"
+	"errors"
+	"math/rand"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,6 +29,10 @@ import (
 
 import (
 	"github.com/opentracing/opentracing-go"
+
+	perrors "github.com/pkg/errors"
+
+	"go.uber.org/atomic"
 )
 
 import (
@@ -35,6 +41,7 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	"dubbo.apache.org/dubbo-go/v3/config"
 	"dubbo.apache.org/dubbo-go/v3/protocol"
+	"dubbo.apache.org/dubbo-go/v3/protocol/dubbo/impl"
 	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
@@ -53,6 +60,25 @@ type DubboInvoker struct {
 	quitOnce    sync.Once
 	// timeout for service(interface) level.
 	timeout time.Duration
+	// the codec to inject the trace context into attachments.
+	traceCodec TraceContextCodec
+	// pins the calls with the same affinity key to the same pooled connection.
+	affinity bool
+	// dial creates the client at the first call of the lazy invoker, and again after the connection is recycled.
+	dial func(url *common.URL) *remoting.ExchangeClient
+	// whether the client has been created, false for the lazy invoker until the first call.
+	connected atomic.Bool
+	// tells the time to compute the deadlines and latencies.
+	clock Clock
+	// resends the requests failed on the connection, built from the url params send.retries if nil.
+	retryPolicy *common.RetryPolicy
+	// the custom labels from the url params prefixed with label.
+	labels map[string]string
+	// the stats of the calls, nil if they are not tracked.
+	stats *invokerStats
+	// the config listeners added on behalf of the invoker, removed once it is destroyed.
+	configListenersLock sync.Mutex
+	configListeners     []configListener
 }
 
 // NewDubboInvoker constructor
@@ -65,11 +91,139 @@ func NewDubboInvoker(url *common.URL, client *remoting.ExchangeClient) *DubboInv
 		clientGuard: &sync.RWMutex{},
 		client:      client,
 		timeout:     timeout,
+		traceCodec:  GetTraceContextCodec(url.GetParam(constant.TRACE_CODEC_KEY, "")),
+		clock:       realClock{},
+		labels:      protocol.LabelsOfURL(url),
+		dial:        getExchangeClient,
+	}
+	di.connected.Store(client != nil)
+	if url.GetParamBool(constant.STATS_ENABLED_KEY, true) {
+		di.stats = &invokerStats{}
 	}
+	di.affinity = url.GetParamInt(constant.AFFINITY_CONNECTIONS_KEY, 0) > 0
+	addActiveInvoker(di)
 
 	return di
 }
 
+// SetClock replaces the wall clock telling the time of the deadlines and latencies, nil restores the wall clock
+func (di *DubboInvoker) SetClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+	di.clock = clock
+}
+
+// SetRetryPolicy replaces the policy resending the requests failed on the connection, nil restores the one
+// built from the url params. Only the connection errors are retried if the policy has no Retryable.
+func (di *DubboInvoker) SetRetryPolicy(policy *common.RetryPolicy) {
+	di.retryPolicy = policy
+}
+
+// sendRetryPolicy returns the policy resending the requests of @methodName, the default one retries
+// send.retries times at once, which can be overridden by the method level url param methods.<method>.send.retries.
+// The retries take the budget shared by all the invokers unless the policy has its own, see SetRetryBudget.
+func (di *DubboInvoker) sendRetryPolicy(methodName string) *common.RetryPolicy {
+	policy := di.retryPolicy
+	if policy == nil {
+		policy = &common.RetryPolicy{
+			MaxAttempts: int(di.GetURL().GetMethodParamInt64(methodName, constant.SEND_RETRIES_KEY, 0)) + 1,
+			BaseDelay:   di.GetURL().GetParamDuration(constant.SEND_RETRY_DELAY_KEY, "0s"),
+		}
+	}
+	retryPolicy := *policy
+	if retryPolicy.Retryable == nil {
+		retryPolicy.Retryable = remoting.IsConnectionError
+		if exceptions := di.GetURL().GetMethodParam(methodName, constant.RETRY_EXCEPTIONS_KEY,
+			di.GetURL().GetParam(constant.RETRY_EXCEPTIONS_KEY, "")); len(exceptions) > 0 {
+			classNames := strings.Split(exceptions, ",")
+			retryPolicy.Retryable = func(err error) bool {
+				return remoting.IsConnectionError(err) || isRetryableException(err, classNames)
+			}
+		}
+	}
+	if retryPolicy.Budget == nil {
+		retryPolicy.Budget = getRetryBudget()
+	}
+	return &retryPolicy
+}
+
+// the retry budget if the consumer config has none, which is generous enough not to throttle the sporadic retries
+const (
+	defaultRetryBudgetRate  = 100
+	defaultRetryBudgetBurst = 1000
+)
+
+var (
+	retryBudgetLock sync.Mutex
+	retryBudget     common.RetryBudget
+)
+
+// SetRetryBudget replaces the budget shared by the retries of all the dubbo invokers in the process,
+// nil restores the one built from the retry budget of the consumer config
+func SetRetryBudget(budget common.RetryBudget) {
+	retryBudgetLock.Lock()
+	defer retryBudgetLock.Unlock()
+	retryBudget = budget
+}
+
+func getRetryBudget() common.RetryBudget {
+	retryBudgetLock.Lock()
+	defer retryBudgetLock.Unlock()
+	if retryBudget == nil {
+		rate, burst := float64(defaultRetryBudgetRate), defaultRetryBudgetBurst
+		if consumerConfig := config.GetConsumerConfig(); consumerConfig != nil {
+			if consumerConfig.RetryBudgetRate > 0 {
+				rate = consumerConfig.RetryBudgetRate
+			}
+			if consumerConfig.RetryBudgetBurst > 0 {
+				burst = consumerConfig.RetryBudgetBurst
+			}
+		}
+		retryBudget = common.NewTokenBucketRetryBudget(rate, burst)
+	}
+	return retryBudget
+}
+
+// isRetryableException reports whether @err is the exception returned by the provider of one of @classNames,
+// which are either the full or the simple java class names
+func isRetryableException(err error, classNames []string) bool {
+	var throwable interface{ JavaClassName() string }
+	if !errors.As(err, &throwable) {
+		return false
+	}
+	className := throwable.JavaClassName()
+	simpleName := className[strings.LastIndex(className, ".")+1:]
+	for _, name := range classNames {
+		if name = strings.TrimSpace(name); name == className || name == simpleName {
+			return true
+		}
+	}
+	return false
+}
+
+// newLazyDubboInvoker creates the invoker without a client, which is created by @dial at the first call
+func newLazyDubboInvoker(url *common.URL, dial func(url *common.URL) *remoting.ExchangeClient) *DubboInvoker {
+	di := NewDubboInvoker(url, nil)
+	di.dial = dial
+	return di
+}
+
+// connect creates the client of the lazy or recycled invoker if it has not been created
+func (di *DubboInvoker) connect() {
+	if di.connected.Load() {
+		return
+	}
+	di.clientGuard.Lock()
+	defer di.clientGuard.Unlock()
+	if di.connected.Load() || !di.BaseInvoker.IsAvailable() {
+		return
+	}
+	if di.client = di.dial(di.GetURL()); di.client != nil {
+		di.connected.Store(true)
+	}
+}
+
 func (di *DubboInvoker) setClient(client *remoting.ExchangeClient) {
 	di.clientGuard.Lock()
 	defer di.clientGuard.Unlock()
@@ -84,8 +238,12 @@ func (di *DubboInvoker) getClient() *remoting.ExchangeClient {
 	return di.client
 }
 
-// Invoke call remoting.
+// Invoke call remoting. The failed call is degraded to the fallback value of the method if it is enabled, see SetDegradation
 func (di *DubboInvoker) Invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
+	return di.degrade(invocation, di.invoke(ctx, invocation))
+}
+
+func (di *DubboInvoker) invoke(ctx context.Context, invocation protocol.Invocation) protocol.Result {
 	var (
 		err    error
 		result protocol.RPCResult
@@ -98,6 +256,7 @@ func (di *DubboInvoker) Invoke(ctx context.Context, invocation protocol.Invocati
 		return &result
 	}
 
+	di.connect()
 	di.clientGuard.RLock()
 	defer di.clientGuard.RUnlock()
 
@@ -116,6 +275,12 @@ func (di *DubboInvoker) Invoke(ctx context.Context, invocation protocol.Invocati
 	}
 
 	inv := invocation.(*invocation_impl.RPCInvocation)
+	// validate the arguments before paying for serialization and network
+	if validator := getInvokeValidator(di.GetURL().GetParam(constant.INTERFACE_KEY, ""), inv.MethodName()); validator != nil {
+		if result.Err = validator(inv.Arguments()); result.Err != nil {
+			return &result
+		}
+	}
 	// init param
 	inv.SetAttachments(constant.PATH_KEY, di.GetURL().GetParam(constant.INTERFACE_KEY, ""))
 	for _, k := range attachmentKey {
@@ -125,61 +290,277 @@ func (di *DubboInvoker) Invoke(ctx context.Context, invocation protocol.Invocati
 	}
 
 	// put the ctx into attachment
-	di.appendCtx(ctx, inv)
+	if result.Err = di.appendCtx(ctx, inv); result.Err != nil {
+		return &result
+	}
+	correlationID := appendCorrelationID(ctx, inv)
+	appendIdempotencyKey(ctx, di.GetURL(), inv)
 
 	url := di.GetURL()
 	// default hessian2 serialization, compatible
 	if url.GetParam(constant.SERIALIZATION_KEY, "") == "" {
 		url.SetParam(constant.SERIALIZATION_KEY, constant.HESSIAN2_SERIALIZATION)
 	}
+	if url, result.Err = applyCallProtocol(ctx, url, inv); result.Err != nil {
+		return &result
+	}
+	// the serialization must be registered by RegisterSerialization
+	serialization := inv.AttachmentsByKey(constant.SERIALIZATION_KEY, url.GetParam(constant.SERIALIZATION_KEY, ""))
+	if _, result.Err = impl.GetSerializerByName(serialization); result.Err != nil {
+		return &result
+	}
 	// async
 	async, err := strconv.ParseBool(inv.AttachmentsByKey(constant.ASYNC_KEY, "false"))
 	if err != nil {
 		logger.Errorf("ParseBool - error: %v", err)
 		async = false
 	}
+	timeout := di.getTimeout(inv)
+	di.appendDeadline(ctx, inv, timeout)
+
+	filters := getInvokerFilters()
+	for i, f := range filters {
+		if res := f.filter.Before(ctx, url, inv); res != nil {
+			return withCorrelationID(di.afterInvoke(ctx, filters[:i], url, inv, res), correlationID)
+		}
+	}
+	client := di.client
+	if di.affinity {
+		pinAffinityKey(ctx, inv)
+	}
+	if di.stats != nil {
+		di.stats.start()
+	}
+	start := di.clock.Now()
+	res := di.doInvoke(ctx, client, url, inv, async, timeout)
+	if di.stats != nil {
+		elapsed := di.clock.Now().Sub(start)
+		di.stats.finish(elapsed, res.Error(), res.Error() != nil && elapsed >= timeout)
+	}
+	blacklist.record(url, res.Error())
+	return withCorrelationID(di.afterInvoke(ctx, filters, url, inv, res), correlationID)
+}
+
+// afterInvoke runs the After of @filters in reverse order
+func (di *DubboInvoker) afterInvoke(ctx context.Context, filters []invokerFilterEntry, url *common.URL,
+	inv *invocation_impl.RPCInvocation, result protocol.Result) protocol.Result {
+	for i := len(filters) - 1; i >= 0; i-- {
+		result = filters[i].filter.After(ctx, url, inv, result)
+	}
+	return result
+}
+
+// doInvoke makes the remoting call
+func (di *DubboInvoker) doInvoke(ctx context.Context, client *remoting.ExchangeClient, url *common.URL,
+	inv *invocation_impl.RPCInvocation, async bool, timeout time.Duration) protocol.Result {
+	var (
+		invocation protocol.Invocation = inv
+		result     protocol.RPCResult
+		void       bool
+	)
+	normalizeAttachmentKeys(inv, url.GetParam(constant.ATTACHMENT_KEY_CASING_KEY, attachmentKeyCasingReserved))
+	if result.Err = di.checkAttachmentCount(inv); result.Err != nil {
+		return &result
+	}
+	if _, result.Err = di.checkAttachmentSize(inv); result.Err != nil {
+		return &result
+	}
+	dumpInvocation(url, inv)
+	if di.isOneway(inv) {
+		// fire and forget, nothing is waited for and the reply is left untouched
+		result.Err = di.send(inv, func() error {
+			return client.Send(&invocation, url, timeout)
+		})
+		logger.Debugf("oneway result.Err: %v", result.Err)
+		return &result
+	}
 	// response := NewResponse(inv.Reply(), nil)
 	rest := &protocol.RPCResult{}
-	timeout := di.getTimeout(inv)
 	if async {
 		if callBack, ok := inv.CallBack().(func(response common.CallbackResponse)); ok {
-			result.Err = di.client.AsyncRequest(&invocation, url, timeout, callBack, rest)
+			callBack = di.callbackWithContext(ctx, url, timeout, callBack)
+			result.Err = di.send(inv, func() error {
+				return client.AsyncRequest(&invocation, url, timeout, callBack, rest)
+			})
 		} else {
-			result.Err = di.client.Send(&invocation, url, timeout)
+			result.Err = di.send(inv, func() error {
+				return client.Send(&invocation, url, timeout)
+			})
 		}
+	} else if inv.Reply() == nil && !di.isVoid(inv) {
+		result.Err = protocol.ErrNoReply
 	} else {
-		if inv.Reply() == nil {
-			result.Err = protocol.ErrNoReply
-		} else {
-			result.Err = di.client.Request(&invocation, url, timeout, rest)
+		if void = inv.Reply() == nil; void {
+			// whatever the void method replies is decoded into a placeholder and dropped
+			inv.SetReply(new(interface{}))
+		}
+		// the timeout is enforced by the client, a reply received is never dropped
+		result.Err = di.send(inv, func() error {
+			return client.Request(&invocation, url, timeout, rest)
+		})
+		if void {
+			inv.SetReply(nil)
 		}
 	}
 	if result.Err == nil {
 		result.Rest = inv.Reply()
-		result.Attrs = rest.Attrs
+		result.Attrs = di.returnedAttachments(inv, rest.Attrs)
 	}
 	logger.Debugf("result.Err: %v, result.Rest: %v", result.Err, result.Rest)
 
 	return &result
 }
 
-// get timeout including methodConfig
-func (di *DubboInvoker) getTimeout(invocation *invocation_impl.RPCInvocation) time.Duration {
-	methodName := invocation.MethodName()
+// send calls @fn for @inv, and calls it again as the retry policy decides, by default up to send.retries times
+// when it fails on the connection or with one of the exceptions listed by retry.exceptions. The other errors,
+// including timeouts and business exceptions, are returned at once because the request may have been handled.
+func (di *DubboInvoker) send(inv *invocation_impl.RPCInvocation, fn func() error) error {
+	return di.sendRetryPolicy(inv.MethodName()).Do(fn, func(retry int, err error) {
+		logger.Warnf("Resend the request to %s for the %d time, because of the error: %v", di.GetURL().Location, retry, err)
+	})
+}
+
+// isOneway reports whether the invocation is oneway, the attachment takes precedence over the url param
+func (di *DubboInvoker) isOneway(inv *invocation_impl.RPCInvocation) bool {
+	if v := inv.AttachmentsByKey(constant.ONEWAY_KEY, ""); len(v) > 0 {
+		oneway, err := strconv.ParseBool(v)
+		if err != nil {
+			logger.Errorf("ParseBool - error: %v", err)
+		}
+		return oneway
+	}
+	return di.GetURL().GetParamBool(constant.ONEWAY_KEY, false)
+}
+
+// isVoid reports whether the method of the invocation replies nothing, so that it can be called without a reply.
+// The attachment takes precedence over the method level url param methods.<method>.void
+func (di *DubboInvoker) isVoid(inv *invocation_impl.RPCInvocation) bool {
+	if v := inv.AttachmentsByKey(constant.VOID_KEY, ""); len(v) > 0 {
+		void, err := strconv.ParseBool(v)
+		if err != nil {
+			logger.Errorf("ParseBool - error: %v", err)
+		}
+		return void
+	}
+	return di.GetURL().GetMethodParamBool(inv.MethodName(), constant.VOID_KEY, false)
+}
+
+// RoutingTags returns the routing tags of the provider for the tag routers to filter the invokers.
+// The tags are the url params listed by the param routing.tags, e.g. "routing.tags=env,zone&env=gray&zone=hz"
+// gives {"env": "gray", "zone": "hz"}, plus the dubbo.tag param if it is set. The params missing or empty are left out.
+func (di *DubboInvoker) RoutingTags() map[string]string {
+	url := di.GetURL()
+	tags := make(map[string]string)
+	if tag := url.GetParam(constant.Tagkey, ""); len(tag) > 0 {
+		tags[constant.Tagkey] = tag
+	}
+	for _, key := range strings.Split(url.GetParam(constant.ROUTING_TAGS_KEY, ""), ",") {
+		if key = strings.TrimSpace(key); len(key) == 0 {
+			continue
+		}
+		if value := url.GetParam(key, ""); len(value) > 0 {
+			tags[key] = value
+		}
+	}
+	return tags
+}
+
+// Labels returns the custom labels of the provider, which are the url params prefixed with "label." when
+// the invoker is created, e.g. "label.experiment=b" gives {"experiment": "b"}. See protocol.SelectByLabels.
+func (di *DubboInvoker) Labels() map[string]string {
+	labels := make(map[string]string, len(di.labels))
+	for name, value := range di.labels {
+		labels[name] = value
+	}
+	return labels
+}
+
+// LoadBalance returns the load balance strategy of the method of @invocation,
+// the method level methods.<method>.loadbalance takes precedence over the service level one.
+func (di *DubboInvoker) LoadBalance(invocation protocol.Invocation) string {
+	lb := di.GetURL().GetParam(constant.LOADBALANCE_KEY, constant.DEFAULT_LOADBALANCE)
+	return di.GetURL().GetMethodParam(di.invokedMethodName(invocation), constant.LOADBALANCE_KEY, lb)
+}
+
+// invokedMethodName returns the name of the method really invoked, which is the first argument of a generic call
+func (di *DubboInvoker) invokedMethodName(invocation protocol.Invocation) string {
 	if di.GetURL().GetParamBool(constant.GENERIC_KEY, false) {
-		methodName = invocation.Arguments()[0].(string)
+		return invocation.Arguments()[0].(string)
 	}
+	return invocation.MethodName()
+}
+
+// get timeout including methodConfig, randomized by the url param timeout.jitter if it is set
+func (di *DubboInvoker) getTimeout(invocation *invocation_impl.RPCInvocation) time.Duration {
+	methodName := di.invokedMethodName(invocation)
+	t := di.timeout
 	timeout := di.GetURL().GetParam(strings.Join([]string{constant.METHOD_KEYS, methodName, constant.TIMEOUT_KEY}, "."), "")
 	if len(timeout) != 0 {
-		if t, err := time.ParseDuration(timeout); err == nil {
-			// config timeout into attachment
-			invocation.SetAttachments(constant.TIMEOUT_KEY, strconv.Itoa(int(t.Milliseconds())))
-			return t
+		if parsed, err := parseTimeout(timeout); err == nil {
+			t = parsed
+		} else {
+			logger.Warnf("Invalid timeout %q of method %s, use the default %v", timeout, methodName, di.timeout)
 		}
 	}
-	// set timeout into invocation at method level
-	invocation.SetAttachments(constant.TIMEOUT_KEY, strconv.Itoa(int(di.timeout.Milliseconds())))
-	return di.timeout
+	t = di.jitterTimeout(t)
+	// config timeout into attachment
+	invocation.SetAttachments(constant.TIMEOUT_KEY, strconv.Itoa(int(t.Milliseconds())))
+	return t
+}
+
+// jitterTimeout randomizes @timeout by up to the percentage of the url param timeout.jitter in both directions
+func (di *DubboInvoker) jitterTimeout(timeout time.Duration) time.Duration {
+	jitter := di.GetURL().GetParam(constant.TIMEOUT_JITTER_KEY, "")
+	if len(jitter) == 0 {
+		return timeout
+	}
+	percent, err := strconv.ParseFloat(jitter, 64)
+	if err != nil || percent < 0 || percent >= 100 {
+		logger.Warnf("Invalid timeout jitter %q, which must be a percentage in [0, 100)", jitter)
+		return timeout
+	}
+	return timeout + time.Duration((rand.Float64()*2-1)*percent/100*float64(timeout))
+}
+
+// appendDeadline puts the absolute deadline of the call into the attachment deadline as unix milliseconds
+// if the invoker is configured to do so, in addition to the relative timeout. The deadline of @ctx is used if any,
+// otherwise the deadline is @timeout from now.
+func (di *DubboInvoker) appendDeadline(ctx context.Context, inv *invocation_impl.RPCInvocation, timeout time.Duration) {
+	if !di.GetURL().GetParamBool(constant.SEND_DEADLINE_KEY, false) {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = di.clock.Now().Add(timeout)
+	}
+	inv.SetAttachments(constant.DEADLINE_KEY, strconv.FormatInt(deadline.UnixNano()/int64(time.Millisecond), 10))
+}
+
+// parseTimeout parses @timeout like "5s", a bare integer is taken as milliseconds as Java Dubbo does
+func parseTimeout(timeout string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(timeout, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(timeout)
+}
+
+// Ping sends a heartbeat to the provider without any business attachment, and returns the round-trip latency.
+// Unlike IsAvailable which only checks the local state, it fails if the provider is unreachable.
+// The deadline of @ctx is used as the timeout if any.
+func (di *DubboInvoker) Ping(ctx context.Context) (time.Duration, error) {
+	client := di.getClient()
+	if client == nil {
+		return 0, protocol.ErrClientClosed
+	}
+	timeout := di.timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+	start := di.clock.Now()
+	if err := client.Heartbeat(di.GetURL(), timeout); err != nil {
+		return 0, err
+	}
+	return di.clock.Now().Sub(start), nil
 }
 
 func (di *DubboInvoker) IsAvailable() bool {
@@ -187,20 +568,49 @@ func (di *DubboInvoker) IsAvailable() bool {
 	if client != nil {
 		return client.IsAvailable()
 	}
+	if !di.connected.Load() {
+		// not connected yet, the lazy or recycled invoker is available until the next call fails to connect
+		return di.BaseInvoker.IsAvailable()
+	}
 
 	return false
 }
 
+// RecycleConnection closes the connection of the invoker, e.g. once it is found half-open, without destroying
+// the invoker, which keeps its place in the cluster and connects again at the next call. The connection shared
+// with the other invokers of the provider is closed once all of them recycle it or are destroyed, while the new
+// connection is no longer shared with them.
+func (di *DubboInvoker) RecycleConnection() {
+	di.clientGuard.Lock()
+	defer di.clientGuard.Unlock()
+	if !di.BaseInvoker.IsAvailable() || !di.connected.Load() {
+		return
+	}
+	client := di.client
+	di.client = nil
+	di.connected.Store(false)
+	if client == nil {
+		return
+	}
+	exchangeClientMap.CompareAndDelete(di.GetURL().Location, client)
+	if client.DecreaseActiveNumber() == 0 {
+		client.Close()
+	}
+	logger.Infof("Recycle the connection to %s, which is connected again at the next call", di.GetURL().Location)
+}
+
 // Destroy destroy dubbo client invoker.
 func (di *DubboInvoker) Destroy() {
 	di.quitOnce.Do(func() {
 		di.BaseInvoker.Destroy()
+		removeActiveInvoker(di)
+		di.removeConfigListeners()
 		client := di.getClient()
 		if client != nil {
 			activeNumber := client.DecreaseActiveNumber()
 			di.setClient(nil)
 			if activeNumber == 0 {
-				exchangeClientMap.Delete(di.GetURL().Location)
+				exchangeClientMap.CompareAndDelete(di.GetURL().Location, client)
 				client.Close()
 			}
 		}
@@ -209,13 +619,18 @@ func (di *DubboInvoker) Destroy() {
 
 // Finally, I made the decision that I don't provide a general way to transfer the whole context
 // because it could be misused. If the context contains to many key-value pairs, the performance will be much lower.
-func (di *DubboInvoker) appendCtx(ctx context.Context, inv *invocation_impl.RPCInvocation) {
+// The failure of injecting the trace context is only logged, unless the trace.inject.failure policy is "fail".
+func (di *DubboInvoker) appendCtx(ctx context.Context, inv *invocation_impl.RPCInvocation) error {
 	// inject opentracing ctx
 	currentSpan := opentracing.SpanFromContext(ctx)
 	if currentSpan != nil {
-		err := injectTraceCtx(currentSpan, inv)
+		err := injectTraceCtx(di.traceCodec, currentSpan, inv)
 		if err != nil {
+			if di.GetURL().GetParam(constant.TRACE_INJECT_FAILURE_KEY, traceInjectFailureLog) == traceInjectFailureFail {
+				return perrors.WithMessage(err, "could not inject the span context into attachments")
+			}
 			logger.Errorf("Could not inject the span context into attachments: %v", err)
 		}
 	}
+	return nil
 }
@@ -17,6 +17,1292 @@
 
 package dubbo
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+import (
+	"github.com/apache/dubbo-go-hessian2/java_exception"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+
+	perrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/config_center"
+	"dubbo.apache.org/dubbo-go/v3/config_center/zookeeper"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+	"dubbo.apache.org/dubbo-go/v3/protocol/dubbo/impl"
+	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+)
+
+const mockInvokerUrl = "dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider"
+
+// mockRemotingClient is a remoting.Client that records the requests and answers them without network.
+type mockRemotingClient struct {
+	lock       sync.Mutex
+	connectErr error
+	closed     bool
+	requests   []*remoting.Request
+	// handler builds the result of a two way request, the default one replies an empty result.
+	handler func(request *remoting.Request) (*protocol.RPCResult, error)
+}
+
+func (c *mockRemotingClient) SetExchangeClient(*remoting.ExchangeClient) {}
+
+func (c *mockRemotingClient) Connect(*common.URL) error {
+	return c.connectErr
+}
+
+func (c *mockRemotingClient) Close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.closed = true
+}
+
+func (c *mockRemotingClient) Request(request *remoting.Request, _ time.Duration, response *remoting.PendingResponse) error {
+	c.lock.Lock()
+	c.requests = append(c.requests, request)
+	c.lock.Unlock()
+	result := &protocol.RPCResult{}
+	if c.handler != nil {
+		var err error
+		if result, err = c.handler(request); err != nil {
+			return err
+		}
+	}
+	response.SetResponse(&remoting.Response{ID: request.ID, Result: result})
+	return nil
+}
+
+func (c *mockRemotingClient) IsAvailable() bool {
+	return true
+}
+
+func (c *mockRemotingClient) requestCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.requests)
+}
+
+func newMockInvoker(t *testing.T, params string) (*DubboInvoker, *mockRemotingClient) {
+	url, err := common.NewURL(mockInvokerUrl + params)
+	assert.NoError(t, err)
+	client := &mockRemotingClient{}
+	return NewDubboInvoker(url, remoting.NewExchangeClient(url, client, time.Second, false)), client
+}
+
+func newMockInvocation(attachments map[string]interface{}) *invocation.RPCInvocation {
+	if attachments == nil {
+		attachments = map[string]interface{}{}
+	}
+	return invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"),
+		invocation.WithArguments([]interface{}{"1", "username"}), invocation.WithReply(new(string)),
+		invocation.WithAttachments(attachments))
+}
+
+func TestDubboInvokerAttachmentSizeThreshold(t *testing.T) {
+	big := map[string]interface{}{"baggage": strings.Repeat("x", 512)}
+
+	// off by default
+	invoker, _ := newMockInvoker(t, "")
+	exceeded, err := invoker.checkAttachmentSize(newMockInvocation(big))
+	assert.NoError(t, err)
+	assert.False(t, exceeded)
+
+	// warn only
+	invoker, client := newMockInvoker(t, "&"+constant.ATTACHMENT_SIZE_THRESHOLD_KEY+"=256")
+	exceeded, err = invoker.checkAttachmentSize(newMockInvocation(nil))
+	assert.NoError(t, err)
+	assert.False(t, exceeded)
+	exceeded, err = invoker.checkAttachmentSize(newMockInvocation(big))
+	assert.NoError(t, err)
+	assert.True(t, exceeded)
+	res := invoker.Invoke(context.Background(), newMockInvocation(big))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+
+	// reject
+	invoker, client = newMockInvoker(t, "&"+constant.ATTACHMENT_SIZE_THRESHOLD_KEY+"=256&"+
+		constant.ATTACHMENT_SIZE_REJECT_KEY+"=true")
+	res = invoker.Invoke(context.Background(), newMockInvocation(big))
+	assert.Error(t, res.Error())
+	assert.Contains(t, res.Error().Error(), "the largest are baggage(519)")
+	assert.Equal(t, 0, client.requestCount())
+
+	// truncate
+	invoker, client = newMockInvoker(t, "&"+constant.ATTACHMENT_SIZE_THRESHOLD_KEY+"=256&"+
+		constant.ATTACHMENT_SIZE_TRUNCATE_KEY+"=true")
+	inv := newMockInvocation(map[string]interface{}{
+		"baggage": strings.Repeat("x", 512),
+		"span":    strings.Repeat("y", 64),
+		"user":    "tom",
+	})
+	res = invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+	assert.Nil(t, inv.Attachment("baggage"))
+	assert.Equal(t, strings.Repeat("y", 64), inv.Attachment("span"))
+	assert.Equal(t, "tom", inv.Attachment("user"))
+	assert.NotEmpty(t, inv.Attachment(constant.PATH_KEY))
+}
+
+func TestDubboInvokerAttachmentCount(t *testing.T) {
+	newInvocation := func() *invocation.RPCInvocation {
+		baggage := make(map[string]interface{})
+		for i := 0; i < 8; i++ {
+			baggage[fmt.Sprintf("baggage-%d", i)] = "x"
+		}
+		return newMockInvocation(baggage)
+	}
+
+	// unlimited by default
+	invoker, client := newMockInvoker(t, "")
+	res := invoker.Invoke(context.Background(), newInvocation())
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+
+	// reject
+	invoker, client = newMockInvoker(t, "&"+constant.ATTACHMENT_COUNT_MAX_KEY+"=16")
+	res = invoker.Invoke(context.Background(), newInvocation())
+	assert.NoError(t, res.Error())
+	invoker, client = newMockInvoker(t, "&"+constant.ATTACHMENT_COUNT_MAX_KEY+"=6")
+	res = invoker.Invoke(context.Background(), newInvocation())
+	assert.Error(t, res.Error())
+	assert.Contains(t, res.Error().Error(), "exceeding the max count of 6")
+	assert.Equal(t, 0, client.requestCount())
+
+	// trim
+	invoker, client = newMockInvoker(t, "&"+constant.ATTACHMENT_COUNT_MAX_KEY+"=6&"+
+		constant.ATTACHMENT_COUNT_POLICY_KEY+"=trim")
+	inv := newInvocation()
+	res = invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+	assert.Len(t, inv.Attachments(), 6)
+	// the reserved attachments are kept, then the others in order
+	assert.NotEmpty(t, inv.Attachment(constant.PATH_KEY))
+	assert.NotEmpty(t, inv.Attachment(constant.INTERFACE_KEY))
+	assert.NotEmpty(t, inv.Attachment(constant.CORRELATION_ID_KEY))
+	assert.NotEmpty(t, inv.Attachment(constant.TIMEOUT_KEY))
+	assert.Equal(t, "x", inv.Attachment("baggage-0"))
+	assert.Equal(t, "x", inv.Attachment("baggage-1"))
+	assert.Nil(t, inv.Attachment("baggage-2"))
+	assert.Nil(t, inv.Attachment("baggage-7"))
+}
+
+// fakeSerialization is hessian2 registered with another id, which counts the bodies marshaled.
+type fakeSerialization struct {
+	impl.HessianSerializer
+	marshaled int
+}
+
+func (s *fakeSerialization) ID() byte {
+	return 30
+}
+
+func (s *fakeSerialization) Marshal(p impl.DubboPackage) ([]byte, error) {
+	s.marshaled++
+	return s.HessianSerializer.Marshal(p)
+}
+
+func TestDubboInvokerSerialization(t *testing.T) {
+	codec := &fakeSerialization{}
+	RegisterSerialization("fake", codec)
+
+	invoker, client := newMockInvoker(t, "&"+constant.SERIALIZATION_KEY+"=fake")
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+	assert.Equal(t, "fake", client.requests[0].Serialization)
+
+	buf, err := (&DubboCodec{}).EncodeRequest(client.requests[0])
+	assert.NoError(t, err)
+	assert.Equal(t, 1, codec.marshaled)
+	assert.Equal(t, byte(30), buf.Bytes()[2]&impl.SERIAL_MASK)
+
+	// an unknown serialization is refused before sending
+	invoker, client = newMockInvoker(t, "&"+constant.SERIALIZATION_KEY+"=unknown")
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.Error(t, res.Error())
+	assert.Equal(t, 0, client.requestCount())
+}
+
+func TestDubboInvokerCallProtocol(t *testing.T) {
+	codec := &fakeSerialization{}
+	RegisterSerialization("fake", codec)
+	interceptor := &reverseInterceptor{}
+	SetBytesInterceptor("reverse", interceptor)
+	invoker, client := newMockInvoker(t, "")
+
+	// both are overridden for the call
+	ctx := WithCallProtocol(context.Background(), CallProtocol{Serialization: "fake", Compression: "reverse"})
+	res := invoker.Invoke(ctx, newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "reverse", client.requests[0].Interceptor)
+	buf, err := (&DubboCodec{}).EncodeRequest(client.requests[0])
+	assert.NoError(t, err)
+	assert.Equal(t, byte(30), buf.Bytes()[2]&impl.SERIAL_MASK)
+	assert.Equal(t, 1, codec.marshaled)
+	assert.Len(t, interceptor.requests, 1)
+
+	// the other calls are left on the defaults of the url
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Empty(t, client.requests[1].Interceptor)
+	buf, err = (&DubboCodec{}).EncodeRequest(client.requests[1])
+	assert.NoError(t, err)
+	assert.Equal(t, constant.S_Hessian2, buf.Bytes()[2]&impl.SERIAL_MASK)
+	assert.Equal(t, constant.HESSIAN2_SERIALIZATION, invoker.GetURL().GetParam(constant.SERIALIZATION_KEY, ""))
+	assert.Empty(t, invoker.GetURL().GetParam(constant.BYTES_INTERCEPTOR_KEY, ""))
+
+	// the compression not registered fails the call
+	ctx = WithCallProtocol(context.Background(), CallProtocol{Compression: "absent"})
+	res = invoker.Invoke(ctx, newMockInvocation(nil))
+	assert.EqualError(t, res.Error(), "compression absent is not registered")
+	assert.Equal(t, 2, client.requestCount())
+}
+
+func TestDubboInvokerBidirectionalAttachments(t *testing.T) {
+	invoker, client := newMockInvoker(t, "&"+constant.ATTACHMENT_BIDIRECTIONAL_KEY+"=baggage")
+	// the provider echoes the attachments with new values, and returns one of its own
+	client.handler = func(request *remoting.Request) (*protocol.RPCResult, error) {
+		attrs := map[string]interface{}{"server": "provider-1"}
+		for k, v := range (*request.Data.(*protocol.Invocation)).Attachments() {
+			attrs[k] = fmt.Sprint(v) + "-echoed"
+		}
+		return &protocol.RPCResult{Attrs: attrs}, nil
+	}
+	res := invoker.Invoke(context.Background(), newMockInvocation(map[string]interface{}{
+		"baggage": "round-trip",
+		"token":   "request-only",
+	}))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "round-trip-echoed", res.Attachment("baggage", nil))
+	assert.Nil(t, res.Attachment("token", nil))
+	assert.Equal(t, "provider-1", res.Attachment("server", nil))
+}
+
+func TestDubboInvokerAttachmentKeyCasing(t *testing.T) {
+	newInvocation := func() *invocation.RPCInvocation {
+		return newMockInvocation(map[string]interface{}{"TIMEOUT": "5000", "Version": "1.0.0", "Trace-Id": "abc"})
+	}
+
+	// the reserved keys by default
+	invoker, _ := newMockInvoker(t, "")
+	inv := newInvocation()
+	assert.NoError(t, invoker.Invoke(context.Background(), inv).Error())
+	assert.Nil(t, inv.Attachment("TIMEOUT"))
+	assert.Nil(t, inv.Attachment("Version"))
+	assert.Equal(t, "1.0.0", inv.Attachment(constant.VERSION_KEY))
+	// the timeout set by the invoker takes precedence
+	assert.Equal(t, "3000", inv.Attachment(constant.TIMEOUT_KEY))
+	assert.Equal(t, "abc", inv.Attachment("Trace-Id"))
+
+	// all keys
+	invoker, _ = newMockInvoker(t, "&"+constant.ATTACHMENT_KEY_CASING_KEY+"=lower")
+	inv = newInvocation()
+	assert.NoError(t, invoker.Invoke(context.Background(), inv).Error())
+	assert.Nil(t, inv.Attachment("Trace-Id"))
+	assert.Equal(t, "abc", inv.Attachment("trace-id"))
+
+	// none
+	invoker, _ = newMockInvoker(t, "&"+constant.ATTACHMENT_KEY_CASING_KEY+"=none")
+	inv = newInvocation()
+	assert.NoError(t, invoker.Invoke(context.Background(), inv).Error())
+	assert.Equal(t, "1.0.0", inv.Attachment("Version"))
+	assert.Nil(t, inv.Attachment(constant.VERSION_KEY))
+}
+
+func TestDubboInvokerDumpInvocation(t *testing.T) {
+	var dumps []string
+	defer func(log func(string)) {
+		logInvocationDump = log
+	}(logInvocationDump)
+	logInvocationDump = func(dump string) {
+		dumps = append(dumps, dump)
+	}
+	attachments := map[string]interface{}{"user": "tom", "auth.token": "secret"}
+
+	// off by default
+	invoker, _ := newMockInvoker(t, "")
+	assert.NoError(t, invoker.Invoke(context.Background(), newMockInvocation(attachments)).Error())
+	assert.Empty(t, dumps)
+
+	invoker, _ = newMockInvoker(t, "&"+constant.DUMP_INVOCATION_KEY+"=true")
+	assert.NoError(t, invoker.Invoke(context.Background(), newMockInvocation(attachments)).Error())
+	assert.Equal(t, 1, len(dumps))
+	assert.Contains(t, dumps[0], "Invoke com.ikurento.user.UserProvider.GetUser on 127.0.0.1:20000, arguments [string(1), string(username)]")
+	assert.Contains(t, dumps[0], "auth.token: ******")
+	assert.Contains(t, dumps[0], "user: tom")
+	assert.NotContains(t, dumps[0], "secret")
+
+	// the custom redactor
+	SetAttachmentRedactor(func(key string) bool {
+		return key == "user"
+	})
+	defer SetAttachmentRedactor(nil)
+	assert.NoError(t, invoker.Invoke(context.Background(), newMockInvocation(attachments)).Error())
+	assert.Contains(t, dumps[1], "auth.token: secret")
+	assert.Contains(t, dumps[1], "user: ******")
+}
+
+func TestLargestAttachments(t *testing.T) {
+	attachments := map[string]interface{}{"a": "12345", "bb": "1", "c": nil, "d": "1234"}
+	assert.Equal(t, "a(6), d(5)", largestAttachments(attachments, 2))
+	assert.Equal(t, "a(6), d(5), bb(3), c(1)", largestAttachments(attachments, 10))
+}
+
+// traceparentCodec serializes the span context of the mock tracer as a single traceparent attachment
+type traceparentCodec struct{}
+
+func (c *traceparentCodec) Inject(spanCtx opentracing.SpanContext, attachments map[string]interface{}) error {
+	mockCtx := spanCtx.(mocktracer.MockSpanContext)
+	attachments["traceparent"] = fmt.Sprintf("00-%d-%d-01", mockCtx.TraceID, mockCtx.SpanID)
+	return nil
+}
+
+func (c *traceparentCodec) Extract(attachments map[string]interface{}) (opentracing.SpanContext, error) {
+	mockCtx := mocktracer.MockSpanContext{Sampled: true}
+	traceparent, _ := attachments["traceparent"].(string)
+	if _, err := fmt.Sscanf(traceparent, "00-%d-%d-01", &mockCtx.TraceID, &mockCtx.SpanID); err != nil {
+		return nil, err
+	}
+	return mockCtx, nil
+}
+
+func TestDubboInvokerTraceContextCodec(t *testing.T) {
+	SetTraceContextCodec("traceparent", &traceparentCodec{})
+	assert.IsType(t, &textMapTraceContextCodec{}, GetTraceContextCodec(""))
+
+	invoker, _ := newMockInvoker(t, "&"+constant.TRACE_CODEC_KEY+"=traceparent")
+	span := mocktracer.New().StartSpan("TestOperation")
+	defer span.Finish()
+	inv := newMockInvocation(nil)
+	res := invoker.Invoke(opentracing.ContextWithSpan(context.Background(), span), inv)
+	assert.NoError(t, res.Error())
+	spanCtx := span.Context().(mocktracer.MockSpanContext)
+	assert.Equal(t, fmt.Sprintf("00-%d-%d-01", spanCtx.TraceID, spanCtx.SpanID), inv.AttachmentsByKey("traceparent", ""))
+
+	ctx := rebuildCtx(inv, GetTraceContextCodec("traceparent"))
+	remoteCtx, ok := ctx.Value(constant.TRACING_REMOTE_SPAN_CTX).(mocktracer.MockSpanContext)
+	assert.True(t, ok)
+	assert.Equal(t, spanCtx.TraceID, remoteCtx.TraceID)
+	assert.Equal(t, spanCtx.SpanID, remoteCtx.SpanID)
+}
+
+// failingTraceCodec fails to inject any span context
+type failingTraceCodec struct{}
+
+func (c *failingTraceCodec) Inject(opentracing.SpanContext, map[string]interface{}) error {
+	return fmt.Errorf("unsupported span context")
+}
+
+func (c *failingTraceCodec) Extract(map[string]interface{}) (opentracing.SpanContext, error) {
+	return nil, opentracing.ErrSpanContextNotFound
+}
+
+func TestDubboInvokerTraceInjectFailure(t *testing.T) {
+	SetTraceContextCodec("failing", &failingTraceCodec{})
+	span := mocktracer.New().StartSpan("TestOperation")
+	defer span.Finish()
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	// log and continue by default
+	invoker, client := newMockInvoker(t, "&"+constant.TRACE_CODEC_KEY+"=failing")
+	assert.NoError(t, invoker.Invoke(ctx, newMockInvocation(nil)).Error())
+	assert.Equal(t, 1, client.requestCount())
+
+	// fail fast without sending
+	invoker, client = newMockInvoker(t, "&"+constant.TRACE_CODEC_KEY+"=failing&"+constant.TRACE_INJECT_FAILURE_KEY+"=fail")
+	res := invoker.Invoke(ctx, newMockInvocation(nil))
+	assert.EqualError(t, res.Error(), "could not inject the span context into attachments: unsupported span context")
+	assert.Equal(t, 0, client.requestCount())
+
+	// nothing to inject without a span
+	assert.NoError(t, invoker.Invoke(context.Background(), newMockInvocation(nil)).Error())
+	assert.Equal(t, 1, client.requestCount())
+}
+
+func TestDubboInvokerValidator(t *testing.T) {
+	SetInvokeValidator("com.ikurento.user.UserProvider", "GetUser", func(arguments []interface{}) error {
+		if id, _ := arguments[0].(string); len(id) == 0 || len(id) > 8 {
+			return fmt.Errorf("id out of range: %q", id)
+		}
+		return nil
+	})
+	defer RemoveInvokeValidator("com.ikurento.user.UserProvider", "GetUser")
+
+	invoker, client := newMockInvoker(t, "")
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"),
+		invocation.WithArguments([]interface{}{"123456789", "username"}), invocation.WithReply(new(string)))
+	res := invoker.Invoke(context.Background(), inv)
+	assert.EqualError(t, res.Error(), `id out of range: "123456789"`)
+	assert.Equal(t, 0, client.requestCount())
+
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+}
+
+func TestDubboInvokerDegradation(t *testing.T) {
+	SetDegradation("com.ikurento.user.UserProvider", "GetUser", "anonymous")
+	defer RemoveDegradation("com.ikurento.user.UserProvider", "GetUser")
+	failing := func(*remoting.Request) (*protocol.RPCResult, error) {
+		return nil, errors.New("provider is overloaded")
+	}
+
+	// the errors propagate by default
+	invoker, client := newMockInvoker(t, "")
+	client.handler = failing
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.EqualError(t, perrors.Cause(res.Error()), "provider is overloaded")
+
+	// degraded to the fallback value
+	invoker, client = newMockInvoker(t, "&methods.GetUser."+constant.DEGRADATION_KEY+"=true")
+	client.handler = failing
+	inv := newMockInvocation(nil)
+	res = invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "anonymous", res.Result())
+	assert.Equal(t, "anonymous", *inv.Reply().(*string))
+
+	// the successful call is not touched
+	client.handler = nil
+	inv = newMockInvocation(nil)
+	res = invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "", *inv.Reply().(*string))
+}
+
+// attachmentFilter injects an attachment before the call
+type attachmentFilter struct{}
+
+func (f *attachmentFilter) Before(_ context.Context, url *common.URL, inv protocol.Invocation) protocol.Result {
+	inv.SetAttachments("auth", url.GetParam(constant.INTERFACE_KEY, ""))
+	return nil
+}
+
+func (f *attachmentFilter) After(_ context.Context, _ *common.URL, _ protocol.Invocation, result protocol.Result) protocol.Result {
+	return result
+}
+
+// recordFilter records the results and short-circuits the calls with the attachment reject
+type recordFilter struct {
+	results []protocol.Result
+}
+
+func (f *recordFilter) Before(_ context.Context, _ *common.URL, inv protocol.Invocation) protocol.Result {
+	if inv.AttachmentsByKey("reject", "") == "true" {
+		return &protocol.RPCResult{Err: fmt.Errorf("rejected")}
+	}
+	return nil
+}
+
+func (f *recordFilter) After(_ context.Context, _ *common.URL, _ protocol.Invocation, result protocol.Result) protocol.Result {
+	f.results = append(f.results, result)
+	return result
+}
+
+func TestDubboInvokerFilter(t *testing.T) {
+	record := &recordFilter{}
+	SetInvokerFilter("record", 1, record)
+	SetInvokerFilter("attachment", 0, &attachmentFilter{})
+	defer RemoveInvokerFilter("record")
+	defer RemoveInvokerFilter("attachment")
+
+	invoker, client := newMockInvoker(t, "")
+	inv := newMockInvocation(nil)
+	res := invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+	assert.Equal(t, "com.ikurento.user.UserProvider", inv.AttachmentsByKey("auth", ""))
+	assert.Equal(t, []protocol.Result{res}, record.results)
+
+	// short-circuit
+	res = invoker.Invoke(context.Background(), newMockInvocation(map[string]interface{}{"reject": "true"}))
+	assert.EqualError(t, res.Error(), "rejected")
+	assert.Equal(t, 1, client.requestCount())
+	// the After of the filter short-circuiting the call is not called
+	assert.Equal(t, 1, len(record.results))
+}
+
+func TestDubboInvokerOneway(t *testing.T) {
+	invoker, client := newMockInvoker(t, "&"+constant.ONEWAY_KEY+"=true")
+	inv := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"),
+		invocation.WithArguments([]interface{}{"1", "username"}))
+	res := invoker.Invoke(context.Background(), inv)
+	// no reply is needed
+	assert.NoError(t, res.Error())
+	assert.Nil(t, res.Result())
+	assert.Equal(t, 1, client.requestCount())
+	assert.False(t, client.requests[0].TwoWay)
+
+	// the attachment takes precedence over the url param
+	invoker, client = newMockInvoker(t, "")
+	res = invoker.Invoke(context.Background(), newMockInvocation(map[string]interface{}{constant.ONEWAY_KEY: "true"}))
+	assert.NoError(t, res.Error())
+	assert.Nil(t, res.Result())
+	assert.False(t, client.requests[0].TwoWay)
+
+	res = invoker.Invoke(context.Background(), invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"),
+		invocation.WithArguments([]interface{}{"1", "username"})))
+	assert.Equal(t, protocol.ErrNoReply, res.Error())
+}
+
+func TestDubboInvokerVoid(t *testing.T) {
+	newInvocation := func(attachments map[string]interface{}) *invocation.RPCInvocation {
+		return invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUser"),
+			invocation.WithArguments([]interface{}{"1", "username"}), invocation.WithAttachments(attachments))
+	}
+
+	// a void method replies nothing
+	invoker, client := newMockInvoker(t, "&methods.GetUser."+constant.VOID_KEY+"=true")
+	inv := newInvocation(map[string]interface{}{})
+	res := invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Nil(t, res.Result())
+	assert.Nil(t, inv.Reply())
+	assert.Equal(t, 1, client.requestCount())
+	assert.True(t, client.requests[0].TwoWay)
+
+	// the attachment takes precedence over the url param
+	res = invoker.Invoke(context.Background(), newInvocation(map[string]interface{}{constant.VOID_KEY: "false"}))
+	assert.Equal(t, protocol.ErrNoReply, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+
+	// a method expecting a reply
+	invoker, client = newMockInvoker(t, "")
+	res = invoker.Invoke(context.Background(), newInvocation(map[string]interface{}{}))
+	assert.Equal(t, protocol.ErrNoReply, res.Error())
+	assert.Equal(t, 0, client.requestCount())
+	res = invoker.Invoke(context.Background(), newInvocation(map[string]interface{}{constant.VOID_KEY: "true"}))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+}
+
+func TestEndpointBlacklist(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	defer func(origin *endpointBlacklist) {
+		blacklist = origin
+	}(blacklist)
+	blacklist = newEndpointBlacklist()
+	blacklist.clock = clock
+
+	failing := func(*remoting.Request) (*protocol.RPCResult, error) {
+		return nil, remoting.NewConnectionError(fmt.Errorf("session not exist"))
+	}
+	params := "&" + constant.BLACKLIST_FAILURES_KEY + "=3&" + constant.BLACKLIST_COOLDOWN_KEY + "=10s"
+	url, err := common.NewURL(mockInvokerUrl + params + "&" + constant.LAZY_CONNECT_KEY + "=true")
+	assert.NoError(t, err)
+	dp := NewDubboProtocol()
+
+	// off by default
+	invoker, client := newMockInvoker(t, "")
+	client.handler = failing
+	for i := 0; i < 5; i++ {
+		invoker.Invoke(context.Background(), newMockInvocation(nil))
+	}
+	assert.False(t, blacklist.isBlacklisted(invoker.GetURL()))
+
+	// the failures are counted across the invokers of the endpoint, and reset by a success
+	first, firstClient := newMockInvoker(t, params)
+	second, secondClient := newMockInvoker(t, params)
+	firstClient.handler = failing
+	first.Invoke(context.Background(), newMockInvocation(nil))
+	first.Invoke(context.Background(), newMockInvocation(nil))
+	second.Invoke(context.Background(), newMockInvocation(nil))
+	first.Invoke(context.Background(), newMockInvocation(nil))
+	first.Invoke(context.Background(), newMockInvocation(nil))
+	assert.False(t, blacklist.isBlacklisted(url))
+	secondClient.handler = failing
+	second.Invoke(context.Background(), newMockInvocation(nil))
+	assert.True(t, blacklist.isBlacklisted(url))
+	assert.Nil(t, dp.Refer(url))
+
+	// referred again once the cooldown expires
+	clock.Advance(9 * time.Second)
+	assert.Nil(t, dp.Refer(url))
+	clock.Advance(time.Second)
+	assert.False(t, blacklist.isBlacklisted(url))
+	referred := dp.Refer(url)
+	assert.NotNil(t, referred)
+	referred.Destroy()
+}
+
+func TestDubboInvokerSendRetries(t *testing.T) {
+	failures := 1
+	handler := func(*remoting.Request) (*protocol.RPCResult, error) {
+		if failures > 0 {
+			failures--
+			return nil, remoting.NewConnectionError(fmt.Errorf("session not exist"))
+		}
+		return &protocol.RPCResult{}, nil
+	}
+
+	// no retry by default
+	invoker, client := newMockInvoker(t, "")
+	client.handler = handler
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.True(t, remoting.IsConnectionError(res.Error()))
+	assert.Equal(t, 1, client.requestCount())
+
+	failures = 1
+	invoker, client = newMockInvoker(t, "&"+constant.SEND_RETRIES_KEY+"=2")
+	client.handler = handler
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 2, client.requestCount())
+
+	// the other errors are not retried
+	client.handler = func(*remoting.Request) (*protocol.RPCResult, error) {
+		return nil, fmt.Errorf("read timeout")
+	}
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.EqualError(t, res.Error(), "read timeout")
+	assert.Equal(t, 3, client.requestCount())
+
+	// the custom policy
+	invoker.SetRetryPolicy(&common.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, Retryable: func(err error) bool {
+		return err.Error() == "read timeout"
+	}})
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.EqualError(t, res.Error(), "read timeout")
+	assert.Equal(t, 6, client.requestCount())
+	// only the connection errors are retried without Retryable
+	invoker.SetRetryPolicy(&common.RetryPolicy{MaxAttempts: 3})
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.EqualError(t, res.Error(), "read timeout")
+	assert.Equal(t, 7, client.requestCount())
+}
+
+func TestDubboInvokerRetryBudget(t *testing.T) {
+	// never refilled
+	SetRetryBudget(common.NewTokenBucketRetryBudget(0, 3))
+	defer SetRetryBudget(nil)
+
+	invoker, client := newMockInvoker(t, "&"+constant.SEND_RETRIES_KEY+"=2")
+	client.handler = func(*remoting.Request) (*protocol.RPCResult, error) {
+		return nil, remoting.NewConnectionError(fmt.Errorf("session not exist"))
+	}
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.True(t, remoting.IsConnectionError(res.Error()))
+	assert.Equal(t, 3, client.requestCount())
+	// a single retry is left in the budget
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.True(t, remoting.IsConnectionError(res.Error()))
+	assert.Equal(t, 5, client.requestCount())
+	// exhausted, no more retry
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.True(t, remoting.IsConnectionError(res.Error()))
+	assert.Equal(t, 6, client.requestCount())
+}
+
+// temporaryException is the exception returned by the provider when the call can be retried
+type temporaryException struct {
+	java_exception.Exception
+}
+
+func (temporaryException) JavaClassName() string {
+	return "org.apache.dubbo.sample.TemporaryException"
+}
+
+func TestDubboInvokerRetryExceptions(t *testing.T) {
+	var exception error
+	failures := 0
+	handler := func(*remoting.Request) (*protocol.RPCResult, error) {
+		if failures > 0 {
+			failures--
+			return nil, perrors.WithStack(exception)
+		}
+		return &protocol.RPCResult{}, nil
+	}
+
+	// no exception is retried by default
+	exception, failures = &temporaryException{}, 1
+	invoker, client := newMockInvoker(t, "&"+constant.SEND_RETRIES_KEY+"=2")
+	client.handler = handler
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.Equal(t, exception, perrors.Cause(res.Error()))
+	assert.Equal(t, 1, client.requestCount())
+
+	// the designated exception is retried
+	exception, failures = &temporaryException{}, 1
+	invoker, client = newMockInvoker(t, "&methods.GetUser."+constant.SEND_RETRIES_KEY+"=2"+
+		"&methods.GetUser."+constant.RETRY_EXCEPTIONS_KEY+"=java.lang.IllegalArgumentException,TemporaryException")
+	client.handler = handler
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 2, client.requestCount())
+
+	// the business error is not
+	exception, failures = java_exception.NewIllegalStateException("insufficient balance"), 1
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.Equal(t, exception, perrors.Cause(res.Error()))
+	assert.Equal(t, 3, client.requestCount())
+	exception, failures = java_exception.NewIllegalArgumentException("invalid id"), 1
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 5, client.requestCount())
+
+	// nor the other methods
+	exception, failures = &temporaryException{}, 1
+	res = invoker.Invoke(context.Background(), invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetAdmin"),
+		invocation.WithReply(new(string))))
+	assert.Equal(t, exception, perrors.Cause(res.Error()))
+	assert.Equal(t, 6, client.requestCount())
+}
+
+func TestDubboInvokerIdempotencyKey(t *testing.T) {
+	var keys []string
+	failures := 0
+	handler := func(request *remoting.Request) (*protocol.RPCResult, error) {
+		keys = append(keys, (*request.Data.(*protocol.Invocation)).AttachmentsByKey(constant.IDEMPOTENCY_KEY, ""))
+		if failures > 0 {
+			failures--
+			return nil, remoting.NewConnectionError(fmt.Errorf("session not exist"))
+		}
+		return &protocol.RPCResult{}, nil
+	}
+
+	// off by default
+	invoker, client := newMockInvoker(t, "")
+	client.handler = handler
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, []string{""}, keys)
+
+	// the same key across the resends
+	keys, failures = nil, 2
+	invoker, client = newMockInvoker(t, "&"+constant.IDEMPOTENCY_ENABLED_KEY+"=true&"+constant.SEND_RETRIES_KEY+"=2")
+	client.handler = handler
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Len(t, keys, 3)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+
+	// and the invocations of the same call by the cluster
+	keys = nil
+	inv := newMockInvocation(nil)
+	invoker.Invoke(context.Background(), inv)
+	invoker.Invoke(context.Background(), inv)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, keys[0], keys[1])
+	// while another call has another key
+	invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NotEqual(t, keys[0], keys[2])
+
+	// given by the context
+	keys = nil
+	ctx := context.WithValue(context.Background(), constant.IDEMPOTENCY_CTX_KEY, "order-1")
+	invoker.Invoke(ctx, newMockInvocation(nil))
+	assert.Equal(t, []string{"order-1"}, keys)
+
+	// enabled per method
+	keys = nil
+	invoker, client = newMockInvoker(t, "&methods.GetUser."+constant.IDEMPOTENCY_ENABLED_KEY+"=true")
+	client.handler = handler
+	invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NotEmpty(t, keys[0])
+}
+
+func TestGetActiveInvokers(t *testing.T) {
+	interfaceName := "com.ikurento.user.AdminProvider"
+	url, err := common.NewURL("dubbo://127.0.0.1:20000/" + interfaceName + "?interface=" + interfaceName)
+	assert.NoError(t, err)
+	newInvoker := func() *DubboInvoker {
+		return NewDubboInvoker(url.Clone(), remoting.NewExchangeClient(url, &mockRemotingClient{}, time.Second, false))
+	}
+	invoker1 := newInvoker()
+	invoker2 := newInvoker()
+	defer invoker2.Destroy()
+	states := GetActiveInvokers(interfaceName)
+	assert.Equal(t, 2, len(states))
+	for _, state := range states {
+		assert.Equal(t, "127.0.0.1:20000", state.URL.Location)
+		assert.True(t, state.Available)
+	}
+
+	invoker1.Destroy()
+	states = GetActiveInvokers(interfaceName)
+	assert.Equal(t, 1, len(states))
+	assert.Same(t, invoker2.GetURL(), states[0].URL)
+	assert.Empty(t, GetActiveInvokers("com.ikurento.user.UnknownProvider"))
+}
+
+func TestDubboInvokerAffinity(t *testing.T) {
+	invoker, client := newMockInvoker(t, "&"+constant.AFFINITY_CONNECTIONS_KEY+"=4")
+	defer invoker.Destroy()
+	res := invoker.Invoke(context.Background(), newMockInvocation(map[string]interface{}{constant.AFFINITY_KEY: "session-1"}))
+	assert.NoError(t, res.Error())
+	ctx := context.WithValue(context.Background(), constant.AFFINITY_CTX_KEY, "session-1")
+	res = invoker.Invoke(ctx, newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	// no affinity key
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	// both pinned to the same pooled connection of the client
+	assert.Equal(t, 3, client.requestCount())
+	assert.Equal(t, "session-1", client.requests[0].Affinity)
+	assert.Equal(t, "session-1", client.requests[1].Affinity)
+	assert.Equal(t, "", client.requests[2].Affinity)
+
+	// affinity is off
+	invoker, client = newMockInvoker(t, "")
+	res = invoker.Invoke(context.Background(), newMockInvocation(map[string]interface{}{constant.AFFINITY_KEY: "session-1"}))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "", client.requests[0].Affinity)
+}
+
+func TestDubboInvokerPing(t *testing.T) {
+	invoker, client := newMockInvoker(t, "")
+	latency, err := invoker.Ping(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, latency >= 0)
+	assert.Equal(t, 1, client.requestCount())
+	assert.True(t, client.requests[0].Event)
+	assert.Nil(t, client.requests[0].Data)
+
+	// unreachable
+	url, err := common.NewURL(mockInvokerUrl)
+	assert.NoError(t, err)
+	unreachable := &mockRemotingClient{connectErr: fmt.Errorf("connection refused")}
+	invoker = NewDubboInvoker(url, remoting.NewExchangeClient(url, unreachable, time.Second, true))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = invoker.Ping(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 0, unreachable.requestCount())
+}
+
+func TestDubboInvokerRoutingTags(t *testing.T) {
+	tests := []struct {
+		name   string
+		params string
+		want   map[string]string
+	}{
+		{name: "none", params: "&env=gray", want: map[string]string{}},
+		{name: "tags", params: "&routing.tags=env,zone&env=gray&zone=hz", want: map[string]string{"env": "gray", "zone": "hz"}},
+		{name: "missing", params: "&routing.tags=env,%20zone,,&env=gray", want: map[string]string{"env": "gray"}},
+		{name: "empty", params: "&routing.tags=env,zone&env=&zone=hz", want: map[string]string{"zone": "hz"}},
+		{name: "dubbo.tag", params: "&dubbo.tag=gray", want: map[string]string{"dubbo.tag": "gray"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoker, _ := newMockInvoker(t, tt.params)
+			defer invoker.Destroy()
+			assert.Equal(t, tt.want, invoker.RoutingTags())
+		})
+	}
+}
+
+func TestDubboInvokerLabels(t *testing.T) {
+	control, _ := newMockInvoker(t, "&label.experiment=checkout&label.group=a")
+	defer control.Destroy()
+	treatment, _ := newMockInvoker(t, "&label.experiment=checkout&label.group=b&label.=ignored")
+	defer treatment.Destroy()
+	unlabeled, _ := newMockInvoker(t, "&group=b")
+	defer unlabeled.Destroy()
+	assert.Equal(t, map[string]string{"experiment": "checkout", "group": "b"}, treatment.Labels())
+	assert.Empty(t, unlabeled.Labels())
+
+	// the invoker without the Labels method is labeled by its url
+	url, err := common.NewURL(mockInvokerUrl + "&label.experiment=checkout&label.group=b")
+	assert.NoError(t, err)
+	base := protocol.NewBaseInvoker(url)
+
+	invokers := []protocol.Invoker{control, treatment, unlabeled, base}
+	assert.Equal(t, []protocol.Invoker{treatment, base},
+		protocol.SelectByLabels(invokers, protocol.MatchLabels(map[string]string{"experiment": "checkout", "group": "b"})))
+	assert.Equal(t, []protocol.Invoker{control, treatment, base},
+		protocol.SelectByLabels(invokers, protocol.MatchLabels(map[string]string{"experiment": "checkout"})))
+	assert.Equal(t, invokers, protocol.SelectByLabels(invokers, protocol.MatchLabels(nil)))
+	assert.Equal(t, []protocol.Invoker{unlabeled}, protocol.SelectByLabels(invokers, func(labels map[string]string) bool {
+		return len(labels) == 0
+	}))
+}
+
+func TestDubboInvokerCorrelationID(t *testing.T) {
+	invoker, client := newMockInvoker(t, "")
+	ctx := context.WithValue(context.Background(), constant.CORRELATION_ID_CTX_KEY, "req-1")
+	inv := newMockInvocation(nil)
+	res := invoker.Invoke(ctx, inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "req-1", inv.AttachmentsByKey(constant.CORRELATION_ID_KEY, ""))
+	request := client.requests[0].Data.(*protocol.Invocation)
+	assert.Equal(t, "req-1", (*request).Attachments()[constant.CORRELATION_ID_KEY])
+	assert.Equal(t, "req-1", res.Attachments()[constant.CORRELATION_ID_KEY])
+
+	// generated if absent
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.NotEmpty(t, res.Attachments()[constant.CORRELATION_ID_KEY])
+
+	// customized keys
+	type requestIDKey struct{}
+	SetCorrelationKeys(requestIDKey{}, "x-request-id")
+	defer SetCorrelationKeys(constant.CORRELATION_ID_CTX_KEY, constant.CORRELATION_ID_KEY)
+	inv = newMockInvocation(nil)
+	res = invoker.Invoke(context.WithValue(context.Background(), requestIDKey{}, "req-2"), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, "req-2", inv.AttachmentsByKey("x-request-id", ""))
+	assert.Equal(t, "req-2", res.Attachments()["x-request-id"])
+}
+
+func TestDubboInvokerLoadBalance(t *testing.T) {
+	invoker, _ := newMockInvoker(t, "")
+	assert.Equal(t, constant.DEFAULT_LOADBALANCE, invoker.LoadBalance(newMockInvocation(nil)))
+
+	invoker, _ = newMockInvoker(t, "&"+constant.LOADBALANCE_KEY+"=roundrobin&methods.GetUser."+
+		constant.LOADBALANCE_KEY+"=consistenthashing")
+	assert.Equal(t, "consistenthashing", invoker.LoadBalance(newMockInvocation(nil)))
+	other := invocation.NewRPCInvocationWithOptions(invocation.WithMethodName("GetUsers"))
+	assert.Equal(t, "roundrobin", invoker.LoadBalance(other))
+}
+
+func TestDubboInvokerGetTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		want    time.Duration
+	}{
+		{name: "bare milliseconds", timeout: "5000", want: 5 * time.Second},
+		{name: "duration", timeout: "5s", want: 5 * time.Second},
+		{name: "invalid", timeout: "abc", want: 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoker, _ := newMockInvoker(t, "&"+constant.TIMEOUT_KEY+"=3s&methods.GetUser."+constant.TIMEOUT_KEY+"="+tt.timeout)
+			inv := newMockInvocation(nil)
+			assert.Equal(t, tt.want, invoker.getTimeout(inv))
+			assert.Equal(t, strconv.Itoa(int(tt.want.Milliseconds())), inv.AttachmentsByKey(constant.TIMEOUT_KEY, ""))
+		})
+	}
+}
+
+func TestDubboInvokerTimeoutJitter(t *testing.T) {
+	// deterministic by default
+	invoker, _ := newMockInvoker(t, "&"+constant.TIMEOUT_KEY+"=3s")
+	assert.Equal(t, 3*time.Second, invoker.getTimeout(newMockInvocation(nil)))
+
+	invoker, _ = newMockInvoker(t, "&"+constant.TIMEOUT_KEY+"=3s&"+constant.TIMEOUT_JITTER_KEY+"=10")
+	timeouts := make(map[time.Duration]bool)
+	for i := 0; i < 100; i++ {
+		inv := newMockInvocation(nil)
+		timeout := invoker.getTimeout(inv)
+		assert.True(t, timeout >= 2700*time.Millisecond && timeout <= 3300*time.Millisecond, timeout)
+		assert.Equal(t, strconv.Itoa(int(timeout.Milliseconds())), inv.AttachmentsByKey(constant.TIMEOUT_KEY, ""))
+		timeouts[timeout] = true
+	}
+	assert.True(t, len(timeouts) > 1)
+
+	// the invalid jitter is ignored
+	invoker, _ = newMockInvoker(t, "&"+constant.TIMEOUT_KEY+"=3s&"+constant.TIMEOUT_JITTER_KEY+"=abc")
+	assert.Equal(t, 3*time.Second, invoker.getTimeout(newMockInvocation(nil)))
+}
+
+func TestDubboInvokerAsyncCallbackContext(t *testing.T) {
+	invokeAsync := func(ctx context.Context, invoker *DubboInvoker, client *mockRemotingClient) <-chan error {
+		observed := make(chan error, 1)
+		inv := newMockInvocation(map[string]interface{}{constant.ASYNC_KEY: "true"})
+		inv.SetCallBack(func(response common.CallbackResponse) {
+			observed <- response.(remoting.AsyncCallbackResponse).Ctx.Err()
+		})
+		res := invoker.Invoke(ctx, inv)
+		assert.NoError(t, res.Error())
+		assert.Equal(t, 1, client.requestCount())
+		return observed
+	}
+	reply := func(client *mockRemotingClient) {
+		(&remoting.Response{ID: client.requests[0].ID, Result: &protocol.RPCResult{}}).Handle()
+	}
+
+	// the context of the call is cancelled before the response arrives
+	invoker, client := newMockInvoker(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	observed := invokeAsync(ctx, invoker, client)
+	cancel()
+	reply(client)
+	assert.Equal(t, context.Canceled, <-observed)
+
+	// the timeout of the call is not inherited by default
+	invoker, client = newMockInvoker(t, "&"+constant.TIMEOUT_KEY+"=10ms")
+	observed = invokeAsync(context.Background(), invoker, client)
+	time.Sleep(20 * time.Millisecond)
+	reply(client)
+	assert.NoError(t, <-observed)
+
+	invoker, client = newMockInvoker(t, "&"+constant.TIMEOUT_KEY+"=10ms&"+constant.ASYNC_CALLBACK_INHERIT_TIMEOUT_KEY+"=true")
+	observed = invokeAsync(context.Background(), invoker, client)
+	time.Sleep(20 * time.Millisecond)
+	reply(client)
+	assert.Equal(t, context.DeadlineExceeded, <-observed)
+}
+
+// cacheListenerRegistry adds the config listeners to the CacheListener of zookeeper
+type cacheListenerRegistry struct {
+	*zookeeper.CacheListener
+}
+
+func (r cacheListenerRegistry) AddListener(key string, listener config_center.ConfigurationListener, opts ...config_center.Option) {
+	_ = r.CacheListener.AddListener(key, listener, opts...)
+}
+
+func (r cacheListenerRegistry) RemoveListener(key string, listener config_center.ConfigurationListener, _ ...config_center.Option) {
+	r.CacheListener.RemoveListener(key, listener)
+}
+
+type countingConfigListener struct {
+	events int
+}
+
+func (l *countingConfigListener) Process(*config_center.ConfigChangeEvent) {
+	l.events++
+}
+
+func TestDubboInvokerConfigListener(t *testing.T) {
+	cacheListener := zookeeper.NewCacheListener("/dubbo/config")
+	registry := cacheListenerRegistry{cacheListener}
+	event := remoting.Event{Path: "/dubbo/config/dubbo/test.condition-router", Action: remoting.EventTypeUpdate, Content: "v1"}
+	listener, other := &countingConfigListener{}, &countingConfigListener{}
+	registry.AddListener("test.condition-router", other)
+
+	invoker, _ := newMockInvoker(t, "")
+	invoker.AddConfigListener(registry, "test.condition-router", listener)
+	cacheListener.DataChange(event)
+	assert.Equal(t, 1, listener.events)
+
+	// only the listener of the invoker is removed
+	invoker.Destroy()
+	cacheListener.DataChange(event)
+	assert.Equal(t, 1, listener.events)
+	assert.Equal(t, 2, other.events)
+
+	// not added to the destroyed invoker
+	invoker.AddConfigListener(registry, "test.condition-router", listener)
+	cacheListener.DataChange(event)
+	assert.Equal(t, 1, listener.events)
+}
+
+func TestDubboInvokerStats(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	invoker, client := newMockInvoker(t, "&methods.GetUser."+constant.TIMEOUT_KEY+"=1s")
+	invoker.SetClock(clock)
+	assert.Equal(t, InvokerStats{}, invoker.Stats())
+
+	latencies := []time.Duration{
+		3 * time.Millisecond, 3 * time.Millisecond, 3 * time.Millisecond, 3 * time.Millisecond, 3 * time.Millisecond,
+		3 * time.Millisecond, 3 * time.Millisecond, 100 * time.Millisecond, 100 * time.Millisecond, 2 * time.Second,
+	}
+	for i, latency := range latencies {
+		latency := latency
+		// the last call fails past the timeout
+		failed := i == 8 || i == 9
+		client.handler = func(request *remoting.Request) (*protocol.RPCResult, error) {
+			assert.Equal(t, int64(1), invoker.Stats().InFlight)
+			clock.Advance(latency)
+			if failed {
+				return nil, perrors.New("failed")
+			}
+			return &protocol.RPCResult{}, nil
+		}
+		invoker.Invoke(context.Background(), newMockInvocation(nil))
+	}
+
+	stats := invoker.Stats()
+	assert.Equal(t, uint64(10), stats.Calls)
+	assert.Equal(t, uint64(8), stats.Successes)
+	assert.Equal(t, uint64(2), stats.Failures)
+	assert.Equal(t, uint64(1), stats.Timeouts)
+	assert.Equal(t, int64(0), stats.InFlight)
+	assert.Equal(t, 222100*time.Microsecond, stats.AvgLatency)
+	assert.Equal(t, 4*time.Millisecond, stats.P50Latency)
+	assert.Equal(t, 128*time.Millisecond, stats.P90Latency)
+	assert.Equal(t, 2048*time.Millisecond, stats.P99Latency)
+
+	// not tracked at all
+	invoker, _ = newMockInvoker(t, "&"+constant.STATS_ENABLED_KEY+"=false")
+	assert.NoError(t, invoker.Invoke(context.Background(), newMockInvocation(nil)).Error())
+	assert.Equal(t, InvokerStats{}, invoker.Stats())
+}
+
+func TestDubboInvokerLazyConnect(t *testing.T) {
+	url, err := common.NewURL(mockInvokerUrl + "&" + constant.LAZY_CONNECT_KEY + "=true")
+	assert.NoError(t, err)
+	client := &mockRemotingClient{}
+	dials := 0
+	invoker := newLazyDubboInvoker(url, func(url *common.URL) *remoting.ExchangeClient {
+		dials++
+		return remoting.NewExchangeClient(url, client, time.Second, false)
+	})
+	assert.Equal(t, 0, dials)
+	assert.Nil(t, invoker.getClient())
+	assert.True(t, invoker.IsAvailable())
+
+	for i := 0; i < 2; i++ {
+		res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+		assert.NoError(t, res.Error())
+	}
+	assert.Equal(t, 1, dials)
+	assert.Equal(t, 2, client.requestCount())
+	invoker.Destroy()
+	assert.Nil(t, invoker.getClient())
+
+	// destroyed before connecting
+	invoker = newLazyDubboInvoker(url, func(url *common.URL) *remoting.ExchangeClient {
+		dials++
+		return remoting.NewExchangeClient(url, client, time.Second, false)
+	})
+	invoker.Destroy()
+	assert.False(t, invoker.IsAvailable())
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.Equal(t, protocol.ErrDestroyedInvoker, res.Error())
+	assert.Equal(t, 1, dials)
+}
+
+// fakeClock is a Clock only moving forward when it is advanced
+type fakeClock struct {
+	lock sync.Mutex
+	now  time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestDubboInvokerClock(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	invoker, client := newMockInvoker(t, "&methods.GetUser."+constant.TIMEOUT_KEY+"=1s")
+	invoker.SetClock(clock)
+	elapsed := 500 * time.Millisecond
+	client.handler = func(*remoting.Request) (*protocol.RPCResult, error) {
+		clock.Advance(elapsed)
+		return &protocol.RPCResult{}, nil
+	}
+
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+
+	// the reply received past the method level timeout by the clock is still returned
+	elapsed = 2 * time.Second
+	inv := newMockInvocation(nil)
+	res = invoker.Invoke(context.Background(), inv)
+	assert.NoError(t, res.Error())
+	assert.Equal(t, inv.Reply(), res.Result())
+
+	latency, err := invoker.Ping(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Second, latency)
+}
+
+func TestDubboInvokerDeadline(t *testing.T) {
+	// only the relative timeout by default
+	invoker, _ := newMockInvoker(t, "")
+	inv := newMockInvocation(nil)
+	assert.NoError(t, invoker.Invoke(context.Background(), inv).Error())
+	assert.Nil(t, inv.Attachment(constant.DEADLINE_KEY))
+
+	// derived from the timeout
+	invoker, _ = newMockInvoker(t, "&"+constant.SEND_DEADLINE_KEY+"=true&methods.GetUser."+constant.TIMEOUT_KEY+"=1500")
+	invoker.SetClock(&fakeClock{now: time.Unix(100, 0)})
+	inv = newMockInvocation(nil)
+	assert.NoError(t, invoker.Invoke(context.Background(), inv).Error())
+	assert.Equal(t, "1500", inv.Attachment(constant.TIMEOUT_KEY))
+	assert.Equal(t, "101500", inv.Attachment(constant.DEADLINE_KEY))
+
+	// the deadline of the context takes precedence
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(1000, 0))
+	defer cancel()
+	inv = newMockInvocation(nil)
+	invoker.appendDeadline(ctx, inv, time.Second)
+	assert.Equal(t, "1000000", inv.Attachment(constant.DEADLINE_KEY))
+}
+
+func TestExchangeClientFactory(t *testing.T) {
+	assert.NotNil(t, GetExchangeClientFactory(""))
+
+	client := &mockRemotingClient{}
+	created := 0
+	SetExchangeClientFactory("in-process", func(url *common.URL) *remoting.ExchangeClient {
+		created++
+		return remoting.NewExchangeClient(url, client, time.Second, false)
+	})
+	url, err := common.NewURL("dubbo://127.0.0.1:20089/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&" +
+		constant.EXCHANGE_CLIENT_KEY + "=in-process")
+	assert.NoError(t, err)
+	invoker := NewDubboProtocol().Refer(url)
+	defer invoker.Destroy()
+	assert.Equal(t, 1, created)
+
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Equal(t, 1, client.requestCount())
+}
+
+func TestDubboInvokerRecycleConnection(t *testing.T) {
+	var clients []*mockRemotingClient
+	SetExchangeClientFactory("recycling", func(url *common.URL) *remoting.ExchangeClient {
+		client := &mockRemotingClient{}
+		clients = append(clients, client)
+		return remoting.NewExchangeClient(url, client, time.Second, false)
+	})
+	url, err := common.NewURL("dubbo://127.0.0.1:20090/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&" +
+		constant.EXCHANGE_CLIENT_KEY + "=recycling")
+	assert.NoError(t, err)
+	invoker := NewDubboProtocol().Refer(url).(*DubboInvoker)
+	defer invoker.Destroy()
+	res := invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Len(t, clients, 1)
+
+	invoker.RecycleConnection()
+	assert.True(t, clients[0].closed)
+	// still available in the gap
+	assert.True(t, invoker.IsAvailable())
+	assert.False(t, invoker.IsDestroyed())
+
+	// connected again at the next call
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.NoError(t, res.Error())
+	assert.Len(t, clients, 2)
+	assert.Equal(t, 1, clients[0].requestCount())
+	assert.Equal(t, 1, clients[1].requestCount())
+	assert.False(t, clients[1].closed)
+
+	// the destroyed invoker is not connected again
+	invoker.Destroy()
+	assert.True(t, clients[1].closed)
+	invoker.RecycleConnection()
+	assert.False(t, invoker.IsAvailable())
+	res = invoker.Invoke(context.Background(), newMockInvocation(nil))
+	assert.Equal(t, protocol.ErrDestroyedInvoker, res.Error())
+	assert.Len(t, clients, 2)
+}
+
 //
 //import (
 //	"bytes"
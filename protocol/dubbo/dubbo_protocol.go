@@ -24,10 +24,6 @@ import (
 	"time"
 )
 
-import (
-	"github.com/opentracing/opentracing-go"
-)
-
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
@@ -88,6 +84,17 @@ func (dp *DubboProtocol) Export(invoker protocol.Invoker) protocol.Exporter {
 
 // Refer create dubbo service reference.
 func (dp *DubboProtocol) Refer(url *common.URL) protocol.Invoker {
+	if blacklist.isBlacklisted(url) {
+		logger.Warnf("skip referring the blacklisted endpoint %s, which failed repeatedly", url.Location)
+		return nil
+	}
+	if url.GetParamBool(constant.LAZY_CONNECT_KEY, false) {
+		// the connection is made at the first call
+		invoker := newLazyDubboInvoker(url, getExchangeClient)
+		dp.SetInvokers(invoker)
+		logger.Infof("Refer service lazily: %s", url.String())
+		return invoker
+	}
 	exchangeClient := getExchangeClient(url)
 	if exchangeClient == nil {
 		logger.Warnf("can't dial the server: %+v", url.Location)
@@ -155,7 +162,7 @@ func doHandleRequest(rpcInvocation *invocation.RPCInvocation) protocol.RPCResult
 	invoker := exporter.(protocol.Exporter).GetInvoker()
 	if invoker != nil {
 		// FIXME
-		ctx := rebuildCtx(rpcInvocation)
+		ctx := rebuildCtx(rpcInvocation, GetTraceContextCodec(invoker.GetURL().GetParam(constant.TRACE_CODEC_KEY, "")))
 
 		invokeResult := invoker.Invoke(ctx, rpcInvocation)
 		if err := invokeResult.Error(); err != nil {
@@ -201,11 +208,7 @@ func getExchangeClient(url *common.URL) *remoting.ExchangeClient {
 			//	RequestTimeout: config.GetConsumerConfig().RequestTimeout,
 			//}), config.GetConsumerConfig().ConnectTimeout, false)
 
-			// todo set by config
-			exchangeClientTmp = remoting.NewExchangeClient(url, getty.NewClient(getty.Options{
-				ConnectTimeout: 3 * time.Second,
-				RequestTimeout: 3 * time.Second,
-			}), 3*time.Second, false)
+			exchangeClientTmp = newExchangeClient(url)
 			// input store
 			if exchangeClientTmp != nil {
 				exchangeClientMap.Store(url.Location, exchangeClientTmp)
@@ -226,13 +229,12 @@ func getExchangeClient(url *common.URL) *remoting.ExchangeClient {
 
 // rebuildCtx rebuild the context by attachment.
 // Once we decided to transfer more context's key-value, we should change this.
-// now we only support rebuild the tracing context
-func rebuildCtx(inv *invocation.RPCInvocation) context.Context {
+// now we only support rebuild the tracing context, which is extracted by @codec
+func rebuildCtx(inv *invocation.RPCInvocation, codec TraceContextCodec) context.Context {
 	ctx := context.WithValue(context.Background(), constant.DubboCtxKey("attachment"), inv.Attachments())
 
 	// actually, if user do not use any opentracing framework, the err will not be nil.
-	spanCtx, err := opentracing.GlobalTracer().Extract(opentracing.TextMap,
-		opentracing.TextMapCarrier(filterContext(inv.Attachments())))
+	spanCtx, err := codec.Extract(inv.Attachments())
 	if err == nil {
 		ctx = context.WithValue(ctx, constant.DubboCtxKey(constant.TRACING_REMOTE_SPAN_CTX), spanCtx)
 	}
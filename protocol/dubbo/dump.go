@@ -0,0 +1,123 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/common/logger"
+	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+const (
+	// the max length of a value rendered in the dump
+	maxDumpValueLen = 256
+	redactedValue   = "******"
+)
+
+// AttachmentRedactor reports whether the value of the attachment @key is sensitive and redacted in the dump
+type AttachmentRedactor func(key string) bool
+
+var (
+	attachmentRedactor     AttachmentRedactor = isSensitiveAttachment
+	attachmentRedactorLock sync.RWMutex
+
+	// logs the dump of the invocations, replaced in tests
+	logInvocationDump = func(dump string) {
+		logger.Infof("%s", dump)
+	}
+)
+
+// SetAttachmentRedactor replaces the redactor of the attachments in the dump, nil restores the default one
+// which redacts the keys containing password, secret, token or authorization
+func SetAttachmentRedactor(redactor AttachmentRedactor) {
+	attachmentRedactorLock.Lock()
+	defer attachmentRedactorLock.Unlock()
+	if redactor == nil {
+		redactor = isSensitiveAttachment
+	}
+	attachmentRedactor = redactor
+}
+
+func getAttachmentRedactor() AttachmentRedactor {
+	attachmentRedactorLock.RLock()
+	defer attachmentRedactorLock.RUnlock()
+	return attachmentRedactor
+}
+
+func isSensitiveAttachment(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range []string{"password", "secret", "token", "authorization"} {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// dumpInvocation logs the method, arguments and attachments of @inv about to be sent if the url param
+// dump.invocation is true, the sensitive attachments are redacted by the AttachmentRedactor
+func dumpInvocation(url *common.URL, inv *invocation_impl.RPCInvocation) {
+	if !url.GetParamBool(constant.DUMP_INVOCATION_KEY, false) {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Invoke %s.%s on %s, arguments [", url.GetParam(constant.INTERFACE_KEY, ""), inv.MethodName(), url.Location)
+	for i, arg := range inv.Arguments() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%T(%s)", arg, renderDumpValue(arg))
+	}
+	b.WriteString("], attachments {")
+	attachments := inv.Attachments()
+	keys := make([]string, 0, len(attachments))
+	for k := range attachments {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	redactor := getAttachmentRedactor()
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		value := redactedValue
+		if !redactor(k) {
+			value = renderDumpValue(attachments[k])
+		}
+		fmt.Fprintf(&b, "%s: %s", k, value)
+	}
+	b.WriteString("}")
+	logInvocationDump(b.String())
+}
+
+// renderDumpValue renders @v in at most maxDumpValueLen bytes
+func renderDumpValue(v interface{}) string {
+	rendered := fmt.Sprintf("%+v", v)
+	if len(rendered) > maxDumpValueLen {
+		rendered = rendered[:maxDumpValueLen] + "..."
+	}
+	return rendered
+}
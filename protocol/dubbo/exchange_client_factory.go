@@ -0,0 +1,78 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"sync"
+	"time"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	"dubbo.apache.org/dubbo-go/v3/remoting"
+	"dubbo.apache.org/dubbo-go/v3/remoting/getty"
+)
+
+const (
+	// DefaultExchangeClientFactory is the name of the factory creating the tcp connections by getty
+	DefaultExchangeClientFactory = "getty"
+)
+
+// ExchangeClientFactory creates the exchange client connecting to the provider of @url, e.g. over QUIC,
+// or an in-process transport for tests. It returns nil if the provider can't be connected.
+type ExchangeClientFactory func(url *common.URL) *remoting.ExchangeClient
+
+var (
+	exchangeClientFactories = map[string]ExchangeClientFactory{
+		DefaultExchangeClientFactory: newGettyExchangeClient,
+	}
+	exchangeClientFactoriesLock sync.RWMutex
+)
+
+// SetExchangeClientFactory registers the factory with @name, which can be selected by the exchange.client param of the url
+func SetExchangeClientFactory(name string, factory ExchangeClientFactory) {
+	exchangeClientFactoriesLock.Lock()
+	defer exchangeClientFactoriesLock.Unlock()
+	exchangeClientFactories[name] = factory
+}
+
+// GetExchangeClientFactory returns the factory registered with @name, or the default factory if it is absent
+func GetExchangeClientFactory(name string) ExchangeClientFactory {
+	exchangeClientFactoriesLock.RLock()
+	defer exchangeClientFactoriesLock.RUnlock()
+	if factory, ok := exchangeClientFactories[name]; ok {
+		return factory
+	}
+	return exchangeClientFactories[DefaultExchangeClientFactory]
+}
+
+// newExchangeClient creates the exchange client of @url by the factory selected in the url
+func newExchangeClient(url *common.URL) *remoting.ExchangeClient {
+	return GetExchangeClientFactory(url.GetParam(constant.EXCHANGE_CLIENT_KEY, DefaultExchangeClientFactory))(url)
+}
+
+func newGettyExchangeClient(url *common.URL) *remoting.ExchangeClient {
+	// todo set by config
+	return remoting.NewExchangeClient(url, getty.NewClient(getty.Options{
+		ConnectTimeout: 3 * time.Second,
+		RequestTimeout: 3 * time.Second,
+		Compression:    negotiateConnectionCompression(url),
+		ConnectionNum:  int(url.GetParamInt(constant.AFFINITY_CONNECTIONS_KEY, 0)),
+	}), 3*time.Second, false)
+}
@@ -0,0 +1,53 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+)
+
+import (
+	"github.com/satori/go.uuid"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
+)
+
+// appendIdempotencyKey attaches the idempotency key of the call for the provider to dedupe the retried requests,
+// if it is enabled by the url param idempotency.enabled, which can be set per method. The key is taken from the
+// attachment, then the context, and generated if both are absent. Since it stays in the attachments of the
+// invocation, the retries of the same call, either resent by the invoker or invoked again by the cluster, reuse it.
+func appendIdempotencyKey(ctx context.Context, url *common.URL, inv *invocation_impl.RPCInvocation) {
+	if !url.GetMethodParamBool(inv.MethodName(), constant.IDEMPOTENCY_ENABLED_KEY,
+		url.GetParamBool(constant.IDEMPOTENCY_ENABLED_KEY, false)) {
+		return
+	}
+	key := inv.AttachmentsByKey(constant.IDEMPOTENCY_KEY, "")
+	if len(key) == 0 && ctx != nil {
+		key, _ = ctx.Value(constant.IDEMPOTENCY_CTX_KEY).(string)
+	}
+	if len(key) == 0 {
+		if u, err := uuid.NewV4(); err == nil {
+			key = u.String()
+		}
+	}
+	inv.SetAttachments(constant.IDEMPOTENCY_KEY, key)
+}
@@ -527,5 +527,5 @@ func ToMapStringInterface(origin map[interface{}]interface{}) map[string]interfa
 }
 
 func init() {
-	SetSerializer("hessian2", HessianSerializer{})
+	RegisterSerialization(constant.HESSIAN2_SERIALIZATION, constant.S_Hessian2, HessianSerializer{})
 }
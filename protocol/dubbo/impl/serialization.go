@@ -19,6 +19,7 @@ package impl
 
 import (
 	"fmt"
+	"sync"
 )
 
 import (
@@ -27,21 +28,45 @@ import (
 
 var (
 	serializers = make(map[string]Serializer)
-	nameMaps    = make(map[byte]string)
-)
-
-func init() {
-	nameMaps = map[byte]string{
+	nameMaps    = map[byte]string{
 		constant.S_Hessian2: constant.HESSIAN2_SERIALIZATION,
 		constant.S_Proto:    constant.PROTOBUF_SERIALIZATION,
 	}
-}
+	serializersLock sync.RWMutex
+)
 
 func SetSerializer(name string, serializer Serializer) {
+	serializersLock.Lock()
+	defer serializersLock.Unlock()
 	serializers[name] = serializer
 }
 
+// RegisterSerialization registers @serializer with @name, and @id carried in the header of the packages
+// serialized by it, which must fit in the serialization mask
+func RegisterSerialization(name string, id byte, serializer Serializer) {
+	serializersLock.Lock()
+	defer serializersLock.Unlock()
+	serializers[name] = serializer
+	nameMaps[id&SERIAL_MASK] = name
+}
+
+// GetSerializationID returns the id of the serialization registered with @name
+func GetSerializationID(name string) (byte, error) {
+	serializersLock.RLock()
+	defer serializersLock.RUnlock()
+	if _, ok := serializers[name]; ok {
+		for id, n := range nameMaps {
+			if n == name {
+				return id, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("serialization %s not found", name)
+}
+
 func GetSerializerById(id byte) (Serializer, error) {
+	serializersLock.RLock()
+	defer serializersLock.RUnlock()
 	name, ok := nameMaps[id]
 	if !ok {
 		panic(fmt.Sprintf("serialId %d not found", id))
@@ -52,3 +77,14 @@ func GetSerializerById(id byte) (Serializer, error) {
 	}
 	return serializer, nil
 }
+
+// GetSerializerByName returns the serializer registered with @name, unlike GetSerializerById it doesn't panic
+func GetSerializerByName(name string) (Serializer, error) {
+	serializersLock.RLock()
+	defer serializersLock.RUnlock()
+	serializer, ok := serializers[name]
+	if !ok {
+		return nil, fmt.Errorf("serialization %s not found", name)
+	}
+	return serializer, nil
+}
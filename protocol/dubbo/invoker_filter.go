@@ -0,0 +1,90 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
+)
+
+// InvokerFilter runs around the remoting call of every DubboInvoker. Unlike the filters of the
+// filter SPI it is scoped to the dubbo protocol and sees the resolved url of the invoker.
+type InvokerFilter interface {
+	// Before is called before the remoting call, a non-nil result short-circuits the call
+	// and is passed to the After of the filters ahead of it instead.
+	Before(ctx context.Context, url *common.URL, invocation protocol.Invocation) protocol.Result
+	// After is called with the result of the call, the returned result replaces it.
+	After(ctx context.Context, url *common.URL, invocation protocol.Invocation, result protocol.Result) protocol.Result
+}
+
+type invokerFilterEntry struct {
+	name   string
+	order  int
+	filter InvokerFilter
+}
+
+var (
+	invokerFiltersLock sync.RWMutex
+	invokerFilters     []invokerFilterEntry
+)
+
+// SetInvokerFilter registers @filter with @name, the filters with a lower @order run their Before first
+// and their After last. Registering a filter with an existing name replaces it.
+func SetInvokerFilter(name string, order int, filter InvokerFilter) {
+	invokerFiltersLock.Lock()
+	defer invokerFiltersLock.Unlock()
+
+	filters := make([]invokerFilterEntry, 0, len(invokerFilters)+1)
+	for _, e := range invokerFilters {
+		if e.name != name {
+			filters = append(filters, e)
+		}
+	}
+	filters = append(filters, invokerFilterEntry{name: name, order: order, filter: filter})
+	sort.SliceStable(filters, func(i, j int) bool {
+		return filters[i].order < filters[j].order
+	})
+	invokerFilters = filters
+}
+
+// RemoveInvokerFilter removes the filter registered with @name
+func RemoveInvokerFilter(name string) {
+	invokerFiltersLock.Lock()
+	defer invokerFiltersLock.Unlock()
+
+	filters := make([]invokerFilterEntry, 0, len(invokerFilters))
+	for _, e := range invokerFilters {
+		if e.name != name {
+			filters = append(filters, e)
+		}
+	}
+	invokerFilters = filters
+}
+
+// getInvokerFilters returns a snapshot of the registered filters in order, it must not be modified.
+func getInvokerFilters() []invokerFilterEntry {
+	invokerFiltersLock.RLock()
+	defer invokerFiltersLock.RUnlock()
+	return invokerFilters
+}
@@ -0,0 +1,82 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"sync"
+)
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
+)
+
+// InvokerState is the state of an active invoker
+type InvokerState struct {
+	URL       *common.URL
+	Available bool
+}
+
+var (
+	activeInvokersLock sync.RWMutex
+	// activeInvokers stores the invokers not destroyed yet keyed by interface
+	activeInvokers = make(map[string]map[*DubboInvoker]struct{})
+)
+
+// GetActiveInvokers returns the states of the invokers of @interfaceName which are not destroyed yet
+func GetActiveInvokers(interfaceName string) []InvokerState {
+	activeInvokersLock.RLock()
+	invokers := make([]*DubboInvoker, 0, len(activeInvokers[interfaceName]))
+	for invoker := range activeInvokers[interfaceName] {
+		invokers = append(invokers, invoker)
+	}
+	activeInvokersLock.RUnlock()
+
+	// IsAvailable may touch the network client, so call it out of the lock
+	states := make([]InvokerState, 0, len(invokers))
+	for _, invoker := range invokers {
+		states = append(states, InvokerState{URL: invoker.GetURL(), Available: invoker.IsAvailable()})
+	}
+	return states
+}
+
+func addActiveInvoker(invoker *DubboInvoker) {
+	interfaceName := invoker.GetURL().GetParam(constant.INTERFACE_KEY, "")
+	activeInvokersLock.Lock()
+	defer activeInvokersLock.Unlock()
+
+	invokers, ok := activeInvokers[interfaceName]
+	if !ok {
+		invokers = make(map[*DubboInvoker]struct{})
+		activeInvokers[interfaceName] = invokers
+	}
+	invokers[invoker] = struct{}{}
+}
+
+func removeActiveInvoker(invoker *DubboInvoker) {
+	interfaceName := invoker.GetURL().GetParam(constant.INTERFACE_KEY, "")
+	activeInvokersLock.Lock()
+	defer activeInvokersLock.Unlock()
+
+	if invokers, ok := activeInvokers[interfaceName]; ok {
+		delete(invokers, invoker)
+		if len(invokers) == 0 {
+			delete(activeInvokers, interfaceName)
+		}
+	}
+}
@@ -17,6 +17,10 @@
 
 package dubbo
 
+import (
+	"sync"
+)
+
 import (
 	"github.com/opentracing/opentracing-go"
 )
@@ -25,17 +29,68 @@ import (
 	invocation_impl "dubbo.apache.org/dubbo-go/v3/protocol/invocation"
 )
 
-func injectTraceCtx(currentSpan opentracing.Span, inv *invocation_impl.RPCInvocation) error {
-	// inject opentracing ctx
-	traceAttachments := filterContext(inv.Attachments())
+const (
+	// DefaultTraceContextCodec is the name of the codec using the text map format of the global tracer
+	DefaultTraceContextCodec = "default"
+
+	// log the failure of injecting the trace context, and invoke without it
+	traceInjectFailureLog = "log"
+	// fail the invocation whose trace context fails to be injected
+	traceInjectFailureFail = "fail"
+)
+
+var traceContextCodecs = map[string]TraceContextCodec{
+	DefaultTraceContextCodec: &textMapTraceContextCodec{},
+}
+
+var traceContextCodecsLock sync.RWMutex
+
+// TraceContextCodec serializes the span context into the attachments on the consumer side,
+// and deserializes it from the attachments on the provider side, e.g. as B3 or W3C traceparent headers.
+type TraceContextCodec interface {
+	Inject(spanCtx opentracing.SpanContext, attachments map[string]interface{}) error
+	Extract(attachments map[string]interface{}) (opentracing.SpanContext, error)
+}
+
+// SetTraceContextCodec registers the codec with @name, which can be selected by the trace.codec param of the url
+func SetTraceContextCodec(name string, codec TraceContextCodec) {
+	traceContextCodecsLock.Lock()
+	defer traceContextCodecsLock.Unlock()
+	traceContextCodecs[name] = codec
+}
+
+// GetTraceContextCodec returns the codec registered with @name, or the default codec if it is absent
+func GetTraceContextCodec(name string) TraceContextCodec {
+	traceContextCodecsLock.RLock()
+	defer traceContextCodecsLock.RUnlock()
+	if codec, ok := traceContextCodecs[name]; ok {
+		return codec
+	}
+	return traceContextCodecs[DefaultTraceContextCodec]
+}
+
+// textMapTraceContextCodec injects and extracts the span context with the text map format of the global tracer
+type textMapTraceContextCodec struct{}
+
+func (c *textMapTraceContextCodec) Inject(spanCtx opentracing.SpanContext, attachments map[string]interface{}) error {
+	traceAttachments := filterContext(attachments)
 	carrier := opentracing.TextMapCarrier(traceAttachments)
-	err := opentracing.GlobalTracer().Inject(currentSpan.Context(), opentracing.TextMap, carrier)
+	err := opentracing.GlobalTracer().Inject(spanCtx, opentracing.TextMap, carrier)
 	if err == nil {
-		fillTraceAttachments(inv.Attachments(), traceAttachments)
+		fillTraceAttachments(attachments, traceAttachments)
 	}
 	return err
 }
 
+func (c *textMapTraceContextCodec) Extract(attachments map[string]interface{}) (opentracing.SpanContext, error) {
+	return opentracing.GlobalTracer().Extract(opentracing.TextMap, opentracing.TextMapCarrier(filterContext(attachments)))
+}
+
+func injectTraceCtx(codec TraceContextCodec, currentSpan opentracing.Span, inv *invocation_impl.RPCInvocation) error {
+	// inject opentracing ctx
+	return codec.Inject(currentSpan.Context(), inv.Attachments())
+}
+
 func filterContext(attachments map[string]interface{}) map[string]string {
 	traceAttchment := make(map[string]string)
 	for k, v := range attachments {
@@ -0,0 +1,35 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"dubbo.apache.org/dubbo-go/v3/protocol/dubbo/impl"
+)
+
+// SerializationCodec marshals and unmarshals the bodies of the dubbo packages
+type SerializationCodec interface {
+	impl.Serializer
+	// ID is the serialization id carried in the header of the packages, which must be unique and less than 32
+	ID() byte
+}
+
+// RegisterSerialization registers @codec with @name, which can be selected by the url param serialization of
+// the invoker, or the attachment of the same key for a single call. The built-in hessian2 is registered the same way.
+func RegisterSerialization(name string, codec SerializationCodec) {
+	impl.RegisterSerialization(name, codec.ID(), codec)
+}
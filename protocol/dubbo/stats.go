@@ -0,0 +1,141 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"time"
+)
+
+import (
+	"go.uber.org/atomic"
+)
+
+// the upper bound of the first latency bucket, each of the following ones doubles it
+const (
+	firstLatencyBucket = time.Millisecond
+	latencyBuckets     = 24
+)
+
+// InvokerStats is the snapshot of the cumulative stats of the remoting calls of a DubboInvoker
+type InvokerStats struct {
+	// Calls is the number of the finished calls
+	Calls uint64
+	// Successes is the number of the calls without error
+	Successes uint64
+	// Failures is the number of the calls failed, including the timeouts
+	Failures uint64
+	// Timeouts is the number of the calls failed once their timeouts elapsed
+	Timeouts uint64
+	// InFlight is the number of the calls not finished yet
+	InFlight int64
+	// AvgLatency is the average latency of the finished calls
+	AvgLatency time.Duration
+	// P50Latency, P90Latency and P99Latency are the percentiles of the latencies, which are rounded up to the
+	// latency buckets doubling from 1ms
+	P50Latency time.Duration
+	P90Latency time.Duration
+	P99Latency time.Duration
+}
+
+// invokerStats maintains the stats of the calls with atomic counters, the latencies are counted by buckets
+type invokerStats struct {
+	successes atomic.Uint64
+	failures  atomic.Uint64
+	timeouts  atomic.Uint64
+	inFlight  atomic.Int64
+	// the total latency of the finished calls in nanoseconds
+	latency atomic.Int64
+	// the number of the calls of the latency within each bucket, the last one is unbounded
+	buckets [latencyBuckets + 1]atomic.Uint64
+}
+
+func (s *invokerStats) start() {
+	s.inFlight.Inc()
+}
+
+// finish records the call finished in @latency with @err, which timed out if @timedOut
+func (s *invokerStats) finish(latency time.Duration, err error, timedOut bool) {
+	s.inFlight.Dec()
+	if err == nil {
+		s.successes.Inc()
+	} else {
+		s.failures.Inc()
+		if timedOut {
+			s.timeouts.Inc()
+		}
+	}
+	s.latency.Add(int64(latency))
+	bucket, bound := 0, firstLatencyBucket
+	for bucket < latencyBuckets && latency > bound {
+		bucket++
+		bound *= 2
+	}
+	s.buckets[bucket].Inc()
+}
+
+func (s *invokerStats) snapshot() InvokerStats {
+	stats := InvokerStats{
+		Successes: s.successes.Load(),
+		Failures:  s.failures.Load(),
+		Timeouts:  s.timeouts.Load(),
+		InFlight:  s.inFlight.Load(),
+	}
+	var counts [latencyBuckets + 1]uint64
+	for i := range s.buckets {
+		counts[i] = s.buckets[i].Load()
+		stats.Calls += counts[i]
+	}
+	if stats.Calls == 0 {
+		return stats
+	}
+	stats.AvgLatency = time.Duration(s.latency.Load() / int64(stats.Calls))
+	stats.P50Latency = percentileOf(counts[:], stats.Calls, 0.5)
+	stats.P90Latency = percentileOf(counts[:], stats.Calls, 0.9)
+	stats.P99Latency = percentileOf(counts[:], stats.Calls, 0.99)
+	return stats
+}
+
+// percentileOf returns the upper bound of the bucket where the @percentile of the @total latencies falls,
+// the unbounded last bucket reports the lower bound of it
+func percentileOf(counts []uint64, total uint64, percentile float64) time.Duration {
+	rank := uint64(percentile*float64(total) + 0.5)
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	bound := firstLatencyBucket
+	for i, count := range counts {
+		if seen += count; seen >= rank || i == len(counts)-1 {
+			if i == len(counts)-1 {
+				return bound / 2
+			}
+			return bound
+		}
+		bound *= 2
+	}
+	return bound
+}
+
+// Stats returns the snapshot of the cumulative stats of the remoting calls of the invoker, which are tracked unless
+// the url param stats.enabled is false. The calls rejected before sending, e.g. by the filters, are not counted.
+func (di *DubboInvoker) Stats() InvokerStats {
+	if di.stats == nil {
+		return InvokerStats{}
+	}
+	return di.stats.snapshot()
+}
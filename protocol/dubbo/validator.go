@@ -0,0 +1,50 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package dubbo
+
+import (
+	"sync"
+)
+
+// InvokeValidator checks the arguments of an invocation on the consumer side before it is sent,
+// a non-nil error short-circuits the invocation without any network call.
+type InvokeValidator func(arguments []interface{}) error
+
+// invokeValidators stores the validators keyed by interface and method
+var invokeValidators sync.Map
+
+// SetInvokeValidator registers the validator for @method of @interfaceName
+func SetInvokeValidator(interfaceName string, method string, validator InvokeValidator) {
+	invokeValidators.Store(validatorKey(interfaceName, method), validator)
+}
+
+// RemoveInvokeValidator removes the validator for @method of @interfaceName
+func RemoveInvokeValidator(interfaceName string, method string) {
+	invokeValidators.Delete(validatorKey(interfaceName, method))
+}
+
+func getInvokeValidator(interfaceName string, method string) InvokeValidator {
+	if v, ok := invokeValidators.Load(validatorKey(interfaceName, method)); ok {
+		return v.(InvokeValidator)
+	}
+	return nil
+}
+
+func validatorKey(interfaceName string, method string) string {
+	return interfaceName + "." + method
+}
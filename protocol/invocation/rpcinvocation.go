@@ -168,6 +168,9 @@ func (r *RPCInvocation) SetAttachments(key string, value interface{}) {
 func (r *RPCInvocation) SetAttribute(key string, value interface{}) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
+	if r.attributes == nil {
+		r.attributes = make(map[string]interface{})
+	}
 	r.attributes[key] = value
 }
 
@@ -19,6 +19,7 @@ package protocol
 
 import (
 	"context"
+	"strings"
 )
 
 import (
@@ -29,6 +30,7 @@ import (
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 )
 
@@ -50,6 +52,60 @@ type Invoker interface {
 	Invoke(context.Context, Invocation) Result
 }
 
+// SourceRegistry returns the registry @invoker is discovered from, which is the registry id or the registry address,
+// empty if the invoker is not discovered from any registry, e.g. it is referred to directly
+func SourceRegistry(invoker Invoker) string {
+	return invoker.GetURL().GetParam(constant.REGISTRY_SOURCE_KEY, "")
+}
+
+// LabelSelector selects the invokers by their labels
+type LabelSelector func(labels map[string]string) bool
+
+// LabelsOfURL returns the custom labels in @url, which are the params prefixed with "label." without the prefix,
+// e.g. "label.experiment=b" gives {"experiment": "b"}
+func LabelsOfURL(url *common.URL) map[string]string {
+	labels := make(map[string]string)
+	url.RangeParams(func(key, value string) bool {
+		if name := strings.TrimPrefix(key, constant.LABEL_PREFIX); len(name) > 0 && len(name) < len(key) {
+			labels[name] = value
+		}
+		return true
+	})
+	return labels
+}
+
+// Labels returns the custom labels of @invoker, which are given by the invoker itself if it has the Labels method,
+// otherwise by the params of its url prefixed with "label."
+func Labels(invoker Invoker) map[string]string {
+	if labeled, ok := invoker.(interface{ Labels() map[string]string }); ok {
+		return labeled.Labels()
+	}
+	return LabelsOfURL(invoker.GetURL())
+}
+
+// MatchLabels selects the invokers having all the labels of @selector with the same values
+func MatchLabels(selector map[string]string) LabelSelector {
+	return func(labels map[string]string) bool {
+		for name, value := range selector {
+			if v, ok := labels[name]; !ok || v != value {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// SelectByLabels returns the invokers of @invokers whose labels are selected by @selector, in the same order
+func SelectByLabels(invokers []Invoker, selector LabelSelector) []Invoker {
+	selected := make([]Invoker, 0, len(invokers))
+	for _, invoker := range invokers {
+		if selector(Labels(invoker)) {
+			selected = append(selected, invoker)
+		}
+	}
+	return selected
+}
+
 /////////////////////////////
 // base invoker
 /////////////////////////////
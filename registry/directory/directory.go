@@ -50,12 +50,37 @@ func init() {
 	extension.SetDefaultRegistryDirectory(NewRegistryDirectory)
 }
 
+// InvokersChangeListener is notified with the urls of the invokers added and removed by each refresh of the
+// invokers from the registry, e.g. to warm the caches for the new providers. An updated provider is both removed
+// and added, for its invoker is replaced.
+type InvokersChangeListener func(added []*common.URL, removed []*common.URL)
+
+var (
+	invokersChangeListener     InvokersChangeListener
+	invokersChangeListenerLock sync.RWMutex
+)
+
+// SetInvokersChangeListener sets the listener of the invokers changes of all the registry directories, nil removes it
+func SetInvokersChangeListener(listener InvokersChangeListener) {
+	invokersChangeListenerLock.Lock()
+	defer invokersChangeListenerLock.Unlock()
+	invokersChangeListener = listener
+}
+
+func getInvokersChangeListener() InvokersChangeListener {
+	invokersChangeListenerLock.RLock()
+	defer invokersChangeListenerLock.RUnlock()
+	return invokersChangeListener
+}
+
 // RegistryDirectory implementation of Directory:
 // Invoker list returned from this Directory's list method have been filtered by Routers
 type RegistryDirectory struct {
 	base.Directory
 	cacheInvokers                  []protocol.Invoker
 	invokersLock                   sync.RWMutex
+	// the cached invokers last notified to the InvokersChangeListener
+	notifiedInvokers map[protocol.Invoker]struct{}
 	serviceType                    string
 	registry                       registry.Registry
 	cacheInvokersMap               *sync.Map // use sync.map
@@ -235,6 +260,35 @@ func (dir *RegistryDirectory) setNewInvokers() {
 	defer dir.invokersLock.Unlock()
 	dir.cacheInvokers = newInvokers
 	dir.RouterChain().SetInvokers(newInvokers)
+	dir.notifyInvokersChange()
+}
+
+// notifyInvokersChange notifies the listener with the invokers added to and removed from the cache since the last
+// notification, the caller must hold the invokersLock
+func (dir *RegistryDirectory) notifyInvokersChange() {
+	listener := getInvokersChangeListener()
+	if listener == nil {
+		return
+	}
+	var added, removed []*common.URL
+	current := make(map[protocol.Invoker]struct{})
+	dir.cacheInvokersMap.Range(func(_, v interface{}) bool {
+		invoker := v.(protocol.Invoker)
+		current[invoker] = struct{}{}
+		if _, ok := dir.notifiedInvokers[invoker]; !ok {
+			added = append(added, invoker.GetURL())
+		}
+		return true
+	})
+	for invoker := range dir.notifiedInvokers {
+		if _, ok := current[invoker]; !ok {
+			removed = append(removed, invoker.GetURL())
+		}
+	}
+	dir.notifiedInvokers = current
+	if len(added) > 0 || len(removed) > 0 {
+		listener(added, removed)
+	}
 }
 
 // cacheInvokerByEvent caches invokers from the service event
@@ -349,6 +403,7 @@ func (dir *RegistryDirectory) cacheInvoker(url *common.URL, event *registry.Serv
 	// check the url's protocol is equal to the protocol which is configured in reference config or referenceUrl is not care about protocol
 	if url.Protocol == referenceUrl.Protocol || referenceUrl.Protocol == "" {
 		newUrl := common.MergeURL(url, referenceUrl)
+		newUrl.SetParam(constant.REGISTRY_SOURCE_KEY, dir.sourceRegistry())
 		dir.overrideUrl(newUrl)
 		event.Update(newUrl)
 		if v, ok := dir.doCacheInvoker(newUrl, event); ok {
@@ -358,6 +413,16 @@ func (dir *RegistryDirectory) cacheInvoker(url *common.URL, event *registry.Serv
 	return nil
 }
 
+// sourceRegistry identifies the registry of the directory by the id of its config,
+// or by its protocol and address if it is not loaded from the config
+func (dir *RegistryDirectory) sourceRegistry() string {
+	registryURL := dir.GetDirectoryUrl()
+	if id := registryURL.GetParam(constant.REGISTRY_ID_KEY, ""); len(id) > 0 {
+		return id
+	}
+	return registryURL.GetParam(constant.REGISTRY_KEY, registryURL.Protocol) + "://" + registryURL.Location
+}
+
 func (dir *RegistryDirectory) doCacheInvoker(newUrl *common.URL, event *registry.ServiceEvent) (protocol.Invoker, bool) {
 	key := event.Key()
 	if cacheInvoker, ok := dir.cacheInvokersMap.Load(key); !ok {
@@ -18,7 +18,9 @@
 package directory
 
 import (
+	"sort"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
@@ -33,6 +35,7 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/extension"
 	"dubbo.apache.org/dubbo-go/v3/config"
+	"dubbo.apache.org/dubbo-go/v3/protocol"
 	"dubbo.apache.org/dubbo-go/v3/protocol/invocation"
 	"dubbo.apache.org/dubbo-go/v3/protocol/protocolwrapper"
 	"dubbo.apache.org/dubbo-go/v3/registry"
@@ -125,6 +128,74 @@ Loop1:
 	}
 }
 
+func TestSourceRegistry(t *testing.T) {
+	extension.SetProtocol(protocolwrapper.FILTER, protocolwrapper.NewMockProtocolFilter)
+	suburl, _ := common.NewURL("dubbo://127.0.0.1:20000/org.apache.dubbo-go.mockService")
+	newDir := func(registryURL *common.URL) (*RegistryDirectory, *registry.MockRegistry) {
+		registryURL.SubURL = suburl
+		mockRegistry, _ := registry.NewMockRegistry(&common.URL{})
+		dir, _ := NewRegistryDirectory(registryURL, mockRegistry)
+		return dir.(*RegistryDirectory), mockRegistry.(*registry.MockRegistry)
+	}
+	shanghaiURL, _ := common.NewURL("registry://127.0.0.1:2181",
+		common.WithParamsValue(constant.REGISTRY_KEY, "zookeeper"),
+		common.WithParamsValue(constant.REGISTRY_ID_KEY, "shanghai"))
+	shanghai, shanghaiRegistry := newDir(shanghaiURL)
+	beijingURL, _ := common.NewURL("registry://127.0.0.2:8848", common.WithParamsValue(constant.REGISTRY_KEY, "nacos"))
+	beijing, beijingRegistry := newDir(beijingURL)
+
+	providerURL, _ := common.NewURL("dubbo://0.0.0.0:20000/org.apache.dubbo-go.mockService")
+	shanghaiRegistry.MockEvent(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: providerURL.Clone()})
+	beijingRegistry.MockEvent(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: providerURL.Clone()})
+	time.Sleep(1e9)
+
+	assert.Len(t, shanghai.cacheInvokers, 1)
+	assert.Equal(t, "shanghai", protocol.SourceRegistry(shanghai.cacheInvokers[0]))
+	assert.Len(t, beijing.cacheInvokers, 1)
+	assert.Equal(t, "nacos://127.0.0.2:8848", protocol.SourceRegistry(beijing.cacheInvokers[0]))
+}
+
+func TestInvokersChangeListener(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		changes [][2][]string
+	)
+	locations := func(urls []*common.URL) []string {
+		result := make([]string, 0, len(urls))
+		for _, u := range urls {
+			result = append(result, u.Location)
+		}
+		sort.Strings(result)
+		return result
+	}
+	SetInvokersChangeListener(func(added []*common.URL, removed []*common.URL) {
+		lock.Lock()
+		defer lock.Unlock()
+		changes = append(changes, [2][]string{locations(added), locations(removed)})
+	})
+	defer SetInvokersChangeListener(nil)
+
+	_, mockRegistry := normalRegistryDir(true)
+	newProvider := func(location string) *common.URL {
+		providerUrl, _ := common.NewURL("dubbo://" + location + "/org.apache.dubbo-go.mockService")
+		return providerUrl
+	}
+	mockRegistry.MockEvent(&registry.ServiceEvent{Action: remoting.EventTypeAdd, Service: newProvider("127.0.0.1:20001")})
+	time.Sleep(1e9)
+	mockRegistry.MockEvents([]*registry.ServiceEvent{
+		{Action: remoting.EventTypeUpdate, Service: newProvider("127.0.0.1:20002")},
+		{Action: remoting.EventTypeUpdate, Service: newProvider("127.0.0.1:20003")},
+	})
+	time.Sleep(1e9)
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, [][2][]string{
+		{{"127.0.0.1:20001"}, {}},
+		{{"127.0.0.1:20002", "127.0.0.1:20003"}, {"127.0.0.1:20001"}},
+	}, changes)
+}
+
 func Test_RefreshUrl(t *testing.T) {
 	registryDirectory, mockRegistry := normalRegistryDir()
 	providerUrl, _ := common.NewURL("dubbo://0.0.0.0:20011/org.apache.dubbo-go.mockService",
@@ -79,7 +79,8 @@ func (bcl *BaseConfigurationListener) Process(event *config_center.ConfigChangeE
 }
 
 func (bcl *BaseConfigurationListener) genConfiguratorFromRawRule(rawConfig string) error {
-	urls, err := bcl.dynamicConfiguration.Parser().ParseToUrls(rawConfig)
+	// the rules are in the dubbo group
+	urls, err := config_center.ParserOf(bcl.dynamicConfiguration, constant.DUBBO).ParseToUrls(rawConfig)
 	if err != nil {
 		return perrors.WithMessage(err, "Failed to parse raw dynamic config and it will not take effect, the raw config is: "+
 			rawConfig)
@@ -51,7 +51,7 @@ func init() {
 
 // fileServiceDiscovery is the implementation of service discovery based on file.
 type fileSystemServiceDiscovery struct {
-	dynamicConfiguration file.FileSystemDynamicConfiguration
+	dynamicConfiguration *file.FileSystemDynamicConfiguration
 	rootPath             string
 	fileMap              map[string]string
 }
@@ -76,7 +76,7 @@ func newFileSystemServiceDiscovery() (registry.ServiceDiscovery, error) {
 	}
 
 	sd := &fileSystemServiceDiscovery{
-		dynamicConfiguration: *c.(*file.FileSystemDynamicConfiguration),
+		dynamicConfiguration: c.(*file.FileSystemDynamicConfiguration),
 		rootPath:             p,
 		fileMap:              make(map[string]string),
 	}
@@ -226,6 +226,15 @@ func (nl *nacosListener) Next() (*registry.ServiceEvent, error) {
 	}
 }
 
+func (nl *nacosListener) isClosed() bool {
+	select {
+	case <-nl.done:
+		return true
+	default:
+		return false
+	}
+}
+
 // nolint
 func (nl *nacosListener) Close() {
 	_ = nl.stopListen()
@@ -21,12 +21,15 @@ import (
 	"bytes"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 import (
 	nacosClient "github.com/dubbogo/gost/database/kv/nacos"
 
+	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/vo"
 
 	perrors "github.com/pkg/errors"
@@ -48,6 +51,9 @@ const (
 	RegistryConnDelay = 3
 )
 
+// newNamingClient creates the nacos naming client, it is a variable so that tests can replace it
+var newNamingClient = clients.NewNamingClient
+
 func init() {
 	localIP = common.GetLocalIp()
 	extension.SetRegistry(constant.NACOS_KEY, newNacosRegistry)
@@ -57,6 +63,9 @@ type nacosRegistry struct {
 	*common.URL
 	namingClient *nacosClient.NacosNamingClient
 	registryUrls []*common.URL
+	// the listeners subscribing with the naming client
+	listeners []*nacosListener
+	lock      sync.Mutex
 }
 
 func getCategory(url *common.URL) string {
@@ -119,7 +128,7 @@ func createRegisterParam(url *common.URL, serviceName string, groupName string)
 // Register will register the service @url to its nacos registry center
 func (nr *nacosRegistry) Register(url *common.URL) error {
 	serviceName := getServiceName(url)
-	groupName := nr.URL.GetParam(constant.GROUP_KEY, defaultGroup)
+	groupName := nr.GetURL().GetParam(constant.GROUP_KEY, defaultGroup)
 	param := createRegisterParam(url, serviceName, groupName)
 	isRegistry, err := nr.namingClient.Client().RegisterInstance(param)
 	if err != nil {
@@ -128,7 +137,9 @@ func (nr *nacosRegistry) Register(url *common.URL) error {
 	if !isRegistry {
 		return perrors.New("registry [" + serviceName + "] to  nacos failed")
 	}
+	nr.lock.Lock()
 	nr.registryUrls = append(nr.registryUrls, url)
+	nr.lock.Unlock()
 	return nil
 }
 
@@ -151,7 +162,7 @@ func createDeregisterParam(url *common.URL, serviceName string, groupName string
 
 func (nr *nacosRegistry) DeRegister(url *common.URL) error {
 	serviceName := getServiceName(url)
-	groupName := nr.URL.GetParam(constant.GROUP_KEY, defaultGroup)
+	groupName := nr.GetURL().GetParam(constant.GROUP_KEY, defaultGroup)
 	param := createDeregisterParam(url, serviceName, groupName)
 	isDeRegistry, err := nr.namingClient.Client().DeregisterInstance(param)
 	if err != nil {
@@ -160,6 +171,15 @@ func (nr *nacosRegistry) DeRegister(url *common.URL) error {
 	if !isDeRegistry {
 		return perrors.New("DeRegistry [" + serviceName + "] to nacos failed")
 	}
+	nr.lock.Lock()
+	registryUrls := nr.registryUrls[:0]
+	for _, u := range nr.registryUrls {
+		if u.Key() != url.Key() {
+			registryUrls = append(registryUrls, u)
+		}
+	}
+	nr.registryUrls = registryUrls
+	nr.lock.Unlock()
 	return nil
 }
 
@@ -169,7 +189,14 @@ func (nr *nacosRegistry) UnRegister(conf *common.URL) error {
 }
 
 func (nr *nacosRegistry) subscribe(conf *common.URL) (registry.Listener, error) {
-	return NewNacosListener(conf, nr.namingClient)
+	listener, err := NewNacosListener(conf, nr.namingClient)
+	if err != nil {
+		return nil, err
+	}
+	nr.lock.Lock()
+	nr.listeners = append(nr.listeners, listener)
+	nr.lock.Unlock()
+	return listener, nil
 }
 
 // subscribe from registry
@@ -187,7 +214,7 @@ func (nr *nacosRegistry) Subscribe(url *common.URL, notifyListener registry.Noti
 			return perrors.New("nacosRegistry is not available.")
 		}
 
-		groupName := nr.GetParam(constant.GROUP_KEY, defaultGroup)
+		groupName := nr.GetURL().GetParam(constant.GROUP_KEY, defaultGroup)
 		url.SetParam(constant.REGISTRY_GROUP_KEY, groupName) // update to registry.group
 
 		listener, err := nr.subscribe(url)
@@ -221,6 +248,8 @@ func (nr *nacosRegistry) UnSubscribe(url *common.URL, notifyListener registry.No
 
 // GetURL gets its registration URL
 func (nr *nacosRegistry) GetURL() *common.URL {
+	nr.lock.Lock()
+	defer nr.lock.Unlock()
 	return nr.URL
 }
 
@@ -230,9 +259,75 @@ func (nr *nacosRegistry) IsAvailable() bool {
 	return true
 }
 
+// UpdateCredentials connects nacos with the new credentials, then registers the urls and resumes the subscriptions
+// on the new connection. The operations in flight still finish with the former connection, which is closed then.
+func (nr *nacosRegistry) UpdateCredentials(username, password string) error {
+	url := nr.GetURL().Clone()
+	url.Username = username
+	url.Password = password
+	url.SetParam(constant.NACOS_USERNAME, username)
+	url.SetParam(constant.NACOS_PASSWORD, password)
+	scs, cc, err := nacos.GetNacosConfig(url)
+	if err != nil {
+		return err
+	}
+	client, err := newNamingClient(vo.NacosClientParam{ClientConfig: &cc, ServerConfigs: scs})
+	if err != nil {
+		return perrors.WithMessage(err, "create nacos naming client with new credentials")
+	}
+
+	nr.lock.Lock()
+	defer nr.lock.Unlock()
+	former := nr.namingClient.Client()
+	nr.namingClient.SetClient(client)
+	nr.URL = url
+
+	groupName := url.GetParam(constant.GROUP_KEY, defaultGroup)
+	for _, u := range nr.registryUrls {
+		if _, err := client.RegisterInstance(createRegisterParam(u, getServiceName(u), groupName)); err != nil {
+			logger.Errorf("register %s with new credentials error: %v", u.Key(), err)
+		}
+	}
+	listeners := nr.listeners[:0]
+	for _, l := range nr.listeners {
+		if l.isClosed() {
+			continue
+		}
+		listeners = append(listeners, l)
+		resubscribe(former, client, l.subscribeParam)
+	}
+	nr.listeners = listeners
+	closeNamingClient(former)
+	return nil
+}
+
+func resubscribe(former, client naming_client.INamingClient, param *vo.SubscribeParam) {
+	if param == nil {
+		return
+	}
+	if former != nil {
+		if err := former.Unsubscribe(param); err != nil {
+			logger.Warnf("unsubscribe %s from former nacos client error: %v", param.ServiceName, err)
+		}
+	}
+	if err := client.Subscribe(param); err != nil {
+		logger.Errorf("subscribe %s with new credentials error: %v", param.ServiceName, err)
+	}
+}
+
+// closeNamingClient stops the heartbeats and subscriptions of the naming client if it supports being closed
+func closeNamingClient(client naming_client.INamingClient) {
+	if c, ok := client.(interface{ CloseClient() }); ok {
+		c.CloseClient()
+	}
+}
+
 // nolint
 func (nr *nacosRegistry) Destroy() {
-	for _, url := range nr.registryUrls {
+	nr.lock.Lock()
+	registryUrls := append([]*common.URL(nil), nr.registryUrls...)
+	nr.lock.Unlock()
+	for _, url := range registryUrls {
 		err := nr.DeRegister(url)
 		logger.Infof("DeRegister Nacos URL:%+v", url)
 		if err != nil {
@@ -27,6 +27,10 @@ import (
 )
 
 import (
+	nacosClient "github.com/dubbogo/gost/database/kv/nacos"
+
+	"github.com/nacos-group/nacos-sdk-go/clients"
+	"github.com/nacos-group/nacos-sdk-go/clients/naming_client"
 	"github.com/nacos-group/nacos-sdk-go/vo"
 
 	"github.com/stretchr/testify/assert"
@@ -229,3 +233,83 @@ func checkNacosServerAlive() bool {
 	}
 	return true
 }
+
+// credentialsNamingClient is a naming client recording the credentials it is created with
+type credentialsNamingClient struct {
+	naming_client.INamingClient
+	username   string
+	registered []string
+	subscribed []string
+	closed     bool
+}
+
+func (c *credentialsNamingClient) RegisterInstance(param vo.RegisterInstanceParam) (bool, error) {
+	c.registered = append(c.registered, param.ServiceName)
+	return true, nil
+}
+
+func (c *credentialsNamingClient) Subscribe(param *vo.SubscribeParam) error {
+	c.subscribed = append(c.subscribed, param.ServiceName)
+	return nil
+}
+
+func (c *credentialsNamingClient) Unsubscribe(*vo.SubscribeParam) error {
+	return nil
+}
+
+func (c *credentialsNamingClient) DeregisterInstance(vo.DeregisterInstanceParam) (bool, error) {
+	return true, nil
+}
+
+func (c *credentialsNamingClient) CloseClient() {
+	c.closed = true
+}
+
+func TestNacosRegistryUpdateCredentials(t *testing.T) {
+	var created []*credentialsNamingClient
+	newNamingClient = func(param vo.NacosClientParam) (naming_client.INamingClient, error) {
+		c := &credentialsNamingClient{username: param.ClientConfig.Username}
+		created = append(created, c)
+		return c, nil
+	}
+	defer func() {
+		newNamingClient = clients.NewNamingClient
+	}()
+
+	regurl, _ := common.NewURL("registry://127.0.0.1:8848?" + constant.NACOS_USERNAME + "=old")
+	former := &credentialsNamingClient{username: "old"}
+	namingClient := &nacosClient.NacosNamingClient{}
+	namingClient.SetClient(former)
+	reg := &nacosRegistry{URL: regurl, namingClient: namingClient}
+
+	providerUrl, _ := common.NewURL("dubbo://127.0.0.1:20000/com.ikurento.user.UserProvider?interface=com.ikurento.user.UserProvider&" +
+		constant.ROLE_KEY + "=" + strconv.Itoa(common.PROVIDER))
+	assert.NoError(t, reg.Register(providerUrl))
+	_, err := reg.subscribe(providerUrl)
+	assert.NoError(t, err)
+
+	assert.NoError(t, reg.UpdateCredentials("new", "secret"))
+	assert.Len(t, created, 1)
+	current := created[0]
+	assert.Equal(t, "new", current.username)
+	assert.Equal(t, "new", reg.GetParam(constant.NACOS_USERNAME, ""))
+	assert.Equal(t, "secret", reg.GetParam(constant.NACOS_PASSWORD, ""))
+	// the registration and subscription are kept on the new connection
+	assert.Equal(t, []string{"providers:com.ikurento.user.UserProvider::"}, current.registered)
+	assert.Equal(t, []string{getSubscribeName(providerUrl)}, current.subscribed)
+	assert.True(t, former.closed)
+	assert.False(t, current.closed)
+
+	// subsequent operations use the new credentials
+	assert.NoError(t, reg.Register(providerUrl))
+	assert.Len(t, current.registered, 2)
+	assert.Len(t, former.registered, 1)
+
+	// the deregistered urls are not registered again with the next credentials
+	assert.NoError(t, reg.DeRegister(providerUrl))
+	assert.Empty(t, reg.registryUrls)
+	assert.NoError(t, reg.UpdateCredentials("newer", "secret"))
+	assert.Len(t, created, 2)
+	assert.Empty(t, created[1].registered)
+	assert.True(t, current.closed)
+}
@@ -85,15 +85,33 @@ func newRegistryProtocol() *registryProtocol {
 	}
 }
 
+// getRegistry creates the registry of @regUrl, the optional registry failing to be created or connected is nil
 func getRegistry(regUrl *common.URL) registry.Registry {
 	reg, err := extension.GetRegistry(regUrl.Protocol, regUrl)
 	if err != nil {
+		if isOptional(regUrl) {
+			logger.Warnf("The optional registry %s is skipped, error: %v", regUrl.Location, err)
+			return nil
+		}
 		logger.Errorf("Registry can not connect success, program is going to panic.Error message is %s", err.Error())
 		panic(err.Error())
 	}
 	return reg
 }
 
+func isOptional(regUrl *common.URL) bool {
+	return regUrl.GetParamBool(constant.REGISTRY_OPTIONAL_KEY, false)
+}
+
+// dropRegistry destroys the optional registry @reg skipped, unless it was @loaded for the other services
+func (proto *registryProtocol) dropRegistry(regUrl *common.URL, reg registry.Registry, loaded bool) {
+	if loaded {
+		return
+	}
+	proto.registries.Delete(regUrl.Key())
+	reg.Destroy()
+}
+
 func getUrlToRegistry(providerUrl *common.URL, registryUrl *common.URL) *common.URL {
 	if registryUrl.GetParamBool("simplified", false) {
 		return providerUrl.CloneWithParams(reserveParams)
@@ -141,8 +159,11 @@ func (proto *registryProtocol) Refer(url *common.URL) protocol.Invoker {
 	}
 
 	var reg registry.Registry
-	if regI, loaded := proto.registries.Load(registryUrl.Key()); !loaded {
-		reg = getRegistry(registryUrl)
+	regI, loaded := proto.registries.Load(registryUrl.Key())
+	if !loaded {
+		if reg = getRegistry(registryUrl); reg == nil {
+			return nil
+		}
 		proto.registries.Store(registryUrl.Key(), reg)
 	} else {
 		reg = regI.(registry.Registry)
@@ -158,6 +179,13 @@ func (proto *registryProtocol) Refer(url *common.URL) protocol.Invoker {
 
 	err = reg.Register(serviceUrl)
 	if err != nil {
+		if isOptional(registryUrl) {
+			logger.Warnf("The optional registry %s is skipped, consumer service %v register error, error message is %s",
+				registryUrl.Location, serviceUrl.String(), err.Error())
+			directory.Destroy()
+			proto.dropRegistry(registryUrl, reg, loaded)
+			return nil
+		}
 		logger.Errorf("consumer service %v register registry %v error, error message is %s",
 			serviceUrl.String(), registryUrl.String(), err.Error())
 	}
@@ -188,19 +216,30 @@ func (proto *registryProtocol) Export(invoker protocol.Invoker) protocol.Exporte
 
 	var reg registry.Registry
 	if registryUrl.Protocol != "" {
-		if regI, loaded := proto.registries.Load(registryUrl.Key()); !loaded {
-			reg = getRegistry(registryUrl)
-			proto.registries.Store(registryUrl.Key(), reg)
-			logger.Infof("Export proto:%p registries address:%p", proto, proto.registries)
+		regI, loaded := proto.registries.Load(registryUrl.Key())
+		if !loaded {
+			if reg = getRegistry(registryUrl); reg != nil {
+				proto.registries.Store(registryUrl.Key(), reg)
+				logger.Infof("Export proto:%p registries address:%p", proto, proto.registries)
+			}
 		} else {
 			reg = regI.(registry.Registry)
 		}
-		registeredProviderUrl := getUrlToRegistry(providerUrl, registryUrl)
-		err := reg.Register(registeredProviderUrl)
-		if err != nil {
-			logger.Errorf("provider service %v register registry %v error, error message is %s",
-				providerUrl.Key(), registryUrl.Key(), err.Error())
-			return nil
+		// the service is exported without being registered to the optional registry skipped
+		if reg != nil {
+			registeredProviderUrl := getUrlToRegistry(providerUrl, registryUrl)
+			err := reg.Register(registeredProviderUrl)
+			if err != nil {
+				if !isOptional(registryUrl) {
+					logger.Errorf("provider service %v register registry %v error, error message is %s",
+						providerUrl.Key(), registryUrl.Key(), err.Error())
+					return nil
+				}
+				logger.Warnf("The optional registry %s is skipped, provider service %v register error, error message is %s",
+					registryUrl.Location, providerUrl.Key(), err.Error())
+				proto.dropRegistry(registryUrl, reg, loaded)
+				reg = nil
+			}
 		}
 	}
 
@@ -216,7 +255,7 @@ func (proto *registryProtocol) Export(invoker protocol.Invoker) protocol.Exporte
 		logger.Infof("The exporter has not been cached, and will return a new exporter!")
 	}
 
-	if registryUrl.Protocol != "" {
+	if reg != nil {
 		go func() {
 			if err := reg.Subscribe(overriderUrl, overrideSubscribeListener); err != nil {
 				logger.Warnf("reg.subscribe(overriderUrl:%v) = error:%v", overriderUrl, err)
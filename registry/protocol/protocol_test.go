@@ -18,6 +18,7 @@
 package protocol
 
 import (
+	"strconv"
 	"testing"
 	"time"
 )
@@ -40,6 +41,7 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/protocol"
 	"dubbo.apache.org/dubbo-go/v3/protocol/protocolwrapper"
 	"dubbo.apache.org/dubbo-go/v3/registry"
+	_ "dubbo.apache.org/dubbo-go/v3/registry/zookeeper"
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
 
@@ -303,3 +305,29 @@ func TestGetProviderUrlWithHideKey(t *testing.T) {
 	assert.NotContains(t, providerUrl.GetParams(), ".d")
 	assert.Contains(t, providerUrl.GetParams(), "a")
 }
+
+func TestOptionalUnreachableRegistry(t *testing.T) {
+	extension.SetProtocol(protocolwrapper.FILTER, protocolwrapper.NewMockProtocolFilter)
+	extension.SetCluster("mock", cluster.NewMockCluster)
+	newURL := func(optional bool) *common.URL {
+		url, _ := common.NewURL("zookeeper://127.0.0.1:1",
+			common.WithParamsValue(constant.REGISTRY_CONNECT_TIMEOUT_KEY, "1s"),
+			common.WithParamsValue(constant.REGISTRY_OPTIONAL_KEY, strconv.FormatBool(optional)))
+		url.SubURL, _ = common.NewURL("dubbo://127.0.0.1:20000/org.apache.dubbo-go.mockService",
+			common.WithParamsValue(constant.CLUSTER_KEY, "mock"))
+		return url
+	}
+	regProtocol := newRegistryProtocol()
+
+	// the optional registry unreachable is skipped
+	assert.Nil(t, regProtocol.Refer(newURL(true)))
+	exporter := regProtocol.Export(protocol.NewBaseInvoker(newURL(true)))
+	assert.IsType(t, &protocol.BaseExporter{}, exporter)
+	assert.Empty(t, regProtocol.GetRegistries())
+
+	// while the mandatory one is kept to reconnect
+	invoker := regProtocol.Refer(newURL(false))
+	assert.NotNil(t, invoker)
+	assert.Len(t, regProtocol.GetRegistries(), 1)
+	regProtocol.Destroy()
+}
@@ -66,6 +66,13 @@ type Registry interface {
 	UnSubscribe(*common.URL, NotifyListener) error
 }
 
+// CredentialsUpdater is implemented by the registries whose credentials can be rotated without restart
+type CredentialsUpdater interface {
+	// UpdateCredentials reconnects the registry with the new credentials,
+	// the registered urls and the subscriptions are kept on the new connection.
+	UpdateCredentials(username, password string) error
+}
+
 // nolint
 type NotifyListener interface {
 	// Notify supports notifications on the service interface and the dimension of the data type. When a list of
@@ -0,0 +1,50 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package remoting
+
+import (
+	"errors"
+)
+
+// ConnectionError means that a request failed on the connection before any byte of it was sent,
+// so it is safe to send the request again.
+type ConnectionError struct {
+	err error
+}
+
+// NewConnectionError marks @err as a ConnectionError
+func NewConnectionError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ConnectionError{err: err}
+}
+
+func (e *ConnectionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.err
+}
+
+// IsConnectionError checks whether @err or any error it wraps is a ConnectionError
+func IsConnectionError(err error) bool {
+	var connErr *ConnectionError
+	return errors.As(err, &connErr)
+}
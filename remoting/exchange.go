@@ -17,6 +17,7 @@
 package remoting
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -62,6 +63,12 @@ type Request struct {
 	Data   interface{}
 	TwoWay bool
 	Event  bool
+	// Interceptor is the name of the interceptor of the request bytes, empty if there is none
+	Interceptor string
+	// Serialization is the name of the serialization of the request body, the default one of the codec if it is empty
+	Serialization string
+	// Affinity pins the requests with the same value to the same connection of the client, any one if it is empty
+	Affinity string
 }
 
 // NewRequest aims to create Request.
@@ -127,6 +134,8 @@ type AsyncCallbackResponse struct {
 	Start     time.Time // invoke(call) start time == write start time
 	ReadStart time.Time // read start time, write duration = ReadStart - Start
 	Reply     interface{}
+	// Ctx is the context of the invocation, the callback can skip its work once it is done
+	Ctx context.Context
 }
 
 // the client sends request to server, there is one pendingResponse at client side to wait the response from server
@@ -139,6 +148,12 @@ type PendingResponse struct {
 	response  *Response
 	Reply     interface{}
 	Done      chan struct{}
+	// MaxSize is the max size in bytes of the response body, 0 means unlimited
+	MaxSize int
+	// FallbackSerializations are tried in order when the response fails to be decoded
+	FallbackSerializations []string
+	// Interceptor is the name of the interceptor of the response bytes, empty if there is none
+	Interceptor string
 }
 
 // NewPendingResponse aims to create PendingResponse.
@@ -18,6 +18,7 @@ package remoting
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -27,6 +28,7 @@ import (
 
 import (
 	"dubbo.apache.org/dubbo-go/v3/common"
+	"dubbo.apache.org/dubbo-go/v3/common/constant"
 	"dubbo.apache.org/dubbo-go/v3/common/logger"
 	"dubbo.apache.org/dubbo-go/v3/protocol"
 )
@@ -84,7 +86,7 @@ func (cl *ExchangeClient) doInit(url *common.URL) error {
 		time.Sleep(100 * time.Millisecond)
 		if cl.client.Connect(url) != nil {
 			logger.Errorf("Failed to connect server %+v " + url.Location)
-			return errors.New("Failed to connect server " + url.Location)
+			return NewConnectionError(errors.New("Failed to connect server " + url.Location))
 		}
 	}
 	// FIXME atomic operation
@@ -117,10 +119,18 @@ func (client *ExchangeClient) Request(invocation *protocol.Invocation, url *comm
 	request.Data = invocation
 	request.Event = false
 	request.TwoWay = true
+	request.Interceptor = url.GetParam(constant.BYTES_INTERCEPTOR_KEY, "")
+	request.Serialization = url.GetParam(constant.SERIALIZATION_KEY, "")
+	request.Affinity, _ = (*invocation).AttributeByKey(constant.AFFINITY_KEY, "").(string)
 
 	rsp := NewPendingResponse(request.ID)
 	rsp.response = NewResponse(request.ID, "2.0.2")
 	rsp.Reply = (*invocation).Reply()
+	rsp.MaxSize = int(url.GetParamInt(constant.RESPONSE_MAX_KEY, 0))
+	rsp.Interceptor = request.Interceptor
+	if fallback := url.GetParam(constant.SERIALIZATION_FALLBACK_KEY, ""); len(fallback) > 0 {
+		rsp.FallbackSerializations = strings.Split(fallback, ",")
+	}
 	AddPendingResponse(rsp)
 
 	err := client.client.Request(request, timeout, rsp)
@@ -147,11 +157,19 @@ func (client *ExchangeClient) AsyncRequest(invocation *protocol.Invocation, url
 	request.Data = invocation
 	request.Event = false
 	request.TwoWay = true
+	request.Interceptor = url.GetParam(constant.BYTES_INTERCEPTOR_KEY, "")
+	request.Serialization = url.GetParam(constant.SERIALIZATION_KEY, "")
+	request.Affinity, _ = (*invocation).AttributeByKey(constant.AFFINITY_KEY, "").(string)
 
 	rsp := NewPendingResponse(request.ID)
 	rsp.response = NewResponse(request.ID, "2.0.2")
 	rsp.Callback = callback
 	rsp.Reply = (*invocation).Reply()
+	rsp.MaxSize = int(url.GetParamInt(constant.RESPONSE_MAX_KEY, 0))
+	rsp.Interceptor = request.Interceptor
+	if fallback := url.GetParam(constant.SERIALIZATION_FALLBACK_KEY, ""); len(fallback) > 0 {
+		rsp.FallbackSerializations = strings.Split(fallback, ",")
+	}
 	AddPendingResponse(rsp)
 
 	err := client.client.Request(request, timeout, rsp)
@@ -172,6 +190,9 @@ func (client *ExchangeClient) Send(invocation *protocol.Invocation, url *common.
 	request.Data = invocation
 	request.Event = false
 	request.TwoWay = false
+	request.Interceptor = url.GetParam(constant.BYTES_INTERCEPTOR_KEY, "")
+	request.Serialization = url.GetParam(constant.SERIALIZATION_KEY, "")
+	request.Affinity, _ = (*invocation).AttributeByKey(constant.AFFINITY_KEY, "").(string)
 
 	rsp := NewPendingResponse(request.ID)
 	rsp.response = NewResponse(request.ID, "2.0.2")
@@ -183,6 +204,26 @@ func (client *ExchangeClient) Send(invocation *protocol.Invocation, url *common.
 	return nil
 }
 
+// heartbeat request, it carries nothing but checks the server is reachable
+func (client *ExchangeClient) Heartbeat(url *common.URL, timeout time.Duration) error {
+	if er := client.doInit(url); er != nil {
+		return er
+	}
+	request := NewRequest("2.0.2")
+	request.Event = true
+	request.TwoWay = true
+
+	rsp := NewPendingResponse(request.ID)
+	rsp.response = NewResponse(request.ID, "2.0.2")
+	AddPendingResponse(rsp)
+
+	err := client.client.Request(request, timeout, rsp)
+	if err != nil {
+		removePendingResponse(SequenceType(request.ID))
+	}
+	return err
+}
+
 // close client
 func (client *ExchangeClient) Close() {
 	client.client.Close()
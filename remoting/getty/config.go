@@ -51,6 +51,10 @@ type (
 		waitTimeout      time.Duration
 		MaxMsgLen        int    `default:"1024" yaml:"max-msg-len" json:"max-msg-len,omitempty"`
 		SessionName      string `default:"rpc" yaml:"session-name" json:"session-name,omitempty"`
+
+		// ConnectionCompression compresses the sessions of the server by "zip", which overrides CompressEncoding.
+		// It is advertised to the consumers, whose sessions are compressed as they negotiate instead.
+		ConnectionCompression string `default:"" yaml:"connection-compression" json:"connection-compression,omitempty"`
 	}
 
 	// ServerConfig holds supported types by the multiconfig package
@@ -182,9 +186,24 @@ func (c *GettySessionParam) CheckValidity() error {
 		return perrors.WithMessagef(err, "time.ParseDuration(WaitTimeout{%#v})", c.WaitTimeout)
 	}
 
+	if len(c.ConnectionCompression) > 0 && !SupportsConnectionCompression(c.ConnectionCompression) {
+		return perrors.Errorf("unsupported connection-compression{%#v}", c.ConnectionCompression)
+	}
+
 	return nil
 }
 
+// connectionCompression returns the compression of the server sessions, which is "zip" if only CompressEncoding is set
+func (c *GettySessionParam) connectionCompression() string {
+	if len(c.ConnectionCompression) > 0 {
+		return c.ConnectionCompression
+	}
+	if c.CompressEncoding {
+		return "zip"
+	}
+	return ""
+}
+
 func parseTcpTimeoutDuration(timeStr string) (time.Duration, error) {
 	result, err := time.ParseDuration(timeStr)
 	if err != nil {
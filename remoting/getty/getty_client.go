@@ -18,6 +18,8 @@
 package getty
 
 import (
+	"crypto/tls"
+	"errors"
 	"math/rand"
 	"sync"
 	"time"
@@ -120,11 +122,28 @@ type Options struct {
 	ConnectTimeout time.Duration
 	// request timeout
 	RequestTimeout time.Duration
+	// Compression compresses the whole connection, which must be supported by SupportsConnectionCompression, none if empty
+	Compression string
+	// ConnectionNum is the number of the connections to the server, the connection-number of the config if it is 0
+	ConnectionNum int
+}
+
+// connectionCompressions are the compressions of the whole connection by name, which leave snappy out
+// as getty never flushes the buffered snappy writer after writing a package
+var connectionCompressions = map[string]getty.CompressType{
+	"zip": getty.CompressZip,
+}
+
+// SupportsConnectionCompression reports whether the connection can be compressed by @name
+func SupportsConnectionCompression(name string) bool {
+	_, ok := connectionCompressions[name]
+	return ok
 }
 
 // Client : some configuration for network communication.
 type Client struct {
 	addr               string
+	url                *common.URL
 	opts               Options
 	conf               ClientConfig
 	mux                sync.RWMutex
@@ -162,10 +181,11 @@ func (c *Client) Connect(url *common.URL) error {
 	initClient(url.Protocol)
 	c.conf = *clientConf
 	c.sslEnabled = url.GetParamBool(constant.SSL_ENABLED_KEY, false)
+	c.url = url
 	// codec
 	c.codec = remoting.GetCodec(url.Protocol)
 	c.addr = url.Location
-	_, _, err := c.selectSession(c.addr)
+	_, _, err := c.selectSession(c.addr, "")
 	if err != nil {
 		logger.Errorf("try to connect server %v failed for : %v", url.Location, err)
 	}
@@ -186,22 +206,30 @@ func (c *Client) Close() {
 
 // Request send request
 func (c *Client) Request(request *remoting.Request, timeout time.Duration, response *remoting.PendingResponse) error {
-	_, session, err := c.selectSession(c.addr)
+	_, session, err := c.selectSession(c.addr, request.Affinity)
 	if err != nil {
-		return perrors.WithStack(err)
+		return remoting.NewConnectionError(perrors.WithStack(err))
 	}
 	if session == nil {
-		return errSessionNotExist
+		return remoting.NewConnectionError(errSessionNotExist)
 	}
 	var (
 		totalLen int
 		sendLen  int
 	)
 	if totalLen, sendLen, err = c.transfer(session, request, timeout); err != nil {
+		var encodeErr *encodeError
+		if errors.As(err, &encodeErr) {
+			// not sent at all, but sending it again does not help
+			return encodeErr.err
+		}
 		if sendLen != 0 && totalLen != sendLen {
 			logger.Warnf("start to close the session at request because %d of %d bytes data is sent success. err:%+v", sendLen, totalLen, err)
 			go c.Close()
 		}
+		if sendLen == 0 {
+			return remoting.NewConnectionError(perrors.WithStack(err))
+		}
 		return perrors.WithStack(err)
 	}
 
@@ -221,13 +249,14 @@ func (c *Client) Request(request *remoting.Request, timeout time.Duration, respo
 
 // IsAvailable returns true if the connection is available, or it can be re-established.
 func (c *Client) IsAvailable() bool {
-	client, _, err := c.selectSession(c.addr)
+	client, _, err := c.selectSession(c.addr, "")
 	return err == nil &&
 		// defensive check
 		client != nil
 }
 
-func (c *Client) selectSession(addr string) (*gettyRPCClient, getty.Session, error) {
+// selectSession selects the session of the connection to @addr pinned by @affinity, a random one if it is empty
+func (c *Client) selectSession(addr string, affinity string) (*gettyRPCClient, getty.Session, error) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 	if c.clientClosed {
@@ -246,18 +275,26 @@ func (c *Client) selectSession(addr string) (*gettyRPCClient, getty.Session, err
 			c.gettyClient = rpcClientConn
 		}
 		client := c.gettyClient
-		session := c.gettyClient.selectSession()
+		session := c.gettyClient.selectSession(affinity)
 		c.gettyClientMux.Unlock()
 		return client, session, nil
 	}
 	c.gettyClientMux.RLock()
 	client := c.gettyClient
-	session := c.gettyClient.selectSession()
+	session := c.gettyClient.selectSession(affinity)
 	c.gettyClientMux.RUnlock()
 	return client, session, nil
 
 }
 
+// connectionNum returns the number of the connections to the server
+func (c *Client) connectionNum() int {
+	if c.opts.ConnectionNum > 0 {
+		return c.opts.ConnectionNum
+	}
+	return c.conf.ConnectionNum
+}
+
 func (c *Client) transfer(session getty.Session, request *remoting.Request, timeout time.Duration) (int, int, error) {
 	totalLen, sendLen, err := session.WritePkg(request, timeout)
 	return totalLen, sendLen, perrors.WithStack(err)
@@ -270,3 +307,22 @@ func (c *Client) resetRpcConn() {
 	c.gettyClientMux.Unlock()
 
 }
+
+// clientTlsConfigBuilder builds the tls config of the connections to the server of url by the client tls config
+// builder, with the server name of the param tls.server.name rather than the host dialed if it is set.
+type clientTlsConfigBuilder struct {
+	url     *common.URL
+	builder getty.TlsConfigBuilder
+}
+
+// BuildTlsConfig implements getty.TlsConfigBuilder
+func (b *clientTlsConfigBuilder) BuildTlsConfig() (*tls.Config, error) {
+	var base *tls.Config
+	if b.builder != nil {
+		var err error
+		if base, err = b.builder.BuildTlsConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return common.TLSClientConfig(b.url, base), nil
+}
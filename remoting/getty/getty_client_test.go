@@ -20,6 +20,16 @@ package getty
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
 	"reflect"
 	"sync"
 	"testing"
@@ -27,6 +37,8 @@ import (
 )
 
 import (
+	"github.com/apache/dubbo-getty"
+
 	hessian "github.com/apache/dubbo-go-hessian2"
 
 	perrors "github.com/pkg/errors"
@@ -49,6 +61,8 @@ func TestRunSuite(t *testing.T) {
 	client := getClient(url)
 	assert.NotNil(t, client)
 	testRequestOneWay(t, client)
+	testRequestEncodeError(t, client)
+	testSelectSessionAffinity(t, client)
 	//testClient_Call(t, client)
 	testClient_AsyncCall(t, client)
 	svr.Stop()
@@ -67,6 +81,63 @@ func testRequestOneWay(t *testing.T, client *Client) {
 	assert.NoError(t, err)
 }
 
+func testRequestEncodeError(t *testing.T, client *Client) {
+	request := remoting.NewRequest("2.0.2")
+	invocation := createInvocation("GetUser", nil, nil, []interface{}{make(chan int)},
+		[]reflect.Value{reflect.ValueOf(make(chan int))})
+	attachment := map[string]string{INTERFACE_KEY: "com.ikurento.user.UserProvider"}
+	setAttachment(invocation, attachment)
+	request.Data = invocation
+	request.Event = false
+	request.TwoWay = false
+	// the encode error is not retried as the connection error
+	err := client.Request(request, 3*time.Second, nil)
+	assert.Error(t, err)
+	assert.False(t, remoting.IsConnectionError(err))
+	assert.Contains(t, err.Error(), "chan int")
+	// nor does it break the connection
+	testRequestOneWay(t, client)
+}
+
+func testSelectSessionAffinity(t *testing.T, client *Client) {
+	_, pinned, err := client.selectSession(client.addr, "session-1")
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, session, err := client.selectSession(client.addr, "session-1")
+		assert.NoError(t, err)
+		assert.Same(t, pinned, session)
+	}
+}
+
+// fakeSession tells the sessions apart without any connection
+type fakeSession struct {
+	getty.Session
+	id int
+}
+
+func (s *fakeSession) Stat() string {
+	return fmt.Sprintf("fake session %d", s.id)
+}
+
+func TestSelectSessionAffinity(t *testing.T) {
+	c := &gettyRPCClient{}
+	for i := 0; i < 4; i++ {
+		c.addSession(&fakeSession{id: i})
+	}
+	selected := make(map[getty.Session]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		session := c.selectSession(key)
+		assert.Same(t, session, c.selectSession(key))
+		selected[session] = true
+	}
+	// the keys are spread over the pool
+	assert.Len(t, selected, 4)
+
+	assert.Equal(t, 8, (&Client{opts: Options{ConnectionNum: 8}, conf: ClientConfig{ConnectionNum: 2}}).connectionNum())
+	assert.Equal(t, 2, (&Client{conf: ClientConfig{ConnectionNum: 2}}).connectionNum())
+}
+
 func createInvocation(methodName string, callback interface{}, reply interface{}, arguments []interface{},
 	parameterValues []reflect.Value) *invocation.RPCInvocation {
 	return invocation.NewRPCInvocationWithOptions(invocation.WithMethodName(methodName),
@@ -494,3 +565,83 @@ func TestInitClient(t *testing.T) {
 	config.SetRootConfig(*originRootConf)
 	assert.NotNil(t, srvConf)
 }
+
+// trustingTlsConfigBuilder builds the client tls config trusting the certificates of roots only
+type trustingTlsConfigBuilder struct {
+	roots *x509.CertPool
+}
+
+func (b *trustingTlsConfigBuilder) BuildTlsConfig() (*tls.Config, error) {
+	return &tls.Config{RootCAs: b.roots}, nil
+}
+
+// newTestCertificate creates a self-signed certificate of @host and the pool trusting it
+func newTestCertificate(t *testing.T, host string) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, roots
+}
+
+func TestClientTLSServerName(t *testing.T) {
+	cert, roots := newTestCertificate(t, "provider.example.com")
+	serverNames := make(chan string, 8)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	})
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				// hold the connection until the client closes it
+				_, _ = io.Copy(ioutil.Discard, conn)
+			}()
+		}
+	}()
+
+	builder := config.GetClientTlsConfigBuilder()
+	defer config.SetClientTlsConfigBuilder(builder)
+	config.SetClientTlsConfigBuilder(&trustingTlsConfigBuilder{roots: roots})
+
+	connect := func(params string) error {
+		url, err := common.NewURL("dubbo://" + listener.Addr().String() +
+			"/com.ikurento.user.UserProvider?" + SSL_ENABLED_KEY + "=true" + params)
+		assert.NoError(t, err)
+		client := NewClient(Options{ConnectTimeout: time.Second})
+		defer client.Close()
+		return client.Connect(url)
+	}
+
+	// the certificate of the host name is verified while dialing the ip
+	assert.NoError(t, connect("&"+TLS_SERVER_NAME_KEY+"=provider.example.com"))
+	assert.Equal(t, "provider.example.com", <-serverNames)
+
+	// while the ip dialed doesn't match it
+	assert.Error(t, connect(""))
+}
@@ -48,43 +48,49 @@ var (
 )
 
 func initServer(protocol string) {
-	srvConf = GetDefaultServerConfig()
+	srvConf = loadServerConfig(protocol)
+}
+
+// loadServerConfig loads the server config of @protocol from rootConfig.Protocols, the default one if it is absent
+func loadServerConfig(protocol string) *ServerConfig {
+	conf := GetDefaultServerConfig()
 	if protocol == "" {
-		return
+		return conf
 	}
 
 	// load server config from rootConfig.Protocols
 	// default use dubbo
 	if config.GetApplicationConfig() == nil {
-		return
+		return conf
 	}
 	if config.GetRootConfig().Protocols == nil {
-		return
+		return conf
 	}
 
 	protocolConf := config.GetRootConfig().Protocols[protocol]
 	if protocolConf == nil {
 		logger.Info("use default getty server config")
-		return
+		return conf
 	} else {
 		gettyServerConfig := protocolConf.Params
 		if gettyServerConfig == nil {
 			logger.Warnf("gettyServerConfig is nil")
-			return
+			return conf
 		}
 
 		gettyServerConfigBytes, err := yaml.Marshal(gettyServerConfig)
 		if err != nil {
 			panic(err)
 		}
-		err = yaml.Unmarshal(gettyServerConfigBytes, srvConf)
+		err = yaml.Unmarshal(gettyServerConfigBytes, conf)
 		if err != nil {
 			panic(err)
 		}
 	}
-	if err := srvConf.CheckValidity(); err != nil {
+	if err := conf.CheckValidity(); err != nil {
 		panic(err)
 	}
+	return conf
 }
 
 // SetServerConfig set dubbo server config.
@@ -97,6 +103,15 @@ func SetServerConfig(s ServerConfig) {
 	}
 }
 
+// ServerConnectionCompressions returns the connection compressions the server of @protocol reads,
+// which is the one its sessions are compressed with as the server can't tell the compression of a session.
+func ServerConnectionCompressions(protocol string) []string {
+	if compression := loadServerConfig(protocol).GettySessionParam.connectionCompression(); len(compression) > 0 {
+		return []string{compression}
+	}
+	return nil
+}
+
 // GetServerConfig get getty server config.
 func GetServerConfig() ServerConfig {
 	return *srvConf
@@ -139,8 +154,8 @@ func (s *Server) newSession(session getty.Session) error {
 	)
 	conf := s.conf
 
-	if conf.GettySessionParam.CompressEncoding {
-		session.SetCompressType(getty.CompressZip)
+	if compressType, ok := connectionCompressions[conf.GettySessionParam.connectionCompression()]; ok {
+		session.SetCompressType(compressType)
 	}
 	if _, ok = session.Conn().(*tls.Conn); ok {
 		session.SetName(conf.GettySessionParam.SessionName)
@@ -20,6 +20,7 @@ package getty
 import (
 	"crypto/tls"
 	"fmt"
+	"hash/crc32"
 	"math/rand"
 	"net"
 	"sync"
@@ -59,11 +60,14 @@ func newGettyRPCClientConn(rpcClient *Client, addr string) (*gettyRPCClient, err
 	sslEnabled = rpcClient.sslEnabled
 	clientOpts := []getty.ClientOption{
 		getty.WithServerAddress(addr),
-		getty.WithConnectionNumber((int)(rpcClient.conf.ConnectionNum)),
+		getty.WithConnectionNumber(rpcClient.connectionNum()),
 		getty.WithReconnectInterval(rpcClient.conf.ReconnectInterval),
 	}
 	if sslEnabled {
-		clientOpts = append(clientOpts, getty.WithClientSslEnabled(sslEnabled), getty.WithClientTlsConfigBuilder(config.GetClientTlsConfigBuilder()))
+		clientOpts = append(clientOpts, getty.WithClientSslEnabled(sslEnabled), getty.WithClientTlsConfigBuilder(&clientTlsConfigBuilder{
+			url:     rpcClient.url,
+			builder: config.GetClientTlsConfigBuilder(),
+		}))
 	}
 
 	if clientGrPool != nil {
@@ -124,6 +128,9 @@ func (c *gettyRPCClient) newSession(session getty.Session) error {
 	if conf.GettySessionParam.CompressEncoding {
 		session.SetCompressType(getty.CompressZip)
 	}
+	if compressType, ok := connectionCompressions[c.rpcClient.opts.Compression]; ok {
+		session.SetCompressType(compressType)
+	}
 	if sslEnabled {
 		if _, ok = session.Conn().(*tls.Conn); !ok {
 			panic(fmt.Sprintf("%s, session.conn{%#v} is not tls connection\n", session.Stat(), session.Conn()))
@@ -173,7 +180,9 @@ func (c *gettyRPCClient) newSession(session getty.Session) error {
 	return nil
 }
 
-func (c *gettyRPCClient) selectSession() getty.Session {
+// selectSession selects the session pinned by @affinity, which stays the same as long as the sessions do,
+// a random one if it is empty
+func (c *gettyRPCClient) selectSession(affinity string) getty.Session {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
@@ -184,6 +193,9 @@ func (c *gettyRPCClient) selectSession() getty.Session {
 	if count == 0 {
 		return nil
 	}
+	if len(affinity) > 0 {
+		return c.sessions[crc32.ChecksumIEEE([]byte(affinity))%uint32(count)].session
+	}
 	return c.sessions[rand.Int31n(int32(count))].session
 }
 
@@ -281,7 +293,7 @@ func (c *gettyRPCClient) getClientRpcSession(session getty.Session) (rpcSession,
 }
 
 func (c *gettyRPCClient) isAvailable() bool {
-	return c.selectSession() != nil
+	return c.selectSession("") != nil
 }
 
 func (c *gettyRPCClient) close() error {
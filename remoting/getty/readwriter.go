@@ -35,6 +35,20 @@ import (
 	"dubbo.apache.org/dubbo-go/v3/remoting"
 )
 
+// encodeError means the package failed to be encoded before any byte of it was written, which is no fault of
+// the connection and fails again however many times the package is sent
+type encodeError struct {
+	err error
+}
+
+func (e *encodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *encodeError) Unwrap() error {
+	return e.err
+}
+
 // RpcClientPackageHandler Read data from server and Write data to server
 type RpcClientPackageHandler struct {
 	client *Client
@@ -70,7 +84,7 @@ func (p *RpcClientPackageHandler) Write(ss getty.Session, pkg interface{}) ([]by
 		buf, err := (p.client.codec).EncodeRequest(req)
 		if err != nil {
 			logger.Warnf("binary.Write(req{%#v}) = err{%#v}", req, perrors.WithStack(err))
-			return nil, perrors.WithStack(err)
+			return nil, &encodeError{err: perrors.WithStack(err)}
 		}
 		return buf.Bytes(), nil
 	}
@@ -80,13 +94,13 @@ func (p *RpcClientPackageHandler) Write(ss getty.Session, pkg interface{}) ([]by
 		buf, err := (p.client.codec).EncodeResponse(res)
 		if err != nil {
 			logger.Warnf("binary.Write(res{%#v}) = err{%#v}", req, perrors.WithStack(err))
-			return nil, perrors.WithStack(err)
+			return nil, &encodeError{err: perrors.WithStack(err)}
 		}
 		return buf.Bytes(), nil
 	}
 
 	logger.Errorf("illegal pkg:%+v\n", pkg)
-	return nil, perrors.New("invalid rpc request")
+	return nil, &encodeError{err: perrors.New("invalid rpc request")}
 }
 
 // RpcServerPackageHandler Read data from client and Write data to client